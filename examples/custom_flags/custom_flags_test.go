@@ -18,7 +18,6 @@ package custom_flags
 
 import (
 	"context"
-	"flag"
 	"os"
 	"testing"
 
@@ -27,6 +26,7 @@ import (
 	"sigs.k8s.io/e2e-framework/pkg/env"
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/e2e-framework/pkg/features"
+	"sigs.k8s.io/e2e-framework/pkg/flags"
 )
 
 var (
@@ -35,8 +35,9 @@ var (
 )
 
 func TestMain(m *testing.M) {
-	flag.StringVar(&myCustomFlag, "my-custom-flag", "", "my custom flag for my tests")
-	// create config from flags (always in TestMain or init handler of the package before calling envconf.NewFromFlags())
+	// register the custom flags (always in TestMain or init handler of the package before calling envconf.NewFromFlags())
+	flags.UserFlags().StringVar(&myCustomFlag, "my-custom-flag", "", "my custom flag for my tests")
+	// create config from flags, the custom flags are parsed along with the framework flags
 	cfg, err := envconf.NewFromFlags()
 	if err != nil {
 		log.Fatalf("failed to build envconf from flags: %s", err)
@@ -94,3 +94,25 @@ func TestScenarioOne(t *testing.T) {
 
 	_ = testEnv.Test(t, feature)
 }
+
+func TestScenarioTwo(t *testing.T) {
+	feature := features.New("Scenario Two").
+		OnAllClusters().
+		Assess("Deployment is running successfully", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			// the feature is run once per registered cluster, config being set to the cluster of the run
+			checkDeploymentStatus(t, config.KubeconfigFile(), config.CurrentCluster())
+			return ctx
+		}).
+		Assess("Deployments can be listed on every registered cluster", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			for _, clusterName := range config.Clusters() {
+				var deployments appsv1.DeploymentList
+				if err := config.Client(clusterName).Resources("default").List(ctx, &deployments); err != nil {
+					t.Fatalf("failed to list the deployments of cluster %s: %s", clusterName, err)
+				}
+			}
+			return ctx
+		}).
+		Feature()
+
+	_ = testEnv.Test(t, feature)
+}
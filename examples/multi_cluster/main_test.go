@@ -49,6 +49,8 @@ func TestMain(m *testing.M) {
 				if err != nil {
 					return ctx, err
 				}
+				// register the cluster so that features can request its client with config.Client(cluster)
+				config.WithCluster(cluster, config.KubeconfigFile())
 			}
 			return ctx, nil
 		},
@@ -17,7 +17,11 @@ limitations under the License.
 package klient
 
 import (
+	"errors"
+	"time"
+
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	klog "k8s.io/klog/v2"
 	cr "sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,6 +39,10 @@ type Client interface {
 	// This method takes zero or at most 1 namespace (more will panic) that
 	// can be used in List operations.
 	Resources(...string) *resources.Resources
+	// Clone returns a copy of the client that keeps its rest.Config, its scheme and the wrappers set up on its
+	// Resources, such as the cache, the tracking session and the retries, and that can be used concurrently
+	// with the client.
+	Clone() Client
 }
 
 type client struct {
@@ -48,9 +56,110 @@ func NewControllerRuntimeClient(cfg *rest.Config, scheme *runtime.Scheme) (cr.Cl
 	return cr.New(cfg, cr.Options{Scheme: scheme})
 }
 
-// New returns a new Client value
-func New(cfg *rest.Config) (Client, error) {
-	res, err := resources.New(cfg)
+// Options are the configurations used to create a Client
+type Options struct {
+	// Scheme maps the Go types used with the client to their GroupVersionKinds. Defaults to the client-go scheme.
+	Scheme *runtime.Scheme
+	// QPS is the maximum number of queries per second sent to the apiserver. Zero keeps the value of the rest.Config.
+	QPS float32
+	// Burst is the maximum burst of queries sent to the apiserver. Zero keeps the value of the rest.Config.
+	Burst int
+	// Timeout is the timeout of each request sent to the apiserver. Zero keeps the value of the rest.Config.
+	Timeout time.Duration
+	// UserAgent is the user agent of the requests sent to the apiserver. Empty keeps the value of the rest.Config.
+	UserAgent string
+	// Impersonate is the identity impersonated by the client. Unset keeps the value of the rest.Config.
+	Impersonate *rest.ImpersonationConfig
+}
+
+// Option is used to alter the Options used to create a Client
+type Option func(*Options)
+
+// WithScheme sets the scheme used by the client, so that the typed objects of custom resources registered with
+// it can be used everywhere the client is, e.g. for CRUD operations, wait conditions and decoding.
+func WithScheme(s *runtime.Scheme) Option {
+	return func(o *Options) {
+		o.Scheme = s
+	}
+}
+
+// WithQPS sets the maximum number of queries per second sent to the apiserver. The client-go default of 5 queries
+// per second throttles the suites that create or watch many objects.
+func WithQPS(qps float32) Option {
+	return func(o *Options) {
+		o.QPS = qps
+	}
+}
+
+// WithBurst sets the maximum burst of queries sent to the apiserver, on top of the QPS
+func WithBurst(burst int) Option {
+	return func(o *Options) {
+		o.Burst = burst
+	}
+}
+
+// WithRequestTimeout sets the timeout of each request sent to the apiserver
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = timeout
+	}
+}
+
+// WithUserAgent sets the user agent of the requests sent to the apiserver, which can be used to identify the
+// requests of a test suite in the apiserver audit logs
+func WithUserAgent(userAgent string) Option {
+	return func(o *Options) {
+		o.UserAgent = userAgent
+	}
+}
+
+// WithImpersonation makes the client impersonate the identity `impersonate`, so that the permissions of the
+// identity can be tested, e.g. to check that it can or cannot perform operations according to its RBAC rules.
+func WithImpersonation(impersonate rest.ImpersonationConfig) Option {
+	return func(o *Options) {
+		o.Impersonate = &impersonate
+	}
+}
+
+// WithImpersonateUser makes the client impersonate the user `user`, member of the groups `groups`
+func WithImpersonateUser(user string, groups ...string) Option {
+	return WithImpersonation(rest.ImpersonationConfig{UserName: user, Groups: groups})
+}
+
+// WithImpersonateServiceAccount makes the client impersonate the ServiceAccount `name` of the namespace `namespace`
+func WithImpersonateServiceAccount(namespace, name string) Option {
+	return WithImpersonation(resources.ServiceAccountImpersonation(namespace, name))
+}
+
+// New returns a new Client value. The rest.Config is copied before the options are applied to it, so that it is
+// not modified.
+func New(cfg *rest.Config, opts ...Option) (Client, error) {
+	if cfg == nil {
+		return nil, errors.New("must provide rest.Config")
+	}
+	options := &Options{Scheme: scheme.Scheme}
+	for _, fn := range opts {
+		fn(options)
+	}
+
+	cfg = rest.CopyConfig(cfg)
+	if options.QPS != 0 {
+		cfg.QPS = options.QPS
+	}
+	if options.Burst != 0 {
+		cfg.Burst = options.Burst
+	}
+	if options.Timeout != 0 {
+		cfg.Timeout = options.Timeout
+	}
+	if options.UserAgent != "" {
+		cfg.UserAgent = options.UserAgent
+	}
+	if options.Impersonate != nil {
+		cfg.Impersonate = *options.Impersonate
+	}
+
+	res, err := resources.NewWithScheme(cfg, options.Scheme)
 	if err != nil {
 		return nil, err
 	}
@@ -58,12 +167,41 @@ func New(cfg *rest.Config) (Client, error) {
 }
 
 // NewWithKubeConfigFile creates a client using the kubeconfig filePath
-func NewWithKubeConfigFile(filePath string) (Client, error) {
+func NewWithKubeConfigFile(filePath string, opts ...Option) (Client, error) {
 	cfg, err := conf.New(filePath)
 	if err != nil {
 		return nil, err
 	}
-	return New(cfg)
+	return New(cfg, opts...)
+}
+
+// NewWithKubeConfigContext creates a client using the context kubeContext of the kubeconfig filePath instead of its
+// current context. The kubeconfig is resolved the same way as by NewWithKubeConfigFile when filePath is empty, and
+// its current context is used when kubeContext is empty.
+func NewWithKubeConfigContext(filePath, kubeContext string, opts ...Option) (Client, error) {
+	if kubeContext == "" {
+		return NewWithKubeConfigFile(filePath, opts...)
+	}
+	if filePath == "" {
+		filePath = conf.ResolveKubeConfigFile()
+	}
+	if filePath == "" {
+		return nil, errors.New("cannot use a cluster context without a valid kubeconfig file")
+	}
+	cfg, err := conf.NewWithContextName(filePath, kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg, opts...)
+}
+
+// NewInCluster creates a client using the service account of the pod it runs in
+func NewInCluster(opts ...Option) (Client, error) {
+	cfg, err := conf.NewInCluster()
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg, opts...)
 }
 
 // RESTConfig returns the *rest.Config value associated
@@ -85,6 +223,12 @@ func (c *client) Resources(namespace ...string) *resources.Resources {
 	}
 }
 
+// Clone returns a copy of the client with a copy of its Resources, so that setting the namespace of either one
+// does not affect the other.
+func (c *client) Clone() Client {
+	return &client{cfg: c.cfg, resources: c.resources.Copy()}
+}
+
 func init() {
 	log.SetLogger(klog.NewKlogr())
 }
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package klient
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	cfg := &rest.Config{Host: "https://127.0.0.1:6443", QPS: 5, Burst: 10}
+	client, err := New(cfg,
+		WithQPS(50),
+		WithBurst(100),
+		WithRequestTimeout(30*time.Second),
+		WithUserAgent("e2e-framework-test"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := client.RESTConfig()
+	if actual.QPS != 50 || actual.Burst != 100 || actual.Timeout != 30*time.Second || actual.UserAgent != "e2e-framework-test" {
+		t.Errorf("options not applied to the rest.Config: %+v", actual)
+	}
+	if cfg.QPS != 5 || cfg.Burst != 10 || cfg.UserAgent != "" {
+		t.Errorf("expected the provided rest.Config to be left untouched, got %+v", cfg)
+	}
+
+	client, err = New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := client.RESTConfig(); actual.QPS != 5 || actual.Burst != 10 {
+		t.Errorf("expected the values of the rest.Config to be kept without options, got %+v", actual)
+	}
+}
+
+func TestNewWithImpersonation(t *testing.T) {
+	cfg := &rest.Config{Host: "https://127.0.0.1:6443"}
+	client, err := New(cfg, WithImpersonateServiceAccount("default", "tester"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	impersonate := client.RESTConfig().Impersonate
+	if impersonate.UserName != "system:serviceaccount:default:tester" {
+		t.Errorf("unexpected impersonated user %q", impersonate.UserName)
+	}
+	if len(impersonate.Groups) != 3 || impersonate.Groups[1] != "system:serviceaccounts:default" {
+		t.Errorf("unexpected impersonated groups %v", impersonate.Groups)
+	}
+	if cfg.Impersonate.UserName != "" {
+		t.Error("expected the provided rest.Config to be left untouched")
+	}
+}
+
+func TestClone(t *testing.T) {
+	s := runtime.NewScheme()
+	client, err := New(&rest.Config{Host: "https://127.0.0.1:6443"}, WithScheme(s), WithQPS(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone := client.Clone()
+	if clone.RESTConfig().QPS != 50 {
+		t.Errorf("expected the clone to keep the rest.Config of the client, got %+v", clone.RESTConfig())
+	}
+	if clone.Resources().GetScheme() != s {
+		t.Error("expected the clone to keep the scheme of the client")
+	}
+	if clone.Resources("clone") == client.Resources("client") {
+		t.Error("expected the clone to have its own Resources")
+	}
+}
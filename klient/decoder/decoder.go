@@ -25,13 +25,21 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path"
+	"regexp"
 	"strings"
+	"text/template"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
 	klog "k8s.io/klog/v2"
@@ -39,6 +47,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/e2e-framework/klient/k8s"
 	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 // Options are a set of configurations used to instruct the decoding process and otherwise
@@ -46,6 +57,50 @@ import (
 type Options struct {
 	DefaultGVK  *schema.GroupVersionKind
 	MutateFuncs []MutateFunc
+	// Scheme is used to decode the objects into their typed counterparts. Defaults to the client-go scheme.
+	Scheme *runtime.Scheme
+	// Template is used to render the manifests as Go templates before decoding them, see WithTemplate.
+	Template *TemplateOptions
+	// EnvSubst is used to look up the values of the ${VAR} references substituted in the manifests before decoding
+	// them, see WithEnvSubst.
+	EnvSubst func(name string) string
+	// IncludePatterns are the globbing patterns of the files decoded by DecodeEachFS and DecodeAllFS.
+	// Defaults to YAML and JSON files.
+	IncludePatterns []string
+	// IgnorePatterns are the globbing patterns of the files and directories skipped by DecodeEachFS and DecodeAllFS.
+	IgnorePatterns []string
+	// Strict makes decoding fail on unknown or duplicate fields, see WithStrict.
+	Strict bool
+	// KindHandlers are the handlers invoked by DecodeEach for the objects of the given kinds, see WithKindHandler.
+	KindHandlers []KindHandler
+}
+
+// KindHandler is a HandlerFunc invoked for the decoded objects of a given Group, Version and Kind only.
+// An empty Version matches all the versions of the Group and Kind.
+type KindHandler struct {
+	GVK     schema.GroupVersionKind
+	Handler HandlerFunc
+}
+
+// matches checks if the handler is to be invoked for an object of the given gvk
+func (h KindHandler) matches(gvk schema.GroupVersionKind) bool {
+	return matchesGVK(h.GVK, gvk)
+}
+
+// matchesGVK checks if gvk matches the expected Group, Version and Kind, where an empty Version matches all versions
+func matchesGVK(expected, gvk schema.GroupVersionKind) bool {
+	return expected.Group == gvk.Group && expected.Kind == gvk.Kind && (expected.Version == "" || expected.Version == gvk.Version)
+}
+
+// defaultIncludePatterns are the patterns of the files decoded by DecodeEachFS when no IncludePatterns are provided
+var defaultIncludePatterns = []string{"*.yaml", "*.yml", "*.json"}
+
+// TemplateOptions are the configurations used to render the manifests as Go templates
+type TemplateOptions struct {
+	// Data is the data the templates are executed with
+	Data interface{}
+	// Funcs are the functions made available to the templates, in addition to the text/template builtins
+	Funcs template.FuncMap
 }
 
 // DecodeOption is a function that alters the configuration Options used to decode and optionally mutate objects via MutateFuncs
@@ -83,6 +138,71 @@ func DecodeEachFile(ctx context.Context, fsys fs.FS, pattern string, handlerFn H
 	return nil
 }
 
+// DecodeEachFS walks the filesystem fsys recursively, e.g. an embed.FS, and decodes every YAML or JSON file
+// found. Supports multi-document files. The files are walked in lexical order.
+//
+// The decoded files can be selected with WithIncludePatterns, and files and directories skipped with
+// WithIgnorePatterns. A pattern matches a file when it matches either its path in fsys or its base name.
+// If handlerFn returns an error, decoding is halted.
+func DecodeEachFS(ctx context.Context, fsys fs.FS, handlerFn HandlerFunc, options ...DecodeOption) error {
+	decodeOpt := &Options{}
+	for _, opt := range options {
+		opt(decodeOpt)
+	}
+	include := decodeOpt.IncludePatterns
+	if len(include) == 0 {
+		include = defaultIncludePatterns
+	}
+
+	return fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if file != "." && matchAny(decodeOpt.IgnorePatterns, file) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !matchAny(include, file) {
+			return nil
+		}
+		f, err := fsys.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := DecodeEach(ctx, f, handlerFn, options...); err != nil {
+			return fmt.Errorf("failed to decode file %q: %w", file, err)
+		}
+		return nil
+	})
+}
+
+// DecodeAllFS walks the filesystem fsys recursively and decodes every YAML or JSON file found, see DecodeEachFS.
+// Falls back to the unstructured.Unstructured type if a matching type cannot be found for the Kind.
+func DecodeAllFS(ctx context.Context, fsys fs.FS, options ...DecodeOption) ([]k8s.Object, error) {
+	objects := []k8s.Object{}
+	err := DecodeEachFS(ctx, fsys, func(ctx context.Context, obj k8s.Object) error {
+		objects = append(objects, obj)
+		return nil
+	}, options...)
+	return objects, err
+}
+
+// matchAny checks if any of the globbing patterns matches the path or the base name of the file
+func matchAny(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(file)); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // DecodeAllFiles  resolves files at the filesystem matching the pattern, decoding JSON or YAML files. Supports multi-document files.
 // Falls back to the unstructured.Unstructured type if a matching type cannot be found for the Kind.
 // Options may be provided to configure the behavior of the decoder.
@@ -109,12 +229,128 @@ func DeleteWithManifestDir(ctx context.Context, r *resources.Resources, dirPath,
 	return err
 }
 
+// ApplyDir resolves all the files in the filesystem fsys against the globbing pattern and server-side applies
+// each of the resources found, see resources.Apply. The resources are applied in an order that respects their
+// dependencies, e.g. Namespaces and ConfigMaps before the Deployments using them, see SortForApply, and the
+// CustomResourceDefinitions are waited for to be established before the custom resources are applied.
+func ApplyDir(ctx context.Context, r *resources.Resources, fsys fs.FS, pattern string, patchOptions []resources.PatchOption, options ...DecodeOption) error {
+	objects, err := DecodeAllFiles(ctx, fsys, pattern, options...)
+	if err != nil {
+		return err
+	}
+	return applyObjects(ctx, r, objects, patchOptions...)
+}
+
+// ApplyAndWait resolves all the files in the filesystem fsys against the globbing pattern, applies the resources
+// found the same way ApplyDir does and then waits for the workloads among them to be ready: the rollout of the
+// Deployments, StatefulSets and DaemonSets to complete, see conditions.RolloutComplete, and the Jobs to complete.
+// The resources are decoded with the decode options decodeOpts, and the workloads are waited for one after the
+// other, each with the wait options waitOpts.
+func ApplyAndWait(ctx context.Context, r *resources.Resources, fsys fs.FS, pattern string, decodeOpts []DecodeOption, waitOpts ...wait.Option) error {
+	objects, err := DecodeAllFiles(ctx, fsys, pattern, decodeOpts...)
+	if err != nil {
+		return err
+	}
+	if err := applyObjects(ctx, r, objects); err != nil {
+		return err
+	}
+	waitOpts = append([]wait.Option{wait.WithContext(ctx)}, waitOpts...)
+	cond := conditions.New(r)
+	for _, obj := range objects {
+		var condition apimachinerywait.ConditionWithContextFunc
+		switch obj.(type) {
+		case *appsv1.Deployment, *appsv1.StatefulSet, *appsv1.DaemonSet:
+			condition = cond.RolloutComplete(obj)
+		case *batchv1.Job:
+			condition = cond.JobCompleted(obj)
+		default:
+			continue
+		}
+		if err := wait.For(condition, waitOpts...); err != nil {
+			return fmt.Errorf("failed to wait for %s %s/%s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// crdEstablishedTimeout is how long applyObjects waits for the CustomResourceDefinitions to be established
+const crdEstablishedTimeout = time.Minute
+
+// applyObjects applies the objects in the order given by SortForApply. The CustomResourceDefinitions applied are
+// waited for to be established before applying the objects that follow them, such as the custom resources.
+func applyObjects(ctx context.Context, r *resources.Resources, objects []k8s.Object, patchOptions ...resources.PatchOption) error {
+	SortForApply(objects)
+	handler := ApplyHandler(r, patchOptions...)
+	var crds []string
+	for _, obj := range objects {
+		if len(crds) > 0 && !isCustomResourceDefinition(obj) {
+			if err := waitForCRDs(ctx, r, crds); err != nil {
+				return err
+			}
+			crds = nil
+		}
+		if err := handler(ctx, obj); err != nil {
+			return fmt.Errorf("failed to apply %s %s/%s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), err)
+		}
+		if isCustomResourceDefinition(obj) {
+			crds = append(crds, obj.GetName())
+		}
+	}
+	return waitForCRDs(ctx, r, crds)
+}
+
+// waitForCRDs waits for the CustomResourceDefinitions to be established
+func waitForCRDs(ctx context.Context, r *resources.Resources, names []string) error {
+	cond := conditions.New(r)
+	for _, name := range names {
+		err := wait.For(cond.CRDEstablished(name), wait.WithContext(ctx), wait.WithImmediate(), wait.WithInterval(time.Second), wait.WithTimeout(crdEstablishedTimeout))
+		if err != nil {
+			return fmt.Errorf("failed to wait for CustomResourceDefinition %s to be established: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// DeleteDir does the reverse of ApplyDir. This will resolve all files in the filesystem fsys against the pattern
+// and then delete the resources found, in the reverse order of ApplyDir. Resources that are already gone are ignored.
+func DeleteDir(ctx context.Context, r *resources.Resources, fsys fs.FS, pattern string, deleteOptions []resources.DeleteOption, options ...DecodeOption) error {
+	objects, err := DecodeAllFiles(ctx, fsys, pattern, options...)
+	if err != nil {
+		return err
+	}
+	SortForDelete(objects)
+	handler := DeleteIgnoreNotFound(r, deleteOptions...)
+	for _, obj := range objects {
+		if err := handler(ctx, obj); err != nil {
+			return fmt.Errorf("failed to delete %s %s/%s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
 // DecodeEach a stream of documents of any Kind using either the innate typing of the scheme.
 // Falls back to the unstructured.Unstructured type if a matching type cannot be found for the Kind.
 //
-// If handlerFn returns an error, decoding is halted.
+// The objects of the kinds registered with WithKindHandler are passed to the matching handlers, in the order they
+// were registered, instead of handlerFn. handlerFn may be nil if it is not needed for the other objects.
+//
+// If a handler returns an error, decoding is halted.
 // Options may be provided to configure the behavior of the decoder.
 func DecodeEach(ctx context.Context, manifest io.Reader, handlerFn HandlerFunc, options ...DecodeOption) error {
+	decodeOpt := &Options{}
+	for _, opt := range options {
+		opt(decodeOpt)
+	}
+	manifest, err := render(manifest, decodeOpt)
+	if err != nil {
+		return err
+	}
+	// the stream is rendered as a whole, so that templates can span several documents
+	options = append(append([]DecodeOption{}, options...), func(do *Options) {
+		do.Template = nil
+		do.EnvSubst = nil
+	})
+
 	decoder := yaml.NewYAMLReader(bufio.NewReader(manifest))
 	for {
 		b, err := decoder.Read()
@@ -133,13 +369,32 @@ func DecodeEach(ctx context.Context, manifest io.Reader, handlerFn HandlerFunc,
 			}
 			return err
 		}
-		if err := handlerFn(ctx, obj); err != nil {
+		if err := decodeOpt.handle(ctx, obj, handlerFn); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// handle invokes the kind handlers matching the object, or handlerFn if there are none
+func (o *Options) handle(ctx context.Context, obj k8s.Object, handlerFn HandlerFunc) error {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	handled := false
+	for _, h := range o.KindHandlers {
+		if !h.matches(gvk) {
+			continue
+		}
+		handled = true
+		if err := h.Handler(ctx, obj); err != nil {
+			return err
+		}
+	}
+	if handled || handlerFn == nil {
+		return nil
+	}
+	return handlerFn(ctx, obj)
+}
+
 // DecodeAll is a stream of  documents of any Kind using either the innate typing of the scheme.
 // Falls back to the unstructured.Unstructured type if a matching type cannot be found for the Kind.
 // Options may be provided to configure the behavior of the decoder.
@@ -161,7 +416,19 @@ func DecodeAny(manifest io.Reader, options ...DecodeOption) (k8s.Object, error)
 		opt(decodeOpt)
 	}
 
-	k8sDecoder := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer().Decode
+	s := decodeOpt.Scheme
+	if s == nil {
+		s = scheme.Scheme
+	}
+	var codecOpts []serializer.CodecFactoryOptionsMutator
+	if decodeOpt.Strict {
+		codecOpts = append(codecOpts, serializer.EnableStrict)
+	}
+	k8sDecoder := serializer.NewCodecFactory(s, codecOpts...).UniversalDeserializer().Decode
+	manifest, err := render(manifest, decodeOpt)
+	if err != nil {
+		return nil, err
+	}
 	b, err := io.ReadAll(manifest)
 	if err != nil {
 		return nil, err
@@ -195,7 +462,19 @@ func Decode(manifest io.Reader, obj k8s.Object, options ...DecodeOption) error {
 	for _, opt := range options {
 		opt(decodeOpt)
 	}
-	if err := yaml.NewYAMLOrJSONDecoder(manifest, 1024).Decode(obj); err != nil {
+	manifest, err := render(manifest, decodeOpt)
+	if err != nil {
+		return err
+	}
+	if decodeOpt.Strict {
+		b, err := yaml.NewYAMLReader(bufio.NewReader(manifest)).Read()
+		if err != nil {
+			return err
+		}
+		if err := sigsyaml.UnmarshalStrict(b, obj); err != nil {
+			return fmt.Errorf("strict decoding error: %w", err)
+		}
+	} else if err := yaml.NewYAMLOrJSONDecoder(manifest, 1024).Decode(obj); err != nil {
 		return err
 	}
 	for _, patch := range decodeOpt.MutateFuncs {
@@ -231,6 +510,155 @@ func DefaultGVK(defaults *schema.GroupVersionKind) DecodeOption {
 	}
 }
 
+// WithTemplate renders the manifests as Go templates, executed with `data` and the functions `funcs`, before
+// decoding them. This can be used to parameterize manifests with e.g. image tags, namespaces or replica counts:
+//
+//	decoder.DecodeEachFile(ctx, fsys, "*.yaml", handler, decoder.WithTemplate(struct{ Image string }{"nginx:1.27"}, nil))
+//
+// Helpers such as the sprig functions can be provided with `funcs`. Missing keys of maps fail the rendering.
+func WithTemplate(data interface{}, funcs template.FuncMap) DecodeOption {
+	return func(do *Options) {
+		do.Template = &TemplateOptions{Data: data, Funcs: funcs}
+	}
+}
+
+// WithEnvSubst substitutes the ${VAR} references in the manifests with the values of the environment variables
+// before decoding them, the same way the envsubst command does. References to variables that are not set are
+// substituted with an empty string. Other forms of references, such as $VAR, are left untouched so that e.g.
+// shell scripts embedded in the manifests are not altered.
+func WithEnvSubst() DecodeOption {
+	return func(do *Options) {
+		do.EnvSubst = os.Getenv
+	}
+}
+
+// WithEnvSubstVars substitutes the ${VAR} references in the manifests with the values of vars before decoding
+// them, see WithEnvSubst.
+func WithEnvSubstVars(vars map[string]string) DecodeOption {
+	return func(do *Options) {
+		do.EnvSubst = func(name string) string {
+			return vars[name]
+		}
+	}
+}
+
+// envSubstReference matches the ${VAR} references substituted by WithEnvSubst
+var envSubstReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// render renders the manifest as configured by the options. The variables are substituted before the templates
+// are rendered.
+func render(manifest io.Reader, options *Options) (io.Reader, error) {
+	if options.Template == nil && options.EnvSubst == nil {
+		return manifest, nil
+	}
+	b, err := io.ReadAll(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if options.EnvSubst != nil {
+		b = envSubstReference.ReplaceAllFunc(b, func(ref []byte) []byte {
+			return []byte(options.EnvSubst(string(ref[2 : len(ref)-1])))
+		})
+	}
+	if options.Template == nil {
+		return bytes.NewReader(b), nil
+	}
+	tmpl, err := template.New("manifest").Funcs(options.Template.Funcs).Option("missingkey=error").Parse(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest template: %w", err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, options.Template.Data); err != nil {
+		return nil, fmt.Errorf("failed to render manifest template: %w", err)
+	}
+	return &rendered, nil
+}
+
+// WithIncludePatterns sets the globbing patterns of the files decoded by DecodeEachFS and DecodeAllFS, e.g.
+// "*.yaml" or "crds/*.yaml"
+func WithIncludePatterns(patterns ...string) DecodeOption {
+	return func(do *Options) {
+		do.IncludePatterns = append(do.IncludePatterns, patterns...)
+	}
+}
+
+// WithIgnorePatterns sets the globbing patterns of the files and directories skipped by DecodeEachFS and
+// DecodeAllFS, e.g. "kustomization.yaml" or "overlays"
+func WithIgnorePatterns(patterns ...string) DecodeOption {
+	return func(do *Options) {
+		do.IgnorePatterns = append(do.IgnorePatterns, patterns...)
+	}
+}
+
+// WithScheme sets the scheme used to decode the objects, so that the types registered with it, such as the
+// types of custom resources, are decoded into their typed counterparts instead of unstructured.Unstructured.
+func WithScheme(s *runtime.Scheme) DecodeOption {
+	return func(do *Options) {
+		do.Scheme = s
+	}
+}
+
+// WithStrict makes decoding fail when a manifest sets fields that are unknown to the type it is decoded into, e.g.
+// a misspelled field, or sets the same field twice. Such fields are otherwise dropped silently. Objects of the kinds
+// that are not registered with the scheme are decoded into unstructured.Unstructured and cannot be checked.
+func WithStrict() DecodeOption {
+	return func(do *Options) {
+		do.Strict = true
+	}
+}
+
+// WithKindHandler registers a handler invoked by DecodeEach and the functions built on it, such as DecodeEachFile,
+// for the objects of the given kind instead of the handler they are called with. This can be used to process a
+// stream of mixed manifests selectively, e.g. to wait for the CustomResourceDefinitions to be established:
+//
+//	decoder.DecodeEachFile(ctx, fsys, "*.yaml", decoder.CreateHandler(r),
+//		decoder.WithKindHandler(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"), createAndWait))
+//
+// Leave the Version of gvk empty to match all the versions of the kind.
+func WithKindHandler(gvk schema.GroupVersionKind, handler HandlerFunc) DecodeOption {
+	return func(do *Options) {
+		do.KindHandlers = append(do.KindHandlers, KindHandler{GVK: gvk, Handler: handler})
+	}
+}
+
+// WithValidation validates each decoded object against the schema of its type served by the cluster of r, the
+// same way `kubectl apply --validate=strict` does, so that malformed manifests fail at decode time with a clear
+// error rather than mid-test. The object is validated with a server-side apply dry run with strict field
+// validation, which also rejects the unknown fields of unstructured objects, and is left unchanged. The unknown
+// fields of typed objects are dropped when they are decoded, use WithStrict to reject them too.
+//
+// The objects that cannot be validated yet are skipped: those missing their namespace, or whose kind is not
+// installed, e.g. the custom resources decoded along with their CustomResourceDefinition. Each dry run is bounded
+// by validationTimeout, as the decoding does not carry a context.
+func WithValidation(r *resources.Resources) DecodeOption {
+	return MutateOption(func(obj k8s.Object) error {
+		dryRun, ok := obj.DeepCopyObject().(k8s.Object)
+		if !ok {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), validationTimeout)
+		defer cancel()
+		err := r.Apply(ctx, dryRun, resources.WithPatchDryRun(), resources.WithPatchStrictValidation(), resources.WithForceOwnership())
+		if err == nil || meta.IsNoMatchError(err) || isNamespaceNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("invalid %s %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+	})
+}
+
+// validationTimeout is how long WithValidation waits for the dry run validating an object
+const validationTimeout = 30 * time.Second
+
+// isNamespaceNotFound checks if the error is due to the namespace of an object not existing
+func isNamespaceNotFound(err error) bool {
+	var status apierrors.APIStatus
+	if !apierrors.IsNotFound(err) || !errors.As(err, &status) {
+		return false
+	}
+	details := status.Status().Details
+	return details != nil && details.Kind == "namespaces"
+}
+
 // MutateOption can be used to add a custom MutateFunc to the DecodeOption
 // used to configure the decoding of objects
 func MutateOption(m MutateFunc) DecodeOption {
@@ -291,6 +719,13 @@ func CreateHandler(r *resources.Resources, opts ...resources.CreateOption) Handl
 	}
 }
 
+// ApplyHandler returns a HandlerFunc that will server-side apply objects
+func ApplyHandler(r *resources.Resources, opts ...resources.PatchOption) HandlerFunc {
+	return func(ctx context.Context, obj k8s.Object) error {
+		return r.Apply(ctx, obj, opts...)
+	}
+}
+
 // ReadHandler returns a HandlerFunc that will use the provided object's Kind / Namespace / Name to retrieve
 // the current state of the object using the provided Resource client.
 // This helper makes it easy to use a stale reference to an object to retrieve its current version.
@@ -298,9 +733,9 @@ func ReadHandler(r *resources.Resources, handler HandlerFunc) HandlerFunc {
 	return func(ctx context.Context, obj k8s.Object) error {
 		name := obj.GetName()
 		namespace := obj.GetNamespace()
-		// use scheme.Scheme to generate a new, empty object to use as a base for decoding into
+		// use the scheme of the client to generate a new, empty object to use as a base for decoding into
 		gvk := obj.GetObjectKind().GroupVersionKind()
-		o, err := scheme.Scheme.New(gvk)
+		o, err := r.GetScheme().New(gvk)
 		if err != nil {
 			return fmt.Errorf("resources: GroupVersionKind not found in scheme: %s", gvk.String())
 		}
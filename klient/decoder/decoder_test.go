@@ -18,18 +18,28 @@ package decoder_test
 
 import (
 	"context"
+	"embed"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"text/template"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/e2e-framework/klient/decoder"
 	"sigs.k8s.io/e2e-framework/klient/k8s"
 	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
 )
 
 const (
@@ -373,3 +383,543 @@ func TestHandlerFuncs(t *testing.T) {
 		}
 	})
 }
+
+func TestApplyDir(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+	testdata := os.DirFS(filepath.Join("testdata", "apply-dir"))
+
+	// the namespace sorts after the configmap by file name, and must be applied first
+	for i := 0; i < 2; i++ {
+		if err := decoder.ApplyDir(context.TODO(), res, testdata, "*", nil); err != nil {
+			t.Fatalf("apply %d failed: %v", i, err)
+		}
+	}
+	var cm v1.ConfigMap
+	if err := res.Get(context.TODO(), "apply-dir-config", "apply-dir-test", &cm); err != nil {
+		t.Fatalf("expected applied configmap to exist: %v", err)
+	}
+
+	if err := decoder.DeleteDir(context.TODO(), res, testdata, "*", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := res.Get(context.TODO(), "apply-dir-config", "apply-dir-test", &cm); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected configmap to be deleted, got: %v", err)
+	}
+	if err := decoder.DeleteDir(context.TODO(), res, testdata, "*", nil); err != nil {
+		t.Fatalf("DeleteDir should not return an error if objects are not found. Error: %s", err)
+	}
+}
+
+func TestApplyDirWithCRD(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+	testdata := os.DirFS(filepath.Join("testdata", "apply-crd"))
+
+	// the custom resource sorts before its definition by file name, and must wait for it to be established
+	if err := decoder.ApplyDir(context.TODO(), res, testdata, "*", nil); err != nil {
+		t.Fatal(err)
+	}
+	crontab := &unstructured.Unstructured{}
+	crontab.SetGroupVersionKind(schema.GroupVersionKind{Group: "apply.example.com", Version: "v1", Kind: "CronTab"})
+	if err := res.Get(context.TODO(), "apply-crd-crontab", "apply-crd-test", crontab); err != nil {
+		t.Fatalf("expected the custom resource to be applied: %v", err)
+	}
+
+	if err := decoder.DeleteDir(context.TODO(), res, testdata, "*", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyAndWait(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+	testdata := os.DirFS(filepath.Join("testdata", "apply-and-wait"))
+
+	if err := decoder.ApplyAndWait(context.TODO(), res, testdata, "*", []decoder.DecodeOption{decoder.MutateLabels(map[string]string{"applied-by": "apply-and-wait"})}, wait.WithTimeout(5*time.Minute), wait.WithInterval(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var deployment appsv1.Deployment
+	if err := res.Get(context.TODO(), "apply-and-wait", "apply-and-wait-test", &deployment); err != nil {
+		t.Fatal(err)
+	}
+	if deployment.Status.AvailableReplicas != 1 {
+		t.Errorf("expected the deployment to be available once applied, got %d available replicas", deployment.Status.AvailableReplicas)
+	}
+	if deployment.Labels["applied-by"] != "apply-and-wait" {
+		t.Errorf("expected the decode options to be applied to the deployment, got labels %v", deployment.Labels)
+	}
+
+	if err := decoder.DeleteDir(context.TODO(), res, testdata, "*", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSortForApply(t *testing.T) {
+	objects := []k8s.Object{
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Custom"}},
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment"}},
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}},
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "admissionregistration.k8s.io/v1", "kind": "ValidatingWebhookConfiguration"}},
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRole"}},
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition"}},
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "Namespace"}},
+	}
+	kinds := func() []string {
+		var kinds []string
+		for _, obj := range objects {
+			kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+		return kinds
+	}
+
+	decoder.SortForApply(objects)
+	if got, expected := fmt.Sprint(kinds()), "[Namespace CustomResourceDefinition ClusterRole ConfigMap Deployment Custom ValidatingWebhookConfiguration]"; got != expected {
+		t.Errorf("expected apply order %s, got %s", expected, got)
+	}
+	decoder.SortForDelete(objects)
+	if got, expected := fmt.Sprint(kinds()), "[ValidatingWebhookConfiguration Custom Deployment ConfigMap ClusterRole CustomResourceDefinition Namespace]"; got != expected {
+		t.Errorf("expected delete order %s, got %s", expected, got)
+	}
+}
+
+func TestDecodeWithScheme(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := v1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := decoder.DecodeAny(strings.NewReader(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"typed"}}`), decoder.WithScheme(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := obj.(*v1.ConfigMap); !ok {
+		t.Errorf("expected type registered with the scheme to be decoded as *v1.ConfigMap, got %T", obj)
+	}
+
+	obj, err = decoder.DecodeAny(strings.NewReader(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"unstructured"}}`), decoder.WithScheme(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := obj.(*unstructured.Unstructured); !ok {
+		t.Errorf("expected type not registered with the scheme to be decoded as *unstructured.Unstructured, got %T", obj)
+	}
+}
+
+func TestDecodeWithTemplate(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace | upper }}
+data:
+  replicas: "{{ .Replicas }}"
+{{- range $i := .Extra }}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ $.Name }}-{{ $i }}
+{{- end }}
+`
+	data := map[string]interface{}{"Name": "templated", "Namespace": "default", "Replicas": 3, "Extra": []int{1, 2}}
+	funcs := template.FuncMap{"upper": strings.ToUpper}
+
+	objects, err := decoder.DecodeAll(context.TODO(), strings.NewReader(manifest), decoder.WithTemplate(data, funcs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("expected 3 objects, got %d", len(objects))
+	}
+	cm, ok := objects[0].(*v1.ConfigMap)
+	if !ok {
+		t.Fatalf("expected *v1.ConfigMap, got %T", objects[0])
+	}
+	if cm.Name != "templated" || cm.Namespace != "DEFAULT" || cm.Data["replicas"] != "3" {
+		t.Errorf("unexpected rendered configmap %s/%s %v", cm.Namespace, cm.Name, cm.Data)
+	}
+	if objects[2].GetName() != "templated-2" {
+		t.Errorf("unexpected rendered name %q", objects[2].GetName())
+	}
+
+	var single v1.ConfigMap
+	if err := decoder.DecodeString("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Name }}\n", &single, decoder.WithTemplate(data, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if single.Name != "templated" {
+		t.Errorf("unexpected rendered name %q", single.Name)
+	}
+
+	if _, err := decoder.DecodeAll(context.TODO(), strings.NewReader("name: {{ .Missing }}"), decoder.WithTemplate(data, nil)); err == nil {
+		t.Error("expected rendering with a missing key to fail")
+	}
+}
+
+//go:embed testdata/examples testdata/apply-dir
+var embeddedTestdata embed.FS
+
+func TestDecodeAllFS(t *testing.T) {
+	testdata, err := fs.Sub(embeddedTestdata, "testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := decoder.DecodeAllFS(context.TODO(), testdata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 6; len(objects) != expected {
+		t.Errorf("expected %d objects decoded from the tree, got %d", expected, len(objects))
+	}
+
+	objects, err = decoder.DecodeAllFS(context.TODO(), testdata, decoder.WithIgnorePatterns("apply-dir"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 4; len(objects) != expected {
+		t.Errorf("expected %d objects decoded outside of the ignored directory, got %d", expected, len(objects))
+	}
+
+	objects, err = decoder.DecodeAllFS(context.TODO(), testdata, decoder.WithIncludePatterns("example-sa-*"), decoder.WithIgnorePatterns("*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 2; len(objects) != expected {
+		t.Errorf("expected %d objects decoded from the included files, got %d", expected, len(objects))
+	}
+	for _, obj := range objects {
+		if _, ok := obj.(*v1.ServiceAccount); !ok {
+			t.Errorf("expected *v1.ServiceAccount, got %T", obj)
+		}
+	}
+}
+
+func TestDecodeKustomize(t *testing.T) {
+	objects, err := decoder.DecodeKustomize(context.TODO(), os.DirFS("testdata/kustomize"), "overlay", decoder.MutateLabels(map[string]string{"test": testLabel}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("expected 1 object rendered by the kustomization, got %d", len(objects))
+	}
+	cm, ok := objects[0].(*v1.ConfigMap)
+	if !ok {
+		t.Fatalf("expected *v1.ConfigMap, got %T", objects[0])
+	}
+	if cm.Name != "overlay-kustomized" {
+		t.Errorf("expected the name prefix of the overlay to be applied, got %q", cm.Name)
+	}
+	if cm.Labels["overlay"] != "true" || cm.Labels["test"] != testLabel {
+		t.Errorf("expected the labels of the overlay and of the mutate func, got %v", cm.Labels)
+	}
+	if cm.Data["foo.cfg"] != "foo=bar\n" {
+		t.Errorf("unexpected data %v", cm.Data)
+	}
+}
+
+func TestDecodeWithStrict(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: strict
+  namespace: default
+datas:
+  foo: bar
+`
+	if _, err := decoder.DecodeAny(strings.NewReader(manifest)); err != nil {
+		t.Fatalf("expected the unknown field to be ignored, got %v", err)
+	}
+	if _, err := decoder.DecodeAny(strings.NewReader(manifest), decoder.WithStrict()); err == nil || !runtime.IsStrictDecodingError(err) {
+		t.Errorf("expected a strict decoding error for the unknown field, got %v", err)
+	}
+	if err := decoder.DecodeString(manifest, &v1.ConfigMap{}, decoder.WithStrict()); err == nil {
+		t.Error("expected decoding into a ConfigMap to fail on the unknown field")
+	}
+	if err := decoder.DecodeString(strings.Replace(manifest, "datas", "data", 1), &v1.ConfigMap{}, decoder.WithStrict()); err != nil {
+		t.Errorf("expected a valid manifest to be decoded, got %v", err)
+	}
+}
+
+func TestDecodeWithKindHandlers(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: handled
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: handled
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: handled
+`
+	var configMaps, serviceAccounts, others int
+	err := decoder.DecodeEach(context.TODO(), strings.NewReader(manifest), func(ctx context.Context, obj k8s.Object) error {
+		others++
+		return nil
+	},
+		decoder.WithKindHandler(v1.SchemeGroupVersion.WithKind("ConfigMap"), func(ctx context.Context, obj k8s.Object) error {
+			if _, ok := obj.(*v1.ConfigMap); !ok {
+				t.Errorf("expected *v1.ConfigMap, got %T", obj)
+			}
+			configMaps++
+			return nil
+		}),
+		decoder.WithKindHandler(schema.GroupVersionKind{Kind: "ServiceAccount"}, func(ctx context.Context, obj k8s.Object) error {
+			serviceAccounts++
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configMaps != 1 || serviceAccounts != 1 || others != 1 {
+		t.Errorf("expected each object to be handled once, got %d configmaps, %d serviceaccounts and %d others", configMaps, serviceAccounts, others)
+	}
+
+	err = decoder.DecodeEach(context.TODO(), strings.NewReader(manifest), nil,
+		decoder.WithKindHandler(v1.SchemeGroupVersion.WithKind("Secret"), func(ctx context.Context, obj k8s.Object) error {
+			return fmt.Errorf("secret not allowed")
+		}),
+	)
+	if err == nil {
+		t.Error("expected the error of the kind handler to halt decoding")
+	}
+}
+
+func TestMutatePatches(t *testing.T) {
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  labels:
+    app: nginx
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+      - name: sidecar
+        image: busybox
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: other
+spec:
+  replicas: 1
+`
+	deploymentGVK := appsv1.SchemeGroupVersion.WithKind("Deployment")
+	objects, err := decoder.DecodeAll(context.TODO(), strings.NewReader(manifest),
+		decoder.MutateJSONPatch(decoder.PatchTarget{GVK: &deploymentGVK, LabelSelector: "app=nginx"}, []byte(`
+- op: replace
+  path: /spec/replicas
+  value: 3
+`)),
+		decoder.MutateStrategicMergePatch(decoder.PatchTarget{Name: "nginx"}, []byte(`
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        env:
+        - name: FOO
+          value: bar
+`)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objects))
+	}
+	nginx, ok := objects[0].(*appsv1.Deployment)
+	if !ok {
+		t.Fatalf("expected *appsv1.Deployment, got %T", objects[0])
+	}
+	if *nginx.Spec.Replicas != 3 {
+		t.Errorf("expected the JSON patch to set 3 replicas, got %d", *nginx.Spec.Replicas)
+	}
+	containers := nginx.Spec.Template.Spec.Containers
+	if len(containers) != 2 || containers[0].Image != "nginx" || len(containers[0].Env) != 1 || containers[0].Env[0].Value != "bar" {
+		t.Errorf("expected the strategic merge patch to merge the env of the nginx container, got %+v", containers)
+	}
+	if other := objects[1].(*appsv1.Deployment); *other.Spec.Replicas != 1 {
+		t.Errorf("expected the object not selected to be left unchanged, got %d replicas", *other.Spec.Replicas)
+	}
+
+	_, err = decoder.DecodeAll(context.TODO(), strings.NewReader(manifest),
+		decoder.MutateJSONPatch(decoder.PatchTarget{Name: "other"}, []byte(`[{"op": "remove", "path": "/spec/missing"}]`)))
+	if err == nil {
+		t.Error("expected an invalid patch to fail decoding")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	now := metav1.Now()
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "encoded",
+			Namespace:         "default",
+			UID:               "0f0b9a40-5c1c-4d4e-9a3c-8f2a7f3b6e1d",
+			ResourceVersion:   "42",
+			CreationTimestamp: now,
+			Labels:            map[string]string{resources.TrackingLabel: "abc"},
+			ManagedFields:     []metav1.ManagedFieldsEntry{{Manager: "e2e-framework"}},
+		},
+		Data: map[string]string{"foo": "bar"},
+	}
+	expected := `apiVersion: v1
+data:
+  foo: bar
+kind: ConfigMap
+metadata:
+  name: encoded
+  namespace: default
+`
+	b, err := decoder.Encode(cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != expected {
+		t.Errorf("unexpected encoding, got:\n%s\nexpected:\n%s", b, expected)
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "encoded", Namespace: "default"},
+		Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			StartTime:  &now,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue, LastTransitionTime: now}},
+		},
+	}
+	b, err = decoder.EncodeAll([]k8s.Object{pod, cm}, decoder.WithIgnoredField("spec"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, err := decoder.DecodeAll(context.TODO(), strings.NewReader(string(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 encoded documents, got %d", len(objects))
+	}
+	decoded := objects[0].(*v1.Pod)
+	if decoded.Status.StartTime != nil || !decoded.Status.Conditions[0].LastTransitionTime.IsZero() || decoded.Status.Phase != v1.PodRunning {
+		t.Errorf("expected only the timestamps of the status to be stripped, got %+v", decoded.Status)
+	}
+
+	b, err = decoder.Encode(pod, decoder.WithoutStatus(), decoder.WithIgnoredField("spec"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "status") || strings.Contains(string(b), "spec") {
+		t.Errorf("expected the status and spec to be stripped, got:\n%s", b)
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("Pod")
+	u.SetName("encoded")
+	u.SetUID("0f0b9a40-5c1c-4d4e-9a3c-8f2a7f3b6e1d")
+	u.SetResourceVersion("42")
+	u.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "e2e-framework"}})
+	if err := unstructured.SetNestedField(u.Object, now.UTC().Format(time.RFC3339), "status", "startTime"); err != nil {
+		t.Fatal(err)
+	}
+	original := u.DeepCopy()
+	if _, err := decoder.Encode(u); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(u.Object, original.Object) {
+		t.Errorf("expected the unstructured object to be left unchanged, got %v", u.Object)
+	}
+}
+
+func TestDecodeWithEnvSubst(t *testing.T) {
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ${NAME}
+spec:
+  replicas: ${REPLICAS}
+  template:
+    spec:
+      containers:
+      - name: app
+        image: "nginx:${UNSET_TAG}"
+        command: ["sh", "-c", "echo $HOME"]
+`
+	objects, err := decoder.DecodeAll(context.TODO(), strings.NewReader(manifest), decoder.WithEnvSubstVars(map[string]string{"NAME": "substituted", "REPLICAS": "3"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployment := objects[0].(*appsv1.Deployment)
+	if deployment.Name != "substituted" || *deployment.Spec.Replicas != 3 {
+		t.Errorf("expected the variables to be substituted, got name %q and %d replicas", deployment.Name, *deployment.Spec.Replicas)
+	}
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.Image != "nginx:" || container.Command[2] != "echo $HOME" {
+		t.Errorf("expected unset variables to be empty and other references to be left untouched, got image %q and command %q", container.Image, container.Command)
+	}
+
+	t.Setenv("NAME", "from-env")
+	t.Setenv("REPLICAS", "2")
+	var fromEnv appsv1.Deployment
+	if err := decoder.DecodeString(manifest, &fromEnv, decoder.WithEnvSubst()); err != nil {
+		t.Fatal(err)
+	}
+	if fromEnv.Name != "from-env" || *fromEnv.Spec.Replicas != 2 {
+		t.Errorf("expected the environment variables to be substituted, got name %q and %d replicas", fromEnv.Name, *fromEnv.Spec.Replicas)
+	}
+}
+
+func TestDecodeWithValidation(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: validated
+  namespace: default
+data:
+  foo: bar
+`
+	if _, err := decoder.DecodeAny(strings.NewReader(manifest), decoder.WithValidation(res)); err != nil {
+		t.Fatalf("expected a valid manifest to be decoded, got %v", err)
+	}
+	var cm v1.ConfigMap
+	if err := res.Get(context.TODO(), "validated", "default", &cm); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the validation not to create the object, got %v", err)
+	}
+
+	invalid := strings.Replace(manifest, "name: validated", "name: Invalid_Name", 1)
+	if _, err := decoder.DecodeAny(strings.NewReader(invalid), decoder.WithValidation(res)); err == nil {
+		t.Error("expected a manifest with an invalid name to fail validation")
+	}
+
+	missingNamespace := strings.Replace(manifest, "namespace: default", "namespace: not-created-yet", 1)
+	if _, err := decoder.DecodeAny(strings.NewReader(missingNamespace), decoder.WithValidation(res)); err != nil {
+		t.Errorf("expected an object missing its namespace to be skipped, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+)
+
+// EncodeOptions are a set of configurations used to instruct the encoding process
+type EncodeOptions struct {
+	// Scheme is used to look up the Group, Version and Kind of the typed objects. Defaults to the client-go scheme.
+	Scheme *runtime.Scheme
+	// IgnoredFields are the paths of the fields stripped from the objects, in addition to the volatile fields
+	IgnoredFields [][]string
+	// OmitStatus strips the status of the objects altogether
+	OmitStatus bool
+}
+
+// EncodeOption is a function that alters the configuration EncodeOptions used to encode objects
+type EncodeOption func(*EncodeOptions)
+
+// volatileFields are the paths of the fields set by the API server that change from a run to another
+var volatileFields = [][]string{
+	{"metadata", "resourceVersion"},
+	{"metadata", "uid"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "deletionTimestamp"},
+	{"metadata", "deletionGracePeriodSeconds"},
+	{"metadata", "managedFields"},
+	{"metadata", "selfLink"},
+	{"metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration"},
+	{"metadata", "labels", resources.TrackingLabel},
+}
+
+// volatileStatusFields are the names of the timestamp fields stripped anywhere in the status of the objects
+var volatileStatusFields = map[string]bool{
+	"lastHeartbeatTime":  true,
+	"lastProbeTime":      true,
+	"lastScheduleTime":   true,
+	"lastSuccessfulTime": true,
+	"lastTransitionTime": true,
+	"lastUpdateTime":     true,
+	"startTime":          true,
+	"startedAt":          true,
+	"finishedAt":         true,
+	"completionTime":     true,
+}
+
+// Encode serializes the object, e.g. as fetched from the cluster, to canonical YAML so that it can be compared
+// with a golden file stored in testdata. The fields of the object that are set by the API server and change
+// from a run to another, such as the resourceVersion, uid, managedFields and the timestamps of the status, are
+// stripped. The fields of the YAML document are sorted by name.
+func Encode(obj k8s.Object, options ...EncodeOption) ([]byte, error) {
+	encodeOpt := &EncodeOptions{}
+	for _, opt := range options {
+		opt(encodeOpt)
+	}
+	s := encodeOpt.Scheme
+	if s == nil {
+		s = scheme.Scheme
+	}
+
+	// the content of an unstructured object is its own map, which is copied so that obj is left unchanged
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj.DeepCopyObject())
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s to unstructured: %w", obj.GetName(), err)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	// typed objects read from the API server do not carry their type information
+	if u.GetKind() == "" {
+		gvk, err := apiutil.GVKForObject(obj, s)
+		if err != nil {
+			return nil, err
+		}
+		u.SetGroupVersionKind(gvk)
+	}
+
+	for _, fields := range [][][]string{volatileFields, encodeOpt.IgnoredFields} {
+		for _, field := range fields {
+			unstructured.RemoveNestedField(u.Object, field...)
+		}
+	}
+	for _, field := range []string{"labels", "annotations"} {
+		if m, found, _ := unstructured.NestedMap(u.Object, "metadata", field); found && len(m) == 0 {
+			unstructured.RemoveNestedField(u.Object, "metadata", field)
+		}
+	}
+	if encodeOpt.OmitStatus {
+		unstructured.RemoveNestedField(u.Object, "status")
+	} else if status, ok := u.Object["status"]; ok {
+		stripStatusTimestamps(status)
+	}
+
+	return yaml.Marshal(u.Object)
+}
+
+// EncodeAll serializes the objects to a multi-document YAML stream, see Encode
+func EncodeAll(objects []k8s.Object, options ...EncodeOption) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, obj := range objects {
+		b, err := Encode(obj, options...)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
+
+// stripStatusTimestamps removes the volatile timestamps found at any depth of the status
+func stripStatusTimestamps(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if volatileStatusFields[key] {
+				delete(v, key)
+				continue
+			}
+			stripStatusTimestamps(field)
+		}
+	case []interface{}:
+		for _, item := range v {
+			stripStatusTimestamps(item)
+		}
+	}
+}
+
+// WithEncodeScheme sets the scheme used to look up the Group, Version and Kind of the typed objects, e.g. the
+// types of custom resources
+func WithEncodeScheme(s *runtime.Scheme) EncodeOption {
+	return func(eo *EncodeOptions) {
+		eo.Scheme = s
+	}
+}
+
+// WithIgnoredField strips the field at the path fields from the encoded objects, in addition to the volatile
+// fields, e.g. WithIgnoredField("metadata", "annotations", "deployment.kubernetes.io/revision")
+func WithIgnoredField(fields ...string) EncodeOption {
+	return func(eo *EncodeOptions) {
+		eo.IgnoredFields = append(eo.IgnoredFields, fields)
+	}
+}
+
+// WithoutStatus strips the status of the encoded objects, so that only their desired state is compared
+func WithoutStatus() EncodeOption {
+	return func(eo *EncodeOptions) {
+		eo.OmitStatus = true
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+// DecodeEachKustomize builds the kustomization in the directory dir of the filesystem fsys, the same way
+// `kubectl kustomize` does, and decodes each of the rendered objects. The kustomization can reference other
+// directories of fsys, such as a shared base of overlays, but no remote resources.
+//
+// If handlerFn returns an error, decoding is halted.
+// Options may be provided to configure the behavior of the decoder.
+func DecodeEachKustomize(ctx context.Context, fsys fs.FS, dir string, handlerFn HandlerFunc, options ...DecodeOption) error {
+	rendered, err := buildKustomization(fsys, dir)
+	if err != nil {
+		return err
+	}
+	return DecodeEach(ctx, bytes.NewReader(rendered), handlerFn, options...)
+}
+
+// DecodeKustomize builds the kustomization in the directory dir of the filesystem fsys and returns the decoded
+// rendered objects, see DecodeEachKustomize.
+// Falls back to the unstructured.Unstructured type if a matching type cannot be found for the Kind.
+func DecodeKustomize(ctx context.Context, fsys fs.FS, dir string, options ...DecodeOption) ([]k8s.Object, error) {
+	objects := []k8s.Object{}
+	err := DecodeEachKustomize(ctx, fsys, dir, func(ctx context.Context, obj k8s.Object) error {
+		objects = append(objects, obj)
+		return nil
+	}, options...)
+	return objects, err
+}
+
+// buildKustomization runs kustomize against a copy of fsys in memory, as kustomize works with its own
+// filesystem abstraction
+func buildKustomization(fsys fs.FS, dir string) ([]byte, error) {
+	memfs := filesys.MakeFsInMemory()
+	err := fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := path.Join("/", file)
+		if d.IsDir() {
+			return memfs.MkdirAll(target)
+		}
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		return memfs.WriteFile(target, content)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kustomization files: %w", err)
+	}
+
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(memfs, path.Join("/", dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kustomization %q: %w", dir, err)
+	}
+	return resources.AsYaml()
+}
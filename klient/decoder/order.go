@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoder
+
+import (
+	"sort"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+// kindOrder is the order in which the kinds of objects are applied, so that the objects are created after the
+// objects they depend on: Namespaces first, then CustomResourceDefinitions, the cluster-scoped objects and the
+// namespaced objects, in the install order used by Helm, e.g. workloads after their ConfigMaps and Secrets. The
+// kinds not listed, such as custom resources, are applied after the built-in kinds, and only the API services and
+// webhook configurations after them, as these intercept the requests made for the other objects and need the
+// Services backing them to be ready.
+var kindOrder = []string{
+	"Namespace",
+	"CustomResourceDefinition",
+	// cluster-scoped
+	"PriorityClass",
+	"RuntimeClass",
+	"StorageClass",
+	"PersistentVolume",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"IngressClass",
+	// namespaced
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"PersistentVolumeClaim",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"Ingress",
+}
+
+// lastKinds are the kinds applied after the kinds not listed in kindOrder, see kindOrder
+var lastKinds = []string{
+	"APIService",
+	"MutatingWebhookConfiguration",
+	"ValidatingWebhookConfiguration",
+}
+
+var kindRank = func() map[string]int {
+	ranks := make(map[string]int, len(kindOrder)+len(lastKinds))
+	for i, kind := range kindOrder {
+		ranks[kind] = i
+	}
+	for i, kind := range lastKinds {
+		ranks[kind] = len(kindOrder) + 1 + i
+	}
+	return ranks
+}()
+
+func rankOf(obj k8s.Object) int {
+	if rank, ok := kindRank[obj.GetObjectKind().GroupVersionKind().Kind]; ok {
+		return rank
+	}
+	return len(kindOrder)
+}
+
+// isCustomResourceDefinition checks if the object is a CustomResourceDefinition
+func isCustomResourceDefinition(obj k8s.Object) bool {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition"
+}
+
+// SortForApply sorts the objects in the order they should be applied in, see kindOrder. The sort is stable, so
+// objects of the same kind are kept in the order they were decoded in.
+func SortForApply(objects []k8s.Object) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return rankOf(objects[i]) < rankOf(objects[j])
+	})
+}
+
+// SortForDelete sorts the objects in the order they should be deleted in, which is the reverse of the order
+// they are applied in.
+func SortForDelete(objects []k8s.Object) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return rankOf(objects[i]) > rankOf(objects[j])
+	})
+}
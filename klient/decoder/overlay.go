@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+// PatchTarget selects the decoded objects an overlay patch is applied to. The empty fields match all the objects.
+type PatchTarget struct {
+	// GVK is the Group, Version and Kind of the objects. An empty Version matches all the versions of the kind.
+	GVK *schema.GroupVersionKind
+	// Name is the name of the objects
+	Name string
+	// Namespace is the namespace of the objects
+	Namespace string
+	// LabelSelector is a label selector the labels of the objects must match, e.g. "app=nginx,tier!=cache"
+	LabelSelector string
+}
+
+// matches checks if the object is selected by the target
+func (t PatchTarget) matches(obj k8s.Object) (bool, error) {
+	if t.GVK != nil && !matchesGVK(*t.GVK, obj.GetObjectKind().GroupVersionKind()) {
+		return false, nil
+	}
+	if t.Name != "" && t.Name != obj.GetName() {
+		return false, nil
+	}
+	if t.Namespace != "" && t.Namespace != obj.GetNamespace() {
+		return false, nil
+	}
+	if t.LabelSelector != "" {
+		selector, err := labels.Parse(t.LabelSelector)
+		if err != nil {
+			return false, fmt.Errorf("invalid label selector %q: %w", t.LabelSelector, err)
+		}
+		return selector.Matches(labels.Set(obj.GetLabels())), nil
+	}
+	return true, nil
+}
+
+// MutateJSONPatch is an optional parameter to decoding functions that applies the RFC 6902 JSON patch to the
+// decoded objects selected by target. The patch can be written in JSON or YAML:
+//
+//	decoder.MutateJSONPatch(decoder.PatchTarget{Name: "nginx"}, []byte(`
+//	- op: replace
+//	  path: /spec/replicas
+//	  value: 3
+//	`))
+func MutateJSONPatch(target PatchTarget, patch []byte) DecodeOption {
+	return mutatePatch(target, patch, func(obj k8s.Object, original, patch []byte) ([]byte, error) {
+		p, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, err
+		}
+		return p.Apply(original)
+	})
+}
+
+// MutateStrategicMergePatch is an optional parameter to decoding functions that applies the strategic merge patch
+// to the decoded objects selected by target, the same way `kubectl patch` does. The patch can be written in JSON
+// or YAML, e.g. as a partial manifest overriding the resource limits of a container. The objects that are not
+// decoded into a registered type, such as the custom resources, are patched with a JSON merge patch instead.
+func MutateStrategicMergePatch(target PatchTarget, patch []byte) DecodeOption {
+	return mutatePatch(target, patch, func(obj k8s.Object, original, patch []byte) ([]byte, error) {
+		if _, ok := obj.(*unstructured.Unstructured); ok {
+			return jsonpatch.MergePatch(original, patch)
+		}
+		return strategicpatch.StrategicMergePatch(original, patch, obj)
+	})
+}
+
+// patchFunc returns the object patched with patch, given its JSON representation
+type patchFunc func(obj k8s.Object, original, patch []byte) ([]byte, error)
+
+// mutatePatch returns a MutateFunc applying the patch with patchFn to the objects selected by target
+func mutatePatch(target PatchTarget, patch []byte, patchFn patchFunc) DecodeOption {
+	return MutateOption(func(obj k8s.Object) error {
+		ok, err := target.matches(obj)
+		if err != nil || !ok {
+			return err
+		}
+		jsonPatch, err := yaml.YAMLToJSON(patch)
+		if err != nil {
+			return fmt.Errorf("invalid patch: %w", err)
+		}
+		original, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		patched, err := patchFn(obj, original, jsonPatch)
+		if err != nil {
+			return fmt.Errorf("failed to patch %s %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+		}
+		// reset the object so that the fields removed by the patch are not left over
+		v := reflect.ValueOf(obj).Elem()
+		v.Set(reflect.Zero(v.Type()))
+		return json.Unmarshal(patched, obj)
+	})
+}
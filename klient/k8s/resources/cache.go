@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+// WithCache returns a copy of Resources whose Get and List calls for the types of `objs` are served from a
+// shared informer cache instead of the apiserver, which reduces the load put on the apiserver by tight wait
+// loops and large parallel suites. The reads of other types, and all the writes, still go to the apiserver.
+// The cache is started and synced before the call returns, and is stopped once `ctx` is done.
+//
+// The objects read from the cache may lag behind the cluster, and List calls on cached types only support
+// field selectors on indexed fields. Use Live to read the current state from the apiserver.
+func (r *Resources) WithCache(ctx context.Context, objs ...k8s.Object) (*Resources, error) {
+	informers, err := cache.New(r.config, cache.Options{Scheme: r.scheme})
+	if err != nil {
+		return nil, fmt.Errorf("create cache: %w", err)
+	}
+
+	cachedTypes := make(map[schema.GroupVersionKind]bool, len(objs))
+	for _, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, r.scheme)
+		if err != nil {
+			return nil, fmt.Errorf("create cache: %w", err)
+		}
+		cachedTypes[gvk] = true
+		if _, err := informers.GetInformer(ctx, obj); err != nil {
+			return nil, fmt.Errorf("create cache for %s: %w", gvk, err)
+		}
+	}
+
+	go func() {
+		_ = informers.Start(ctx)
+	}()
+	if !informers.WaitForCacheSync(ctx) {
+		return nil, fmt.Errorf("create cache: cache not synced: %w", ctx.Err())
+	}
+
+	cached := *r
+	cached.cache = informers
+	cached.cachedTypes = cachedTypes
+	return &cached, nil
+}
+
+// Live returns a copy of Resources whose reads are served by the apiserver, bypassing the cache set up by
+// WithCache. This can be used to get the current state of an object after asserting on its cached state.
+func (r *Resources) Live() *Resources {
+	live := *r
+	live.cache = nil
+	live.cachedTypes = nil
+	return &live
+}
+
+// reader returns the reader to use for the object or list of objects
+func (r *Resources) reader(obj runtime.Object) cr.Reader {
+	if r.cache == nil {
+		return r.client
+	}
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return r.client
+	}
+	if meta.IsListType(obj) {
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+	if r.cachedTypes[gvk] {
+		return r.cache
+	}
+	return r.client
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CopyToPod copies the local file or directory `srcPath` to `destPath` in the container `containerName`
+// of the pod `podName`. Like `kubectl cp`, the copy is done by streaming a tar archive to the `tar` binary
+// of the container, which hence needs to be available in the container image.
+func (r *Resources) CopyToPod(ctx context.Context, namespaceName, podName, containerName, srcPath, destPath string) error {
+	if _, err := os.Stat(srcPath); err != nil {
+		return fmt.Errorf("copy to pod: %w", err)
+	}
+	destPath = path.Clean(destPath)
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+	go func() {
+		writer.CloseWithError(writeTar(writer, srcPath, path.Base(destPath)))
+	}()
+
+	var stderr bytes.Buffer
+	command := []string{"tar", "-xmf", "-", "-C", path.Dir(destPath)}
+	if err := r.execInPod(ctx, namespaceName, podName, containerName, command, reader, nil, &stderr); err != nil {
+		return fmt.Errorf("copy to pod %s/%s: %w: %s", namespaceName, podName, err, stderr.String())
+	}
+	return nil
+}
+
+// CopyFromPod copies the file or directory `srcPath` of the container `containerName` of the pod `podName`
+// to the local path `destPath`. Like `kubectl cp`, the copy is done by streaming a tar archive from the `tar`
+// binary of the container, which hence needs to be available in the container image.
+func (r *Resources) CopyFromPod(ctx context.Context, namespaceName, podName, containerName, srcPath, destPath string) error {
+	srcPath = path.Clean(srcPath)
+	reader, writer := io.Pipe()
+
+	var stderr bytes.Buffer
+	errChan := make(chan error, 1)
+	go func() {
+		command := []string{"tar", "-cf", "-", "-C", path.Dir(srcPath), path.Base(srcPath)}
+		err := r.execInPod(ctx, namespaceName, podName, containerName, command, nil, writer, &stderr)
+		writer.CloseWithError(err)
+		errChan <- err
+	}()
+
+	err := readTar(reader, path.Base(srcPath), destPath)
+	// drain the remaining stream so that the exec call can complete
+	_, _ = io.Copy(io.Discard, reader)
+	if execErr := <-errChan; execErr != nil {
+		return fmt.Errorf("copy from pod %s/%s: %w: %s", namespaceName, podName, execErr, stderr.String())
+	}
+	if err != nil {
+		return fmt.Errorf("copy from pod %s/%s: %w", namespaceName, podName, err)
+	}
+	return nil
+}
+
+// writeTar writes the file or directory srcPath as a tar archive whose entries are rooted at prefix
+func writeTar(w io.Writer, srcPath, prefix string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(srcPath, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcPath, file)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			// symlinks and special files are not copied, as with kubectl cp
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(prefix, filepath.ToSlash(rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// readTar extracts the entries of the tar archive rooted at prefix to destPath
+func readTar(r io.Reader, prefix, destPath string) error {
+	tr := tar.NewReader(r)
+	found := false
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(hdr.Name)
+		if name != prefix && !strings.HasPrefix(name, prefix+"/") {
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, prefix), "/")
+		if rel != "" && !filepath.IsLocal(rel) {
+			return fmt.Errorf("tar entry %q is outside of the copied path", hdr.Name)
+		}
+		target := filepath.Join(destPath, filepath.FromSlash(rel))
+		found = true
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr, hdr.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		default:
+			// symlinks and special files are not copied, as with kubectl cp
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s not found in archive", prefix)
+	}
+	return nil
+}
+
+func writeFile(target string, r io.Reader, perm os.FileMode) error {
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
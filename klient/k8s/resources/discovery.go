@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+)
+
+// HasGroupVersionKind checks if the apiserver serves the kind `gvk`, e.g. to skip a feature when the
+// CustomResourceDefinition or API group it relies on is not installed in the cluster.
+func (r *Resources) HasGroupVersionKind(gvk schema.GroupVersionKind) (bool, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(r.config)
+	if err != nil {
+		return false, err
+	}
+	resources, err := client.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == gvk.Kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ServerVersion returns the version of the apiserver
+func (r *Resources) ServerVersion() (*version.Info, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(r.config)
+	if err != nil {
+		return nil, err
+	}
+	return client.ServerVersion()
+}
+
+// ServerVersionAtLeast checks if the version of the apiserver is at least `minVersion`, e.g. "1.30" or "v1.30.2",
+// to skip a feature relying on an API or behavior introduced in a given Kubernetes version. Pre-release versions
+// of the apiserver count as the version they precede.
+func (r *Resources) ServerVersionAtLeast(minVersion string) (bool, error) {
+	minimum, err := utilversion.ParseGeneric(minVersion)
+	if err != nil {
+		return false, fmt.Errorf("parse minimum version: %w", err)
+	}
+	info, err := r.ServerVersion()
+	if err != nil {
+		return false, err
+	}
+	current, err := utilversion.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return false, fmt.Errorf("parse server version: %w", err)
+	}
+	return current.AtLeast(minimum), nil
+}
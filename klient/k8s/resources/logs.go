@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"io"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LogOption is used to provide additional arguments to the GetLogs and StreamLogs calls.
+type LogOption func(*v1.PodLogOptions)
+
+// WithLogContainer selects the container to get the logs of. It is required for pods with more than one container.
+func WithLogContainer(container string) LogOption {
+	return func(o *v1.PodLogOptions) { o.Container = container }
+}
+
+// WithPreviousLogs returns the logs of the previous instance of the container instead of the current one.
+func WithPreviousLogs() LogOption {
+	return func(o *v1.PodLogOptions) { o.Previous = true }
+}
+
+// WithTailLines limits the logs to the last `lines` lines.
+func WithTailLines(lines int64) LogOption {
+	return func(o *v1.PodLogOptions) { o.TailLines = &lines }
+}
+
+// WithSinceTime limits the logs to the ones written after `since`.
+func WithSinceTime(since time.Time) LogOption {
+	t := metav1.NewTime(since)
+	return func(o *v1.PodLogOptions) { o.SinceTime = &t }
+}
+
+// WithLogTimestamps prefixes each log line with its timestamp.
+func WithLogTimestamps() LogOption {
+	return func(o *v1.PodLogOptions) { o.Timestamps = true }
+}
+
+// WithFollow keeps the log stream open to receive new log lines. It is only meaningful with StreamLogs.
+func WithFollow() LogOption {
+	return func(o *v1.PodLogOptions) { o.Follow = true }
+}
+
+// GetLogs returns the logs of the pod `podName`
+func (r *Resources) GetLogs(ctx context.Context, namespaceName, podName string, opts ...LogOption) ([]byte, error) {
+	stream, err := r.StreamLogs(ctx, namespaceName, podName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return io.ReadAll(stream)
+}
+
+// StreamLogs returns a stream of the logs of the pod `podName`. The caller is responsible for closing the stream.
+func (r *Resources) StreamLogs(ctx context.Context, namespaceName, podName string, opts ...LogOption) (io.ReadCloser, error) {
+	logOptions := &v1.PodLogOptions{}
+	for _, fn := range opts {
+		fn(logOptions)
+	}
+
+	clientset, err := kubernetes.NewForConfig(r.config)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().Pods(namespaceName).GetLogs(podName, logOptions).Stream(ctx)
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	klog "k8s.io/klog/v2"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PortForwardOptions are the options used to configure a port forwarding session
+type PortForwardOptions struct {
+	// LocalPort is the local port to listen on. When set to 0, a free port is picked.
+	LocalPort int
+	// ReconnectInterval is the time to wait before re-establishing a dropped connection.
+	ReconnectInterval time.Duration
+}
+
+// PortForwardOption is used to provide additional arguments to the port forwarding calls
+type PortForwardOption func(*PortForwardOptions)
+
+// WithLocalPort sets the local port to listen on instead of picking a free one
+func WithLocalPort(port int) PortForwardOption {
+	return func(o *PortForwardOptions) { o.LocalPort = port }
+}
+
+// WithReconnectInterval sets the time to wait before a dropped port forwarding connection is re-established
+func WithReconnectInterval(interval time.Duration) PortForwardOption {
+	return func(o *PortForwardOptions) { o.ReconnectInterval = interval }
+}
+
+// PortForward is a port forwarding session from a local port to a port of a pod.
+// The session is re-established whenever the connection drops, until the context used to
+// start it is canceled or Close is called.
+type PortForward struct {
+	localPort int
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// LocalPort returns the local port that is forwarded
+func (p *PortForward) LocalPort() int {
+	return p.localPort
+}
+
+// Address returns the local address, in host:port form, that is forwarded
+func (p *PortForward) Address() string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(p.localPort))
+}
+
+// Done returns a channel that is closed once the port forwarding session has stopped
+func (p *PortForward) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns the last error observed by the port forwarding session, if any
+func (p *PortForward) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Close stops the port forwarding session and waits for it to finish
+func (p *PortForward) Close() {
+	p.cancel()
+	<-p.done
+}
+
+func (p *PortForward) setErr(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// portForwardTarget resolves the pod name and pod port to forward to
+type portForwardTarget func(ctx context.Context) (string, int, error)
+
+// PortForwardPod forwards a local port to the port `remotePort` of the pod `podName`.
+// The call returns once the local port is ready to accept connections.
+func (r *Resources) PortForwardPod(ctx context.Context, namespace, podName string, remotePort int, opts ...PortForwardOption) (*PortForward, error) {
+	return r.portForward(ctx, namespace, func(context.Context) (string, int, error) {
+		return podName, remotePort, nil
+	}, opts...)
+}
+
+// PortForwardService forwards a local port to the port `servicePort` of the service `serviceName`.
+// As with kubectl, the traffic is forwarded to one of the ready pods selected by the service, which is
+// selected again every time the connection is re-established.
+func (r *Resources) PortForwardService(ctx context.Context, namespace, serviceName string, servicePort int, opts ...PortForwardOption) (*PortForward, error) {
+	return r.portForward(ctx, namespace, func(ctx context.Context) (string, int, error) {
+		return r.resolveServicePort(ctx, namespace, serviceName, servicePort)
+	}, opts...)
+}
+
+func (r *Resources) portForward(ctx context.Context, namespace string, target portForwardTarget, opts ...PortForwardOption) (*PortForward, error) {
+	options := &PortForwardOptions{ReconnectInterval: time.Second}
+	for _, fn := range opts {
+		fn(options)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	pf := &PortForward{localPort: options.LocalPort, cancel: cancel, done: make(chan struct{})}
+
+	started := make(chan error, 1)
+	go func() {
+		defer close(pf.done)
+		ready := false
+		for {
+			err := r.forwardOnce(ctx, namespace, target, pf.localPort, func(localPort int) {
+				if !ready {
+					// keep the picked local port for the reconnections
+					pf.localPort = localPort
+					ready = true
+					started <- nil
+				}
+			})
+			if !ready {
+				started <- err
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			pf.setErr(err)
+			klog.V(4).InfoS("Port forwarding connection dropped, reconnecting", "namespace", namespace, "localPort", pf.localPort, "err", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(options.ReconnectInterval):
+			}
+		}
+	}()
+
+	if err := <-started; err != nil {
+		cancel()
+		<-pf.done
+		return nil, fmt.Errorf("port forward: %w", err)
+	}
+	return pf, nil
+}
+
+// forwardOnce establishes a single port forwarding connection and blocks until it drops or the context is canceled
+func (r *Resources) forwardOnce(ctx context.Context, namespace string, target portForwardTarget, localPort int, onReady func(localPort int)) error {
+	podName, remotePort, err := target(ctx)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := kubernetes.NewForConfig(r.config)
+	if err != nil {
+		return err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(r.config)
+	if err != nil {
+		return err
+	}
+	url := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("portforward").
+		URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	stopChan, readyChan := make(chan struct{}), make(chan struct{})
+	ports := []string{fmt.Sprintf("%d:%d", localPort, remotePort)}
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, ports, stopChan, readyChan, io.Discard, io.Discard)
+	if err != nil {
+		return err
+	}
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- forwarder.ForwardPorts() }()
+
+	for {
+		select {
+		case <-readyChan:
+			forwarded, err := forwarder.GetPorts()
+			if err != nil {
+				close(stopChan)
+				<-errChan
+				return err
+			}
+			onReady(int(forwarded[0].Local))
+			readyChan = nil
+		case err := <-errChan:
+			if err == nil {
+				err = portforward.ErrLostConnectionToPod
+			}
+			return err
+		case <-ctx.Done():
+			close(stopChan)
+			<-errChan
+			return ctx.Err()
+		}
+	}
+}
+
+// resolveServicePort picks a ready pod selected by the service and resolves the target port of the service port
+func (r *Resources) resolveServicePort(ctx context.Context, namespace, serviceName string, servicePort int) (string, int, error) {
+	var svc v1.Service
+	if err := r.Get(ctx, serviceName, namespace, &svc); err != nil {
+		return "", 0, err
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return "", 0, fmt.Errorf("service %s/%s has no selector", namespace, serviceName)
+	}
+
+	var targetPort *intstr.IntOrString
+	for i := range svc.Spec.Ports {
+		if int(svc.Spec.Ports[i].Port) == servicePort {
+			targetPort = &svc.Spec.Ports[i].TargetPort
+			break
+		}
+	}
+	if targetPort == nil {
+		return "", 0, fmt.Errorf("service %s/%s has no port %d", namespace, serviceName, servicePort)
+	}
+
+	var pods v1.PodList
+	selector := labels.SelectorFromSet(svc.Spec.Selector)
+	if err := r.client.List(ctx, &pods, cr.InNamespace(namespace), cr.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return "", 0, err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || !isPodReady(pod) {
+			continue
+		}
+		switch {
+		case targetPort.Type == intstr.String:
+			for _, c := range pod.Spec.Containers {
+				for _, p := range c.Ports {
+					if p.Name == targetPort.StrVal {
+						return pod.Name, int(p.ContainerPort), nil
+					}
+				}
+			}
+		case targetPort.IntVal != 0:
+			return pod.Name, int(targetPort.IntVal), nil
+		default:
+			return pod.Name, servicePort, nil
+		}
+	}
+	return "", 0, fmt.Errorf("no ready pods found for service %s/%s", namespace, serviceName)
+}
+
+func isPodReady(pod *v1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodReady {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
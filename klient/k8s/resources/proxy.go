@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// ProxyResponse is the response of a request sent through the apiserver proxy
+type ProxyResponse struct {
+	// StatusCode is the HTTP status code returned by the proxied endpoint
+	StatusCode int
+	// Body is the body returned by the proxied endpoint
+	Body []byte
+}
+
+// ProxyService sends an HTTP request through the apiserver proxy to the port `port` of the service
+// `serviceName` and returns the response. The port can be a port number or name, optionally prefixed
+// by the scheme to use (e.g. "https:443"). Non-2xx responses are not returned as errors, so that the
+// status code can be asserted on.
+func (r *Resources) ProxyService(ctx context.Context, namespaceName, serviceName, port, method, path string, body []byte) (*ProxyResponse, error) {
+	return r.proxy(ctx, "services", namespaceName, serviceName, port, method, path, body)
+}
+
+// ProxyPod sends an HTTP request through the apiserver proxy to the port `port` of the pod `podName`
+// and returns the response. The port can be a port number, optionally prefixed by the scheme to use
+// (e.g. "https:8443"). Non-2xx responses are not returned as errors, so that the status code can be
+// asserted on.
+func (r *Resources) ProxyPod(ctx context.Context, namespaceName, podName, port, method, path string, body []byte) (*ProxyResponse, error) {
+	return r.proxy(ctx, "pods", namespaceName, podName, port, method, path, body)
+}
+
+func (r *Resources) proxy(ctx context.Context, resource, namespaceName, name, port, method, path string, body []byte) (*ProxyResponse, error) {
+	clientset, err := kubernetes.NewForConfig(r.config)
+	if err != nil {
+		return nil, err
+	}
+
+	// the scheme, when provided, prefixes the name in the proxy URL: <scheme>:<name>:<port>
+	target := name
+	if port != "" {
+		if scheme, p, found := strings.Cut(port, ":"); found {
+			target = scheme + ":" + name + ":" + p
+		} else {
+			target = name + ":" + port
+		}
+	}
+
+	req := clientset.CoreV1().RESTClient().Verb(method).
+		Namespace(namespaceName).
+		Resource(resource).
+		Name(target).
+		SubResource("proxy").
+		Suffix(path)
+	if body != nil {
+		req = req.Body(bytes.NewReader(body))
+	}
+
+	var statusCode int
+	result := req.Do(ctx).StatusCode(&statusCode)
+	raw, err := result.Raw()
+	if statusCode == 0 {
+		return nil, fmt.Errorf("proxy %s %s/%s: %w", resource, namespaceName, name, err)
+	}
+	return &ProxyResponse{StatusCode: statusCode, Body: raw}, nil
+}
@@ -17,22 +17,35 @@ limitations under the License.
 package resources
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/retry"
 	klog "k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	cr "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"sigs.k8s.io/e2e-framework/klient/k8s"
@@ -51,6 +64,13 @@ type Resources struct {
 
 	// namespace for namespaced object requests
 	namespace string
+
+	// tracker records the objects created when tracking is enabled, see WithTracking
+	tracker *tracker
+
+	// cache serves the reads of the types in cachedTypes when set, see WithCache
+	cache       cache.Cache
+	cachedTypes map[schema.GroupVersionKind]bool
 }
 
 // New instantiates the controller runtime client
@@ -58,24 +78,57 @@ type Resources struct {
 // 1. if user does not provide k8s config
 // 2. if controller runtime client instantiation fails.
 func New(cfg *rest.Config) (*Resources, error) {
+	return NewWithScheme(cfg, scheme.Scheme)
+}
+
+// NewWithScheme instantiates the controller runtime client object with a custom scheme, which maps the Go types
+// used with Resources to their GroupVersionKinds. This can be used to work with the typed objects of custom
+// resources without registering them with the global client-go scheme used by New.
+func NewWithScheme(cfg *rest.Config, s *runtime.Scheme) (*Resources, error) {
 	if cfg == nil {
 		return nil, errors.New("must provide rest.Config")
 	}
+	if s == nil {
+		return nil, errors.New("must provide runtime.Scheme")
+	}
 
-	cl, err := cr.New(cfg, cr.Options{Scheme: scheme.Scheme})
+	cl, err := cr.New(cfg, cr.Options{Scheme: s})
 	if err != nil {
 		return nil, err
 	}
 
 	res := &Resources{
 		config: cfg,
-		scheme: scheme.Scheme,
+		scheme: s,
 		client: cl,
 	}
 
 	return res, nil
 }
 
+// Impersonate returns a copy of Resources whose requests impersonate the identity `impersonate`, so that the
+// permissions of the identity can be tested, e.g. to check that it can or cannot perform operations according
+// to its RBAC rules. The cache set up by WithCache, if any, is not used by the copy.
+func (r *Resources) Impersonate(impersonate rest.ImpersonationConfig) (*Resources, error) {
+	cfg := rest.CopyConfig(r.config)
+	cfg.Impersonate = impersonate
+	res, err := NewWithScheme(cfg, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+	res.namespace = r.namespace
+	return res, nil
+}
+
+// ServiceAccountImpersonation returns the impersonation configuration of the ServiceAccount `name` of the
+// namespace `namespace`, with the groups the apiserver assigns to authenticated ServiceAccounts.
+func ServiceAccountImpersonation(namespace, name string) rest.ImpersonationConfig {
+	return rest.ImpersonationConfig{
+		UserName: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name),
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"},
+	}
+}
+
 // GetConfig hepls to get config type *rest.Config
 func (r *Resources) GetConfig() *rest.Config {
 	return r.config
@@ -86,8 +139,16 @@ func (r *Resources) WithNamespace(ns string) *Resources {
 	return r
 }
 
+// Copy returns a copy of Resources that shares its scheme, its client along with the retries set up by WithRetry,
+// its cache and its tracking session. The namespace of the copy can be changed with WithNamespace without
+// affecting r, so that the copy can be used concurrently with r.
+func (r *Resources) Copy() *Resources {
+	c := *r
+	return &c
+}
+
 func (r *Resources) Get(ctx context.Context, name, namespace string, obj k8s.Object) error {
-	return r.client.Get(ctx, cr.ObjectKey{Namespace: namespace, Name: name}, obj)
+	return r.reader(obj).Get(ctx, cr.ObjectKey{Namespace: namespace, Name: name}, obj)
 }
 
 type CreateOption func(*metav1.CreateOptions)
@@ -99,16 +160,50 @@ func (r *Resources) Create(ctx context.Context, obj k8s.Object, opts ...CreateOp
 	}
 
 	o := &cr.CreateOptions{
-		Raw:          createOptions,
-		DryRun:       createOptions.DryRun,
-		FieldManager: createOptions.FieldManager,
+		Raw:             createOptions,
+		DryRun:          createOptions.DryRun,
+		FieldManager:    createOptions.FieldManager,
+		FieldValidation: createOptions.FieldValidation,
+	}
+
+	dryRun := len(createOptions.DryRun) > 0
+	if !dryRun {
+		r.labelTracked(obj)
+	}
+	if err := r.client.Create(ctx, obj, o); err != nil {
+		return err
+	}
+	if !dryRun {
+		r.track(obj)
 	}
+	return nil
+}
+
+// WithDryRun runs the create request as a server-side dry run: the request goes through admission
+// and validation, and the defaulted object is returned, but nothing is persisted.
+func WithDryRun() CreateOption {
+	return func(co *metav1.CreateOptions) { co.DryRun = []string{metav1.DryRunAll} }
+}
 
-	return r.client.Create(ctx, obj, o)
+// WithStrictValidation makes the API server reject the create request if the object has unknown or duplicate
+// fields, the same way `kubectl create --validate=strict` does, instead of dropping them silently.
+func WithStrictValidation() CreateOption {
+	return func(co *metav1.CreateOptions) { co.FieldValidation = metav1.FieldValidationStrict }
 }
 
 type UpdateOption func(*metav1.UpdateOptions)
 
+// WithUpdateDryRun runs the update request as a server-side dry run, see WithDryRun.
+func WithUpdateDryRun() UpdateOption {
+	return func(uo *metav1.UpdateOptions) { uo.DryRun = []string{metav1.DryRunAll} }
+}
+
+// WithUpdateStrictValidation rejects the update request if the object has unknown or duplicate fields, see
+// WithStrictValidation.
+func WithUpdateStrictValidation() UpdateOption {
+	return func(uo *metav1.UpdateOptions) { uo.FieldValidation = metav1.FieldValidationStrict }
+}
+
 func (r *Resources) Update(ctx context.Context, obj k8s.Object, opts ...UpdateOption) error {
 	updateOptions := &metav1.UpdateOptions{}
 	for _, fn := range opts {
@@ -116,13 +211,30 @@ func (r *Resources) Update(ctx context.Context, obj k8s.Object, opts ...UpdateOp
 	}
 
 	o := &cr.UpdateOptions{
-		Raw:          updateOptions,
-		DryRun:       updateOptions.DryRun,
-		FieldManager: updateOptions.FieldManager,
+		Raw:             updateOptions,
+		DryRun:          updateOptions.DryRun,
+		FieldManager:    updateOptions.FieldManager,
+		FieldValidation: updateOptions.FieldValidation,
 	}
 	return r.client.Update(ctx, obj, o)
 }
 
+// UpdateWithRetry applies the mutation `mutateFn` to the latest state of the object `obj` and updates it. When
+// the update conflicts with the changes made by another writer, such as a controller, the object is fetched again
+// and the mutation re-applied, so `mutateFn` must be safe to call more than once. An error returned by `mutateFn`
+// aborts the update.
+func (r *Resources) UpdateWithRetry(ctx context.Context, obj k8s.Object, mutateFn func(obj k8s.Object) error, opts ...UpdateOption) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
+			return err
+		}
+		if err := mutateFn(obj); err != nil {
+			return err
+		}
+		return r.Update(ctx, obj, opts...)
+	})
+}
+
 // UpdateSubresource updates the subresource of the object
 func (r *Resources) UpdateSubresource(ctx context.Context, obj k8s.Object, subresource string, opts ...UpdateOption) error {
 	updateOptions := &metav1.UpdateOptions{}
@@ -135,7 +247,8 @@ func (r *Resources) UpdateSubresource(ctx context.Context, obj k8s.Object, subre
 	return r.client.SubResource(subresource).Update(ctx, obj, o)
 }
 
-// UpdateStatus updates the status of the object
+// UpdateStatus updates the status of the object through the status subresource. For types with the status
+// subresource enabled, this is the only way to write the status, as Update ignores it.
 func (r *Resources) UpdateStatus(ctx context.Context, obj k8s.Object, opts ...UpdateOption) error {
 	return r.UpdateSubresource(ctx, obj, "status", opts...)
 }
@@ -158,19 +271,96 @@ func (r *Resources) Delete(ctx context.Context, obj k8s.Object, opts ...DeleteOp
 	return r.client.Delete(ctx, obj, o)
 }
 
+const (
+	// defaultDeleteAndWaitTimeout is the time DeleteAndWait waits for the object to be gone when no timeout is provided
+	defaultDeleteAndWaitTimeout = 5 * time.Minute
+	// deleteAndWaitInterval is the interval between the checks of DeleteAndWait
+	deleteAndWaitInterval = time.Second
+)
+
+// DeleteAndWait deletes the object `obj` and blocks until it is gone from the API, e.g. once its finalizers
+// have run, or until `timeout` expires. A zero timeout defaults to 5 minutes. The delete request uses the
+// Foreground propagation policy by default, so that the dependents of the object are gone as well when the
+// call returns; this can be overridden with WithPropagationPolicy. An object that is already gone is not an error.
+func (r *Resources) DeleteAndWait(ctx context.Context, obj k8s.Object, timeout time.Duration, opts ...DeleteOption) error {
+	if timeout <= 0 {
+		timeout = defaultDeleteAndWaitTimeout
+	}
+	opts = append([]DeleteOption{WithForegroundDeletion()}, opts...)
+	if err := r.Delete(ctx, obj, opts...); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	current, ok := obj.DeepCopyObject().(k8s.Object)
+	if !ok {
+		return fmt.Errorf("delete and wait: unexpected type %T", obj)
+	}
+	err := apimachinerywait.PollUntilContextTimeout(ctx, deleteAndWaitInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		err := r.Get(ctx, obj.GetName(), obj.GetNamespace(), current)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for %s/%s to be deleted: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// WithDeleteDryRun runs the delete request as a server-side dry run, see WithDryRun.
+func WithDeleteDryRun() DeleteOption {
+	return func(do *metav1.DeleteOptions) { do.DryRun = []string{metav1.DryRunAll} }
+}
+
+// WithGracePeriod sets the duration the object has to terminate gracefully before it is deleted. The duration
+// is rounded down to the second, and a zero duration deletes the object immediately.
 func WithGracePeriod(gpt time.Duration) DeleteOption {
-	t := gpt.Milliseconds()
+	t := int64(gpt.Seconds())
 	return func(do *metav1.DeleteOptions) { do.GracePeriodSeconds = &t }
 }
 
+// WithDeletePropagation sets the propagation policy of the deletion to the dependents of the object, which
+// is one of "Foreground", "Background" or "Orphan".
 func WithDeletePropagation(prop string) DeleteOption {
-	p := metav1.DeletionPropagation(prop)
-	return func(do *metav1.DeleteOptions) { do.PropagationPolicy = &p }
+	return WithPropagationPolicy(metav1.DeletionPropagation(prop))
+}
+
+// WithPropagationPolicy sets the propagation policy of the deletion to the dependents of the object.
+func WithPropagationPolicy(policy metav1.DeletionPropagation) DeleteOption {
+	return func(do *metav1.DeleteOptions) { do.PropagationPolicy = &policy }
+}
+
+// WithForegroundDeletion deletes the dependents of the object before the object itself.
+func WithForegroundDeletion() DeleteOption {
+	return WithPropagationPolicy(metav1.DeletePropagationForeground)
+}
+
+// WithBackgroundDeletion deletes the object immediately and lets the garbage collector delete its dependents.
+func WithBackgroundDeletion() DeleteOption {
+	return WithPropagationPolicy(metav1.DeletePropagationBackground)
+}
+
+// WithOrphanDependents deletes the object and leaves its dependents in place.
+func WithOrphanDependents() DeleteOption {
+	return WithPropagationPolicy(metav1.DeletePropagationOrphan)
 }
 
 type ListOption func(*metav1.ListOptions)
 
 func (r *Resources) List(ctx context.Context, objs k8s.ObjectList, opts ...ListOption) error {
+	o, err := r.listOptions(opts...)
+	if err != nil {
+		return err
+	}
+	return r.reader(objs).List(ctx, objs, o)
+}
+
+// listOptions converts the list options to the controller-runtime ones, scoped to the namespace of Resources
+func (r *Resources) listOptions(opts ...ListOption) (*cr.ListOptions, error) {
 	listOptions := &metav1.ListOptions{}
 
 	for _, fn := range opts {
@@ -179,11 +369,11 @@ func (r *Resources) List(ctx context.Context, objs k8s.ObjectList, opts ...ListO
 
 	ls, err := labels.Parse(listOptions.LabelSelector)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	fs, err := fields.ParseSelector(listOptions.FieldSelector)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	o := &cr.ListOptions{
@@ -196,8 +386,58 @@ func (r *Resources) List(ctx context.Context, objs k8s.ObjectList, opts ...ListO
 	if r.namespace != "" {
 		o.Namespace = r.namespace
 	}
+	return o, nil
+}
+
+// defaultListEachLimit is the page size used by ListEach when no limit is provided
+const defaultListEachLimit = 500
+
+// ListEach lists the objects of the type of `objs` page by page, and calls `fn` for each of them.
+// The page size can be set with WithLimit and defaults to 500 objects, so that large collections can be
+// walked without loading them in memory all at once. `objs` is reused to hold each page. Listing stops at
+// the first error returned by `fn`, which is returned.
+func (r *Resources) ListEach(ctx context.Context, objs k8s.ObjectList, fn func(obj k8s.Object) error, opts ...ListOption) error {
+	listOptions := &metav1.ListOptions{}
+	for _, opt := range opts {
+		opt(listOptions)
+	}
+	limit := listOptions.Limit
+	if limit <= 0 {
+		limit = defaultListEachLimit
+	}
+
+	continueToken := listOptions.Continue
+	for {
+		pageOpts := append(append([]ListOption{}, opts...), WithLimit(limit), WithContinue(continueToken))
+		if err := r.List(ctx, objs, pageOpts...); err != nil {
+			return err
+		}
+		err := meta.EachListItem(objs, func(item runtime.Object) error {
+			obj, ok := item.(k8s.Object)
+			if !ok {
+				return fmt.Errorf("list item %T is not a k8s.Object", item)
+			}
+			return fn(obj)
+		})
+		if err != nil {
+			return err
+		}
+		continueToken = objs.GetContinue()
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
+
+// WithLimit sets the maximum number of objects returned by a List call. The remaining objects can be
+// retrieved with the continue token of the returned list, see WithContinue.
+func WithLimit(limit int64) ListOption {
+	return func(lo *metav1.ListOptions) { lo.Limit = limit }
+}
 
-	return r.client.List(ctx, objs, o)
+// WithContinue sets the continue token returned by a previous List call, to retrieve the next page of objects.
+func WithContinue(token string) ListOption {
+	return func(lo *metav1.ListOptions) { lo.Continue = token }
 }
 
 func WithLabelSelector(sel string) ListOption {
@@ -208,14 +448,26 @@ func WithFieldSelector(sel string) ListOption {
 	return func(lo *metav1.ListOptions) { lo.FieldSelector = sel }
 }
 
+// WithTimeout sets the timeout of the list or watch request. The duration is rounded down to the second.
 func WithTimeout(to time.Duration) ListOption {
-	t := to.Milliseconds()
+	t := int64(to.Seconds())
 	return func(lo *metav1.ListOptions) { lo.TimeoutSeconds = &t }
 }
 
 // PatchOption is used to provide additional arguments to the Patch call.
 type PatchOption func(*metav1.PatchOptions)
 
+// WithPatchDryRun runs the patch request as a server-side dry run, see WithDryRun.
+func WithPatchDryRun() PatchOption {
+	return func(po *metav1.PatchOptions) { po.DryRun = []string{metav1.DryRunAll} }
+}
+
+// WithPatchStrictValidation rejects the patch request if the patch or the patched object has unknown or
+// duplicate fields, see WithStrictValidation.
+func WithPatchStrictValidation() PatchOption {
+	return func(po *metav1.PatchOptions) { po.FieldValidation = metav1.FieldValidationStrict }
+}
+
 // Patch patches portion of object `obj` with data from object `patch`
 func (r *Resources) Patch(ctx context.Context, obj k8s.Object, patch k8s.Patch, opts ...PatchOption) error {
 	patchOptions := &metav1.PatchOptions{}
@@ -227,14 +479,149 @@ func (r *Resources) Patch(ctx context.Context, obj k8s.Object, patch k8s.Patch,
 	p := cr.RawPatch(patch.PatchType, patch.Data)
 
 	o := &cr.PatchOptions{
-		Raw:          patchOptions,
-		DryRun:       patchOptions.DryRun,
-		Force:        patchOptions.Force,
-		FieldManager: patchOptions.FieldManager,
+		Raw:             patchOptions,
+		DryRun:          patchOptions.DryRun,
+		Force:           patchOptions.Force,
+		FieldManager:    patchOptions.FieldManager,
+		FieldValidation: patchOptions.FieldValidation,
 	}
 	return r.client.Patch(ctx, obj, p, o)
 }
 
+// DefaultFieldManager is the field manager used by Apply when none is provided with WithFieldManager
+const DefaultFieldManager = "e2e-framework"
+
+// Apply server-side applies the object `obj`: the fields set in `obj` are owned by the field manager of the
+// request, which defaults to DefaultFieldManager and can be set with WithFieldManager. The object is created
+// if it does not exist, and updated with the result. Conflicts with the fields owned by other managers are
+// returned as errors unless WithForceOwnership is used. An object applied with WithPatchDryRun is not tracked, as
+// it is not persisted.
+func (r *Resources) Apply(ctx context.Context, obj k8s.Object, opts ...PatchOption) error {
+	patchOptions := &metav1.PatchOptions{}
+	for _, fn := range opts {
+		fn(patchOptions)
+	}
+	dryRun := len(patchOptions.DryRun) > 0
+	if !dryRun {
+		r.labelTracked(obj)
+	}
+	data, err := r.applyConfiguration(obj)
+	if err != nil {
+		return err
+	}
+	opts = append([]PatchOption{WithFieldManager(DefaultFieldManager)}, opts...)
+	if err := r.PatchBytes(ctx, obj, types.ApplyPatchType, data, opts...); err != nil {
+		return err
+	}
+	if !dryRun {
+		r.track(obj)
+	}
+	return nil
+}
+
+// ApplyStatus server-side applies the status of the object `obj` through the status subresource, the way
+// controllers built with controller-runtime do: the status fields set in `obj` are owned by the field manager
+// of the request, which defaults to DefaultFieldManager and can be set with WithFieldManager. This lets tests
+// simulating a controller own specific status fields without overwriting the fields owned by others.
+func (r *Resources) ApplyStatus(ctx context.Context, obj k8s.Object, opts ...PatchOption) error {
+	return r.ApplySubresource(ctx, obj, "status", opts...)
+}
+
+// ApplySubresource server-side applies the subresource `subresource` of the object `obj`, see ApplyStatus
+func (r *Resources) ApplySubresource(ctx context.Context, obj k8s.Object, subresource string, opts ...PatchOption) error {
+	data, err := r.applyConfiguration(obj)
+	if err != nil {
+		return err
+	}
+	opts = append([]PatchOption{WithFieldManager(DefaultFieldManager)}, opts...)
+	return r.PatchSubresource(ctx, obj, subresource, k8s.Patch{PatchType: types.ApplyPatchType, Data: data}, opts...)
+}
+
+// applyConfiguration returns the apply configuration of the object. Server-side apply requires the type
+// information to be set and the managed fields to be unset.
+func (r *Resources) applyConfiguration(obj k8s.Object) ([]byte, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return nil, fmt.Errorf("apply: %w", err)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetManagedFields(nil)
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("apply: %w", err)
+	}
+	return data, nil
+}
+
+// WithFieldManager sets the name of the actor making the changes, which owns the changed fields
+func WithFieldManager(manager string) PatchOption {
+	return func(po *metav1.PatchOptions) { po.FieldManager = manager }
+}
+
+// WithForceOwnership makes a server-side apply take the ownership of the fields owned by other managers
+// instead of failing with a conflict
+func WithForceOwnership() PatchOption {
+	force := true
+	return func(po *metav1.PatchOptions) { po.Force = &force }
+}
+
+// PatchBytes patches portion of object `obj` with the raw `data` of the given patch type
+func (r *Resources) PatchBytes(ctx context.Context, obj k8s.Object, patchType types.PatchType, data []byte, opts ...PatchOption) error {
+	return r.Patch(ctx, obj, k8s.Patch{PatchType: patchType, Data: data}, opts...)
+}
+
+// MergePatch patches portion of object `obj` with a JSON merge patch (RFC 7386) built from `patch`
+func (r *Resources) MergePatch(ctx context.Context, obj k8s.Object, patch map[string]interface{}, opts ...PatchOption) error {
+	return r.patchWithMap(ctx, obj, types.MergePatchType, patch, opts...)
+}
+
+// StrategicMergePatch patches portion of object `obj` with a strategic merge patch built from `patch`.
+// Strategic merge patches are only supported for built-in types.
+func (r *Resources) StrategicMergePatch(ctx context.Context, obj k8s.Object, patch map[string]interface{}, opts ...PatchOption) error {
+	return r.patchWithMap(ctx, obj, types.StrategicMergePatchType, patch, opts...)
+}
+
+// JSONPatchOperation is a single operation of a JSON patch (RFC 6902)
+type JSONPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON encodes the operation with its value, even when it is null, unless the operation is a remove, move
+// or copy, which take no value
+func (o JSONPatchOperation) MarshalJSON() ([]byte, error) {
+	// jsonPatchOperation drops the methods of the operation, so that it is encoded field by field
+	type jsonPatchOperation JSONPatchOperation
+	switch o.Op {
+	case "remove", "move", "copy":
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+			From string `json:"from,omitempty"`
+		}{Op: o.Op, Path: o.Path, From: o.From})
+	}
+	return json.Marshal(jsonPatchOperation(o))
+}
+
+// JSONPatch patches portion of object `obj` with the JSON patch (RFC 6902) operations `ops`
+func (r *Resources) JSONPatch(ctx context.Context, obj k8s.Object, ops []JSONPatchOperation, opts ...PatchOption) error {
+	data, err := json.Marshal(ops)
+	if err != nil {
+		return fmt.Errorf("marshal json patch: %w", err)
+	}
+	return r.PatchBytes(ctx, obj, types.JSONPatchType, data, opts...)
+}
+
+func (r *Resources) patchWithMap(ctx context.Context, obj k8s.Object, patchType types.PatchType, patch map[string]interface{}, opts ...PatchOption) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", patchType, err)
+	}
+	return r.PatchBytes(ctx, obj, patchType, data, opts...)
+}
+
 // PatchSubresource patches portion of object `obj` with data from object `patch`
 func (r *Resources) PatchSubresource(ctx context.Context, obj k8s.Object, subresource string, patch k8s.Patch, opts ...PatchOption) error {
 	patchOptions := &metav1.PatchOptions{}
@@ -255,6 +642,146 @@ func (r *Resources) PatchStatus(ctx context.Context, objs k8s.Object, patch k8s.
 	return r.PatchSubresource(ctx, objs, "status", patch, opts...)
 }
 
+// Evict evicts the pod `pod` through the eviction subresource, the same way `kubectl drain` does, so that
+// PodDisruptionBudgets are honored. An eviction refused because of a PodDisruptionBudget is returned as an
+// error for which apierrors.IsTooManyRequests is true.
+func (r *Resources) Evict(ctx context.Context, pod k8s.Object, opts ...DeleteOption) error {
+	deleteOptions := &metav1.DeleteOptions{}
+	for _, fn := range opts {
+		fn(deleteOptions)
+	}
+
+	eviction := &policyv1.Eviction{
+		ObjectMeta:    metav1.ObjectMeta{Name: pod.GetName(), Namespace: pod.GetNamespace()},
+		DeleteOptions: deleteOptions,
+	}
+	return r.client.SubResource("eviction").Create(ctx, pod, eviction)
+}
+
+// GetScale returns the scale subresource of the object `obj`, e.g. a Deployment, a StatefulSet or a custom
+// resource with the scale subresource enabled.
+func (r *Resources) GetScale(ctx context.Context, obj k8s.Object) (*autoscalingv1.Scale, error) {
+	scale := &autoscalingv1.Scale{}
+	if err := r.client.SubResource("scale").Get(ctx, obj, scale); err != nil {
+		return nil, err
+	}
+	return scale, nil
+}
+
+// UpdateScale updates the scale subresource of the object `obj` with `scale`
+func (r *Resources) UpdateScale(ctx context.Context, obj k8s.Object, scale *autoscalingv1.Scale, opts ...UpdateOption) error {
+	updateOptions := &metav1.UpdateOptions{}
+	for _, fn := range opts {
+		fn(updateOptions)
+	}
+
+	uo := cr.UpdateOptions{Raw: updateOptions}
+	o := &cr.SubResourceUpdateOptions{UpdateOptions: uo, SubResourceBody: scale}
+	return r.client.SubResource("scale").Update(ctx, obj, o)
+}
+
+// Scale sets the number of replicas of the object `obj` through its scale subresource, the same way
+// `kubectl scale` and the HorizontalPodAutoscaler do, and returns the updated scale.
+func (r *Resources) Scale(ctx context.Context, obj k8s.Object, replicas int32, opts ...PatchOption) (*autoscalingv1.Scale, error) {
+	patchOptions := &metav1.PatchOptions{}
+	for _, fn := range opts {
+		fn(patchOptions)
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"replicas": replicas}})
+	if err != nil {
+		return nil, err
+	}
+	scale := &autoscalingv1.Scale{}
+	po := cr.PatchOptions{Raw: patchOptions}
+	o := &cr.SubResourcePatchOptions{PatchOptions: po, SubResourceBody: scale}
+	if err := r.client.SubResource("scale").Patch(ctx, obj, cr.RawPatch(types.MergePatchType, data), o); err != nil {
+		return nil, err
+	}
+	return scale, nil
+}
+
+// RestartedAtAnnotation is the pod template annotation set by RolloutRestart, the same as `kubectl rollout restart`
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// RolloutRestart triggers a rollout of the Deployment, StatefulSet or DaemonSet `obj` by setting the
+// RestartedAtAnnotation annotation on its pod template, the same way `kubectl rollout restart` does.
+// The completion of the rollout can be waited for with the RolloutComplete condition of the
+// klient/wait/conditions package.
+func (r *Resources) RolloutRestart(ctx context.Context, obj k8s.Object, opts ...PatchOption) error {
+	switch obj.(type) {
+	case *appsv1.Deployment, *appsv1.StatefulSet, *appsv1.DaemonSet:
+	default:
+		return fmt.Errorf("rollout restart: unsupported type %T", obj)
+	}
+
+	return r.MergePatch(ctx, obj, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						RestartedAtAnnotation: time.Now().Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	}, opts...)
+}
+
+// AddLabels adds the labels `labels` to the object `obj` in the cluster, overwriting the values of the
+// existing keys, and updates `obj` with the result. Other labels of the object are left untouched.
+func (r *Resources) AddLabels(ctx context.Context, obj k8s.Object, labels map[string]string) error {
+	return r.patchMetadata(ctx, obj, "labels", stringMapPatch(labels))
+}
+
+// RemoveLabels removes the labels with the keys `keys` from the object `obj` in the cluster, and updates
+// `obj` with the result. Missing keys are ignored.
+func (r *Resources) RemoveLabels(ctx context.Context, obj k8s.Object, keys ...string) error {
+	return r.patchMetadata(ctx, obj, "labels", removeKeysPatch(keys))
+}
+
+// AddAnnotations adds the annotations `annotations` to the object `obj` in the cluster, overwriting the
+// values of the existing keys, and updates `obj` with the result. Other annotations of the object are left
+// untouched.
+func (r *Resources) AddAnnotations(ctx context.Context, obj k8s.Object, annotations map[string]string) error {
+	return r.patchMetadata(ctx, obj, "annotations", stringMapPatch(annotations))
+}
+
+// RemoveAnnotations removes the annotations with the keys `keys` from the object `obj` in the cluster, and
+// updates `obj` with the result. Missing keys are ignored.
+func (r *Resources) RemoveAnnotations(ctx context.Context, obj k8s.Object, keys ...string) error {
+	return r.patchMetadata(ctx, obj, "annotations", removeKeysPatch(keys))
+}
+
+// patchMetadata merge patches the metadata field `field` of the object. A merge patch only carries the
+// modified keys and no resource version, so it does not race with the other writers of the object; the
+// patch is still retried on conflicts, which the apiserver can return under heavy contention.
+func (r *Resources) patchMetadata(ctx context.Context, obj k8s.Object, field string, values map[string]interface{}) error {
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{field: values},
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return r.MergePatch(ctx, obj, patch)
+	})
+}
+
+func stringMapPatch(values map[string]string) map[string]interface{} {
+	patch := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		patch[k] = v
+	}
+	return patch
+}
+
+// removeKeysPatch returns a merge patch removing the keys, which is done by setting them to null
+func removeKeysPatch(keys []string) map[string]interface{} {
+	patch := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		patch[k] = nil
+	}
+	return patch
+}
+
 // Annotate attach annotations to an existing resource objec
 func (r *Resources) Annotate(obj k8s.Object, annotation map[string]string) {
 	obj.SetAnnotations(annotation)
@@ -265,6 +792,19 @@ func (r *Resources) Label(obj k8s.Object, label map[string]string) {
 	obj.SetLabels(label)
 }
 
+// RegisterTypes registers the types of API groups with the scheme of Resources, using the AddToScheme functions
+// generated for the API groups, so that their typed objects can be used with Resources, the wait conditions and
+// the decoder. The resources of the newly registered types are discovered lazily, so the types can be registered
+// after their CustomResourceDefinitions are installed.
+func (r *Resources) RegisterTypes(addToScheme ...func(*runtime.Scheme) error) error {
+	for _, fn := range addToScheme {
+		if err := fn(r.scheme); err != nil {
+			return fmt.Errorf("register types: %w", err)
+		}
+	}
+	return nil
+}
+
 func (r *Resources) GetScheme() *runtime.Scheme {
 	return r.scheme
 }
@@ -282,6 +822,9 @@ func (r *Resources) Watch(object k8s.ObjectList, opts ...ListOption) *watcher.Ev
 	}
 
 	o := &cr.ListOptions{Raw: listOptions}
+	if r.namespace != "" {
+		o.Namespace = r.namespace
+	}
 
 	return &watcher.EventHandlerFuncs{
 		ListOptions: o,
@@ -290,12 +833,28 @@ func (r *Resources) Watch(object k8s.ObjectList, opts ...ListOption) *watcher.Ev
 	}
 }
 
-func (r *Resources) ExecInPod(ctx context.Context, namespaceName, podName, containerName string, command []string, stdout, stderr *bytes.Buffer) error {
+// ExecInPod runs the command `command` in the container `containerName` of the pod `podName` and streams
+// its output to `stdout` and `stderr`. Either writer can be nil, in which case io.Discard is used, so that the
+// corresponding stream is still requested from the apiserver but its content is dropped.
+// A non-zero exit code of the command is returned as an error.
+func (r *Resources) ExecInPod(ctx context.Context, namespaceName, podName, containerName string, command []string, stdout, stderr io.Writer) error {
+	return r.execInPod(ctx, namespaceName, podName, containerName, command, nil, stdout, stderr)
+}
+
+func (r *Resources) execInPod(ctx context.Context, namespaceName, podName, containerName string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
 	clientset, err := kubernetes.NewForConfig(r.config)
 	if err != nil {
 		return err
 	}
 
+	// the exec subresource rejects requests without any stream to attach to
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	if stderr == nil {
+		stderr = io.Discard
+	}
+
 	req := clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Name(podName).
@@ -309,21 +868,23 @@ func (r *Resources) ExecInPod(ctx context.Context, namespaceName, podName, conta
 	req.VersionedParams(&v1.PodExecOptions{
 		Container: containerName,
 		Command:   command,
+		Stdin:     stdin != nil,
 		Stdout:    true,
 		Stderr:    true,
 	}, parameterCodec)
 
 	exec, err := remotecommand.NewSPDYExecutor(r.config, "POST", req.URL())
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("create executor for pod %s/%s: %w", namespaceName, podName, err)
 	}
 
 	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
 		Stdout: stdout,
 		Stderr: stderr,
 	})
 	if err != nil {
-		return err
+		return fmt.Errorf("exec in pod %s/%s: %w", namespaceName, podName, err)
 	}
 
 	return nil
@@ -20,21 +20,39 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/vladimirvivien/gexe"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
 	log "k8s.io/klog/v2"
 
 	"sigs.k8s.io/e2e-framework/klient/k8s"
 	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
 	"sigs.k8s.io/e2e-framework/klient/k8s/resources/testdata/projectExample"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
 )
 
 func TestCreate(t *testing.T) {
@@ -133,6 +151,44 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdateWithRetry(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "update-with-retry", Namespace: namespace.Name}}
+	if err := res.Create(context.TODO(), cm); err != nil {
+		t.Fatal("error while creating configmap", err)
+	}
+	stale := cm.DeepCopy()
+
+	// update the object behind the back of the stale copy, so that a plain update of it conflicts
+	if err := res.AddLabels(context.TODO(), cm, map[string]string{"concurrent": "writer"}); err != nil {
+		t.Fatal("error while updating configmap", err)
+	}
+	stale.Data = map[string]string{"key": "value"}
+	if err := res.Update(context.TODO(), stale); !apierrors.IsConflict(err) {
+		t.Fatalf("expected update of a stale object to conflict, got %v", err)
+	}
+
+	err = res.UpdateWithRetry(context.TODO(), stale, func(obj k8s.Object) error {
+		obj.(*corev1.ConfigMap).Data = map[string]string{"key": "value"}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("error while updating configmap with retry", err)
+	}
+
+	obj := &corev1.ConfigMap{}
+	if err := res.Get(context.TODO(), cm.Name, cm.Namespace, obj); err != nil {
+		t.Fatal("error while getting configmap", err)
+	}
+	if obj.Data["key"] != "value" || obj.Labels["concurrent"] != "writer" {
+		t.Errorf("expected both writes to be kept, got data %v and labels %v", obj.Data, obj.Labels)
+	}
+}
+
 func TestUpdateStatus(t *testing.T) {
 	res, err := resources.New(cfg)
 	if err != nil {
@@ -201,6 +257,91 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestDeleteAndWait(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:       "delete-and-wait",
+		Namespace:  namespace.Name,
+		Finalizers: []string{"e2e-framework.sigs.k8s.io/test"},
+	}}
+	if err := res.Create(context.TODO(), cm); err != nil {
+		t.Fatal("error while creating configmap", err)
+	}
+
+	// release the finalizer once the deletion has started
+	go func() {
+		_ = apimachinerywait.PollUntilContextTimeout(context.TODO(), time.Second, time.Minute, true, func(ctx context.Context) (bool, error) {
+			current := &corev1.ConfigMap{}
+			if err := res.Get(ctx, cm.Name, cm.Namespace, current); err != nil || current.DeletionTimestamp == nil {
+				return false, nil
+			}
+			current.Finalizers = nil
+			return res.Update(ctx, current) == nil, nil
+		})
+	}()
+
+	if err := res.DeleteAndWait(context.TODO(), cm, time.Minute); err != nil {
+		t.Fatal("error while deleting configmap", err)
+	}
+	if err := res.Get(context.TODO(), cm.Name, cm.Namespace, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected configmap to be deleted, got %v", err)
+	}
+	if err := res.DeleteAndWait(context.TODO(), cm, time.Minute); err != nil {
+		t.Error("expected deleting a missing object to succeed", err)
+	}
+}
+
+func TestDeleteWithOrphanDependents(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	orphaning := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "orphaning", Namespace: namespace.Name},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "orphaning"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "orphaning"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}}},
+			},
+		},
+	}
+	if err := res.Create(context.TODO(), orphaning); err != nil {
+		t.Fatal("error while creating deployment", err)
+	}
+
+	replicaSets := &appsv1.ReplicaSetList{}
+	err = wait.For(func(ctx context.Context) (bool, error) {
+		if err := res.List(ctx, replicaSets, resources.WithLabelSelector("app=orphaning")); err != nil {
+			return false, err
+		}
+		return len(replicaSets.Items) > 0, nil
+	}, wait.WithTimeout(time.Minute), wait.WithInterval(time.Second))
+	if err != nil {
+		t.Fatal("replicaset of the deployment not created", err)
+	}
+
+	if err := res.DeleteAndWait(context.TODO(), orphaning, time.Minute, resources.WithOrphanDependents(), resources.WithGracePeriod(0)); err != nil {
+		t.Fatal("error while deleting deployment", err)
+	}
+
+	rs := &appsv1.ReplicaSet{}
+	if err := res.Get(context.TODO(), replicaSets.Items[0].Name, namespace.Name, rs); err != nil {
+		t.Fatal("expected the replicaset to be orphaned", err)
+	}
+	if len(rs.OwnerReferences) != 0 {
+		t.Error("expected the owner reference of the orphaned replicaset to be removed")
+	}
+	if err := res.Delete(context.TODO(), rs, resources.WithBackgroundDeletion()); err != nil {
+		t.Error("error while deleting replicaset", err)
+	}
+}
+
 func TestList(t *testing.T) {
 	res, err := resources.New(cfg)
 	if err != nil {
@@ -263,6 +404,252 @@ func TestPatch(t *testing.T) {
 	}
 }
 
+func TestPatchHelpers(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	err = res.MergePatch(context.Background(), dep, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"merge": "patched"},
+		},
+	})
+	if err != nil {
+		t.Error("error while merge patching the deployment", err)
+	}
+
+	err = res.StrategicMergePatch(context.Background(), dep, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{"strategic": "patched"},
+		},
+	})
+	if err != nil {
+		t.Error("error while strategic merge patching the deployment", err)
+	}
+
+	err = res.JSONPatch(context.Background(), dep, []resources.JSONPatchOperation{
+		{Op: "replace", Path: "/spec/replicas", Value: 2},
+	})
+	if err != nil {
+		t.Error("error while json patching the deployment", err)
+	}
+
+	obj := &appsv1.Deployment{}
+	err = res.Get(context.Background(), dep.Name, dep.Namespace, obj)
+	if err != nil {
+		t.Error("error while getting patched deployment", err)
+	}
+
+	if obj.Labels["merge"] != "patched" {
+		t.Error("merge patch not applied correctly.")
+	}
+	if obj.Annotations["strategic"] != "patched" {
+		t.Error("strategic merge patch not applied correctly.")
+	}
+	if obj.Spec.Replicas == nil || *obj.Spec.Replicas != 2 {
+		t.Error("json patch not applied correctly.")
+	}
+}
+
+func TestJSONPatchOperation_MarshalJSON(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		op       resources.JSONPatchOperation
+		expected string
+	}{
+		{name: "replace", op: resources.JSONPatchOperation{Op: "replace", Path: "/spec/replicas", Value: 2}, expected: `{"op":"replace","path":"/spec/replicas","value":2}`},
+		{name: "null value", op: resources.JSONPatchOperation{Op: "add", Path: "/metadata/annotations"}, expected: `{"op":"add","path":"/metadata/annotations","value":null}`},
+		{name: "remove", op: resources.JSONPatchOperation{Op: "remove", Path: "/metadata/labels/app"}, expected: `{"op":"remove","path":"/metadata/labels/app"}`},
+		{name: "move", op: resources.JSONPatchOperation{Op: "move", From: "/metadata/labels/app", Path: "/metadata/labels/name"}, expected: `{"op":"move","path":"/metadata/labels/name","from":"/metadata/labels/app"}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.op)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, data)
+			}
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "dry-run", Namespace: namespace.Name}}
+	if err := res.Create(context.TODO(), cm, resources.WithDryRun()); err != nil {
+		t.Fatal("error while creating the configmap with dry run", err)
+	}
+	if err := res.Get(context.TODO(), cm.Name, cm.Namespace, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected configmap created with dry run to not exist, got %v", err)
+	}
+
+	err = res.Patch(context.TODO(), dep, k8s.Patch{PatchType: types.MergePatchType, Data: []byte(`{"metadata":{"labels":{"dry-run":"true"}}}`)}, resources.WithPatchDryRun())
+	if err != nil {
+		t.Fatal("error while patching the deployment with dry run", err)
+	}
+	if err := res.Delete(context.TODO(), dep, resources.WithDeleteDryRun()); err != nil {
+		t.Fatal("error while deleting the deployment with dry run", err)
+	}
+
+	obj := &appsv1.Deployment{}
+	if err := res.Get(context.TODO(), dep.Name, dep.Namespace, obj); err != nil {
+		t.Fatal("expected deployment deleted with dry run to still exist", err)
+	}
+	if _, ok := obj.Labels["dry-run"]; ok {
+		t.Error("expected deployment patch with dry run to not be persisted")
+	}
+}
+
+func TestScale(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	scale, err := res.Scale(context.TODO(), dep, 3)
+	if err != nil {
+		t.Fatal("error while scaling the deployment", err)
+	}
+	if scale.Spec.Replicas != 3 {
+		t.Errorf("expected scale to have 3 replicas, got %d", scale.Spec.Replicas)
+	}
+
+	scale, err = res.GetScale(context.TODO(), dep)
+	if err != nil {
+		t.Fatal("error while getting the scale of the deployment", err)
+	}
+	scale.Spec.Replicas = 1
+	if err := res.UpdateScale(context.TODO(), dep, scale); err != nil {
+		t.Fatal("error while updating the scale of the deployment", err)
+	}
+
+	obj := &appsv1.Deployment{}
+	if err := res.Get(context.TODO(), dep.Name, dep.Namespace, obj); err != nil {
+		t.Fatal("error while getting scaled deployment", err)
+	}
+	if obj.Spec.Replicas == nil || *obj.Spec.Replicas != 1 {
+		t.Error("deployment not scaled through the scale subresource")
+	}
+}
+
+func TestLabelAndAnnotationHelpers(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:        "labels-and-annotations",
+		Namespace:   namespace.Name,
+		Labels:      map[string]string{"keep": "true", "remove": "true"},
+		Annotations: map[string]string{"keep": "true", "remove": "true"},
+	}}
+	if err := res.Create(context.TODO(), cm); err != nil {
+		t.Fatal("error while creating configmap", err)
+	}
+
+	if err := res.AddLabels(context.TODO(), cm, map[string]string{"added": "true"}); err != nil {
+		t.Fatal("error while adding labels", err)
+	}
+	if err := res.RemoveLabels(context.TODO(), cm, "remove", "missing"); err != nil {
+		t.Fatal("error while removing labels", err)
+	}
+	if err := res.AddAnnotations(context.TODO(), cm, map[string]string{"added": "true"}); err != nil {
+		t.Fatal("error while adding annotations", err)
+	}
+	if err := res.RemoveAnnotations(context.TODO(), cm, "remove"); err != nil {
+		t.Fatal("error while removing annotations", err)
+	}
+
+	obj := &corev1.ConfigMap{}
+	if err := res.Get(context.TODO(), cm.Name, cm.Namespace, obj); err != nil {
+		t.Fatal("error while getting configmap", err)
+	}
+	expected := map[string]string{"keep": "true", "added": "true"}
+	if !reflect.DeepEqual(obj.Labels, expected) {
+		t.Errorf("expected labels %v, got %v", expected, obj.Labels)
+	}
+	if !reflect.DeepEqual(obj.Annotations, expected) {
+		t.Errorf("expected annotations %v, got %v", expected, obj.Annotations)
+	}
+}
+
+func TestApply(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "server-side-apply", Namespace: namespace.Name},
+		Data:       map[string]string{"owned": "by-test"},
+	}
+	if err := res.Apply(context.TODO(), cm, resources.WithFieldManager("e2e-test")); err != nil {
+		t.Fatal("error while applying configmap", err)
+	}
+
+	conflicting := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: cm.Name, Namespace: cm.Namespace},
+		Data:       map[string]string{"owned": "by-other"},
+	}
+	if err := res.Apply(context.TODO(), conflicting, resources.WithFieldManager("other")); !apierrors.IsConflict(err) {
+		t.Errorf("expected apply of a field owned by another manager to conflict, got %v", err)
+	}
+	if err := res.Apply(context.TODO(), conflicting, resources.WithFieldManager("other"), resources.WithForceOwnership()); err != nil {
+		t.Fatal("error while force applying configmap", err)
+	}
+	if conflicting.Data["owned"] != "by-other" {
+		t.Errorf("expected forced apply to take ownership of the field, got %q", conflicting.Data["owned"])
+	}
+}
+
+func TestApplyStatus(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	status := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: dep.Name, Namespace: dep.Namespace},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:               "ApplyStatusTest",
+				Status:             corev1.ConditionTrue,
+				LastUpdateTime:     metav1.NewTime(time.Now()),
+				LastTransitionTime: metav1.NewTime(time.Now()),
+			}},
+		},
+	}
+	if err := res.ApplyStatus(context.TODO(), status, resources.WithFieldManager("fake-controller"), resources.WithForceOwnership()); err != nil {
+		t.Fatal("error while applying the deployment status", err)
+	}
+
+	obj := &appsv1.Deployment{}
+	if err := res.Get(context.TODO(), dep.Name, dep.Namespace, obj); err != nil {
+		t.Fatal("error while getting deployment", err)
+	}
+	found := false
+	for _, cond := range obj.Status.Conditions {
+		found = found || (cond.Type == "ApplyStatusTest" && cond.Status == corev1.ConditionTrue)
+	}
+	if !found {
+		t.Error("deployment status not applied")
+	}
+	managed := false
+	for _, entry := range obj.ManagedFields {
+		managed = managed || (entry.Manager == "fake-controller" && entry.Subresource == "status")
+	}
+	if !managed {
+		t.Error("expected the status fields to be owned by the field manager")
+	}
+}
+
 func TestPatchStatus(t *testing.T) {
 	res, err := resources.New(cfg)
 	if err != nil {
@@ -330,6 +717,31 @@ func TestListAllPods(t *testing.T) {
 	t.Logf("pod list contains %d pods", len(pods.Items))
 }
 
+func TestListEach(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	pods := &corev1.PodList{}
+	if err := res.List(context.TODO(), pods, resources.WithFieldSelector("metadata.namespace=kube-system")); err != nil {
+		t.Fatal("error while listing pods", err)
+	}
+
+	count := 0
+	err = res.ListEach(context.TODO(), &corev1.PodList{}, func(obj k8s.Object) error {
+		count++
+		return nil
+	}, resources.WithFieldSelector("metadata.namespace=kube-system"), resources.WithLimit(1))
+	if err != nil {
+		t.Fatal("error while listing pods page by page", err)
+	}
+
+	if count != len(pods.Items) {
+		t.Errorf("expected %d pods to be listed page by page, got %d", len(pods.Items), count)
+	}
+}
+
 func TestGetCRDs(t *testing.T) {
 	res, err := resources.New(cfg)
 	if err != nil {
@@ -434,4 +846,506 @@ func TestExecInPod(t *testing.T) {
 	if !strings.Contains(stdout.String(), hostName) {
 		t.Fatal("Couldn't find proper env")
 	}
+
+	if err := res.ExecInPod(context.TODO(), namespace.Name, pod.Name, containerName, []string{"printenv"}, nil, nil); err != nil {
+		t.Errorf("expected the command to run with the output discarded: %v", err)
+	}
+}
+
+func TestPortForwardPod(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-port-forward-ns"}}
+	if err := res.Create(context.TODO(), namespace); err != nil {
+		t.Fatalf("Error while creating namespace resource: %v", err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-port-forward", Namespace: namespace.Name},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "nginx",
+			Image: "nginx",
+			Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+		}}},
+	}
+	if err := res.Create(context.TODO(), pod); err != nil {
+		t.Fatalf("Error while creating pod resource: %v", err)
+	}
+	if err := wait.For(conditions.New(res).PodReady(pod), wait.WithTimeout(5*time.Minute)); err != nil {
+		t.Fatalf("pod not ready: %v", err)
+	}
+
+	pf, err := res.PortForwardPod(context.TODO(), namespace.Name, pod.Name, 80)
+	if err != nil {
+		t.Fatalf("failed to forward port: %v", err)
+	}
+	defer pf.Close()
+
+	if pf.LocalPort() == 0 {
+		t.Fatal("expected a local port to be picked")
+	}
+	resp, err := http.Get("http://" + pf.Address())
+	if err != nil {
+		t.Fatalf("failed to reach pod through forwarded port: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+}
+
+func TestCopyToAndFromPod(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-copy-ns"}}
+	if err := res.Create(context.TODO(), namespace); err != nil {
+		t.Fatalf("Error while creating namespace resource: %v", err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-copy", Namespace: namespace.Name},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}}},
+	}
+	if err := res.Create(context.TODO(), pod); err != nil {
+		t.Fatalf("Error while creating pod resource: %v", err)
+	}
+	if err := wait.For(conditions.New(res).PodReady(pod), wait.WithTimeout(5*time.Minute)); err != nil {
+		t.Fatalf("pod not ready: %v", err)
+	}
+
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "nested", "fixture.txt"), []byte("e2e-framework"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := res.CopyToPod(context.TODO(), namespace.Name, pod.Name, "nginx", src, "/tmp/fixtures"); err != nil {
+		t.Fatalf("failed to copy to pod: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := res.ExecInPod(context.TODO(), namespace.Name, pod.Name, "nginx", []string{"cat", "/tmp/fixtures/nested/fixture.txt"}, &stdout, &stderr); err != nil {
+		t.Fatalf("failed to read copied file: %v: %s", err, stderr.String())
+	}
+	if stdout.String() != "e2e-framework" {
+		t.Errorf("unexpected copied file content %q", stdout.String())
+	}
+
+	dest := filepath.Join(t.TempDir(), "artifacts")
+	if err := res.CopyFromPod(context.TODO(), namespace.Name, pod.Name, "nginx", "/tmp/fixtures", dest); err != nil {
+		t.Fatalf("failed to copy from pod: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dest, "nested", "fixture.txt"))
+	if err != nil {
+		t.Fatalf("failed to read file copied from pod: %v", err)
+	}
+	if string(content) != "e2e-framework" {
+		t.Errorf("unexpected file content %q copied from pod", string(content))
+	}
+}
+
+func TestGetLogs(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-logs-ns"}}
+	if err := res.Create(context.TODO(), namespace); err != nil {
+		t.Fatalf("Error while creating namespace resource: %v", err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-logs", Namespace: namespace.Name},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:    "busybox",
+				Image:   "busybox",
+				Command: []string{"sh", "-c", "echo first; echo second"},
+			}},
+		},
+	}
+	if err := res.Create(context.TODO(), pod); err != nil {
+		t.Fatalf("Error while creating pod resource: %v", err)
+	}
+	if err := wait.For(conditions.New(res).PodPhaseMatch(pod, corev1.PodSucceeded), wait.WithTimeout(5*time.Minute)); err != nil {
+		t.Fatalf("pod not completed: %v", err)
+	}
+
+	logs, err := res.GetLogs(context.TODO(), namespace.Name, pod.Name, resources.WithLogContainer("busybox"))
+	if err != nil {
+		t.Fatalf("failed to get logs: %v", err)
+	}
+	if string(logs) != "first\nsecond\n" {
+		t.Errorf("unexpected logs %q", string(logs))
+	}
+
+	logs, err = res.GetLogs(context.TODO(), namespace.Name, pod.Name, resources.WithTailLines(1))
+	if err != nil {
+		t.Fatalf("failed to get tail of logs: %v", err)
+	}
+	if string(logs) != "second\n" {
+		t.Errorf("unexpected tail of logs %q", string(logs))
+	}
+}
+
+func TestProxy(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-proxy-ns"}}
+	if err := res.Create(context.TODO(), namespace); err != nil {
+		t.Fatalf("Error while creating namespace resource: %v", err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-proxy", Namespace: namespace.Name, Labels: map[string]string{"app": "test-proxy"}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "nginx",
+			Image: "nginx",
+			Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+		}}},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-proxy", Namespace: namespace.Name},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "test-proxy"},
+			Ports:    []corev1.ServicePort{{Name: "http", Port: 8080, TargetPort: intstr.FromInt32(80)}},
+		},
+	}
+	for _, obj := range []k8s.Object{pod, service} {
+		if err := res.Create(context.TODO(), obj); err != nil {
+			t.Fatalf("Error while creating resource: %v", err)
+		}
+	}
+	if err := wait.For(conditions.New(res).PodReady(pod), wait.WithTimeout(5*time.Minute)); err != nil {
+		t.Fatalf("pod not ready: %v", err)
+	}
+
+	resp, err := res.ProxyPod(context.TODO(), namespace.Name, pod.Name, "80", http.MethodGet, "/", nil)
+	if err != nil {
+		t.Fatalf("failed to proxy request to pod: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(resp.Body), "nginx") {
+		t.Errorf("unexpected response from pod proxy: %d %s", resp.StatusCode, resp.Body)
+	}
+
+	err = wait.For(func(ctx context.Context) (bool, error) {
+		resp, err = res.ProxyService(ctx, namespace.Name, service.Name, "http", http.MethodGet, "/missing", nil)
+		// the service is unavailable until its endpoints are populated
+		return err == nil && resp.StatusCode != http.StatusServiceUnavailable, nil
+	}, wait.WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("failed to proxy request to service: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d from service proxy, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-evict-ns"}}
+	if err := res.Create(context.TODO(), namespace); err != nil {
+		t.Fatalf("Error while creating namespace resource: %v", err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-evict", Namespace: namespace.Name, Labels: map[string]string{"app": "test-evict"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}}},
+	}
+	maxUnavailable := intstr.FromInt32(0)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-evict", Namespace: namespace.Name},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test-evict"}},
+		},
+	}
+	for _, obj := range []k8s.Object{pod, pdb} {
+		if err := res.Create(context.TODO(), obj); err != nil {
+			t.Fatalf("Error while creating resource: %v", err)
+		}
+	}
+	err = wait.For(conditions.And(
+		conditions.New(res).PodReady(pod),
+		conditions.New(res).PodDisruptionBudgetSatisfied(pdb),
+	), wait.WithTimeout(5*time.Minute))
+	if err != nil {
+		t.Fatalf("pod or pod disruption budget not ready: %v", err)
+	}
+
+	if err := res.Evict(context.TODO(), pod); !apierrors.IsTooManyRequests(err) {
+		t.Errorf("expected eviction to be refused by the pod disruption budget, got %v", err)
+	}
+
+	if err := res.Delete(context.TODO(), pdb); err != nil {
+		t.Fatalf("Error while deleting pod disruption budget: %v", err)
+	}
+	if err := res.Evict(context.TODO(), pod, resources.WithGracePeriod(0)); err != nil {
+		t.Fatalf("failed to evict pod: %v", err)
+	}
+	if err := wait.For(conditions.New(res).ResourceDeleted(pod), wait.WithTimeout(time.Minute)); err != nil {
+		t.Errorf("evicted pod not deleted: %v", err)
+	}
+}
+
+func TestWatchFor(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Minute)
+	defer cancel()
+	events, err := res.WatchFor(ctx, &corev1.ConfigMapList{}, resources.WithLabelSelector("test=watch-for"))
+	if err != nil {
+		t.Fatal("error while starting the watch", err)
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "watch-for", Namespace: namespace.Name, Labels: map[string]string{"test": "watch-for"}}}
+	if err := res.Create(ctx, cm); err != nil {
+		t.Fatal("error while creating configmap", err)
+	}
+	if err := res.AddAnnotations(ctx, cm, map[string]string{"step": "modified"}); err != nil {
+		t.Fatal("error while updating configmap", err)
+	}
+	if err := res.Delete(ctx, cm); err != nil {
+		t.Fatal("error while deleting configmap", err)
+	}
+
+	var observed []string
+	for event := range events {
+		if _, ok := event.Object.(*corev1.ConfigMap); !ok {
+			t.Fatalf("expected a typed configmap, got %T", event.Object)
+		}
+		observed = append(observed, string(event.Type))
+		if event.Type == watch.Deleted {
+			break
+		}
+	}
+	if got, expected := strings.Join(observed, ","), "ADDED,MODIFIED,DELETED"; got != expected {
+		t.Errorf("expected events %s, got %s", expected, got)
+	}
+}
+
+func TestWithCache(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	cached, err := res.WithCache(ctx, &corev1.ConfigMap{})
+	if err != nil {
+		t.Fatal("error while creating the cache", err)
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cached", Namespace: namespace.Name, Labels: map[string]string{"test": "cache"}}}
+	if err := cached.Create(ctx, cm); err != nil {
+		t.Fatal("error while creating configmap", err)
+	}
+	if err := cached.Live().Get(ctx, cm.Name, cm.Namespace, &corev1.ConfigMap{}); err != nil {
+		t.Fatal("expected live read of the configmap to succeed", err)
+	}
+
+	err = wait.For(func(ctx context.Context) (bool, error) {
+		configMaps := &corev1.ConfigMapList{}
+		if err := cached.List(ctx, configMaps, resources.WithLabelSelector("test=cache")); err != nil {
+			return false, err
+		}
+		return len(configMaps.Items) == 1, nil
+	}, wait.WithTimeout(time.Minute), wait.WithInterval(time.Second))
+	if err != nil {
+		t.Error("configmap not found in cache", err)
+	}
+
+	// reads of the types that are not cached are served by the apiserver
+	if err := cached.Get(ctx, dep.Name, dep.Namespace, &appsv1.Deployment{}); err != nil {
+		t.Error("error while getting an uncached object", err)
+	}
+}
+
+func TestTypedAccessors(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	deployment, err := resources.Get[appsv1.Deployment](context.TODO(), res, dep.Name, dep.Namespace)
+	if err != nil {
+		t.Fatal("error while getting the deployment", err)
+	}
+	if deployment.Name != dep.Name {
+		t.Errorf("expected deployment %s, got %s", dep.Name, deployment.Name)
+	}
+
+	deployments, err := resources.List[appsv1.Deployment](context.TODO(), res, resources.WithFieldSelector("metadata.namespace="+dep.Namespace))
+	if err != nil {
+		t.Fatal("error while listing deployments", err)
+	}
+	found := false
+	for _, d := range deployments {
+		found = found || d.Name == dep.Name
+	}
+	if !found {
+		t.Errorf("expected deployment %s to be listed", dep.Name)
+	}
+}
+
+func TestImpersonate(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	unprivileged, err := res.Impersonate(resources.ServiceAccountImpersonation(namespace.Name, "unprivileged"))
+	if err != nil {
+		t.Fatal("error while creating impersonating resources", err)
+	}
+	if err := unprivileged.List(context.TODO(), &corev1.SecretList{}); !apierrors.IsForbidden(err) {
+		t.Errorf("expected listing secrets as an unprivileged service account to be forbidden, got %v", err)
+	}
+
+	admin, err := res.Impersonate(rest.ImpersonationConfig{UserName: "admin", Groups: []string{"system:masters"}})
+	if err != nil {
+		t.Fatal("error while creating impersonating resources", err)
+	}
+	if err := admin.List(context.TODO(), &corev1.SecretList{}); err != nil {
+		t.Errorf("expected listing secrets as a cluster admin to succeed, got %v", err)
+	}
+}
+
+func TestCleanupTracked(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	tracked := res.WithTracking()
+	trackedNS := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-tracking-ns"}}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "tracked", Namespace: trackedNS.Name}}
+	for _, obj := range []k8s.Object{trackedNS, cm} {
+		if err := tracked.Create(context.TODO(), obj); err != nil {
+			t.Fatal("error while creating tracked object", err)
+		}
+	}
+	untracked := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "untracked", Namespace: namespace.Name}}
+	if err := res.Create(context.TODO(), untracked); err != nil {
+		t.Fatal("error while creating untracked object", err)
+	}
+
+	dryRun := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "dry-run", Namespace: trackedNS.Name}}
+	if err := tracked.Apply(context.TODO(), dryRun, resources.WithPatchDryRun()); err != nil {
+		t.Fatal("error while applying dry run object", err)
+	}
+	if _, ok := dryRun.Labels[resources.TrackingLabel]; ok {
+		t.Errorf("expected dry run object not to be labeled with %s, got %v", resources.TrackingLabel, dryRun.Labels)
+	}
+
+	if cm.Labels[resources.TrackingLabel] != tracked.TrackingID() {
+		t.Errorf("expected tracked object to be labeled with %s=%s, got %v", resources.TrackingLabel, tracked.TrackingID(), cm.Labels)
+	}
+	if len(tracked.Tracked()) != 2 {
+		t.Fatalf("expected 2 tracked objects, got %d", len(tracked.Tracked()))
+	}
+
+	if err := tracked.CleanupTracked(context.TODO()); err != nil {
+		t.Fatal("error while cleaning up tracked objects", err)
+	}
+	if err := wait.For(conditions.New(res).ResourceDeleted(cm), wait.WithTimeout(time.Minute)); err != nil {
+		t.Error("tracked configmap not deleted", err)
+	}
+	if err := res.Get(context.TODO(), untracked.Name, untracked.Namespace, &corev1.ConfigMap{}); err != nil {
+		t.Error("expected untracked configmap to be kept", err)
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	found, err := res.HasGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	if err != nil || !found {
+		t.Errorf("expected Deployment to be served, got %v, %v", found, err)
+	}
+	found, err = res.HasGroupVersionKind(schema.GroupVersionKind{Group: "missing.e2e-framework.sigs.k8s.io", Version: "v1", Kind: "Missing"})
+	if err != nil || found {
+		t.Errorf("expected missing kind to not be served, got %v, %v", found, err)
+	}
+
+	info, err := res.ServerVersion()
+	if err != nil {
+		t.Fatal("error while getting server version", err)
+	}
+	t.Logf("server version %s", info.GitVersion)
+	if atLeast, err := res.ServerVersionAtLeast("1.0"); err != nil || !atLeast {
+		t.Errorf("expected server version to be at least 1.0, got %v, %v", atLeast, err)
+	}
+	if atLeast, err := res.ServerVersionAtLeast("99.0"); err != nil || atLeast {
+		t.Errorf("expected server version to be lower than 99.0, got %v, %v", atLeast, err)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	gr := schema.GroupResource{Resource: "pods"}
+	for _, tc := range []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{name: "nil", err: nil},
+		{name: "not found", err: apierrors.NewNotFound(gr, "pod")},
+		{name: "conflict", err: apierrors.NewConflict(gr, "pod", errors.New("conflict"))},
+		{name: "too many requests", err: apierrors.NewTooManyRequests("throttled", 1), transient: true},
+		{name: "service unavailable", err: apierrors.NewServiceUnavailable("restarting"), transient: true},
+		{name: "server timeout", err: apierrors.NewServerTimeout(gr, "get", 1), transient: true},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, transient: true},
+		{name: "eof", err: fmt.Errorf("get pods: %w", io.EOF), transient: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if transient := resources.IsTransientError(tc.err); transient != tc.transient {
+				t.Errorf("expected transient to be %v, got %v", tc.transient, transient)
+			}
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	res, err := resources.New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	attempts := 0
+	retrying := res.WithRetry(
+		resources.WithRetryBackoff(apimachinerywait.Backoff{Duration: time.Millisecond, Steps: 3}),
+		resources.WithRetriable(func(err error) bool {
+			attempts++
+			return apierrors.IsNotFound(err)
+		}),
+	)
+	if err := retrying.Get(context.TODO(), "missing", namespace.Name, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the last error to be returned, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected the request to be attempted 3 times, got %d", attempts)
+	}
+
+	if err := retrying.Get(context.TODO(), dep.Name, dep.Namespace, &appsv1.Deployment{}); err != nil {
+		t.Error("expected a successful request to not be retried", err)
+	}
 }
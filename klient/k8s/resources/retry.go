@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"io"
+	"syscall"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultTransientRetry is the backoff used by WithRetry when none is provided. It retries for about 10 seconds,
+// which covers the restart of an apiserver.
+var DefaultTransientRetry = apimachinerywait.Backoff{
+	Duration: 200 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    6,
+	Cap:      5 * time.Second,
+}
+
+// RetryOptions are the options used to retry the requests failing with transient errors
+type RetryOptions struct {
+	// Backoff is the backoff used in between the attempts of a request
+	Backoff apimachinerywait.Backoff
+	// Retriable decides if a failed request is retried. Defaults to IsTransientError.
+	Retriable func(error) bool
+}
+
+// RetryOption is used to alter the RetryOptions used by WithRetry
+type RetryOption func(*RetryOptions)
+
+// WithRetryBackoff sets the backoff used in between the attempts of a request
+func WithRetryBackoff(backoff apimachinerywait.Backoff) RetryOption {
+	return func(o *RetryOptions) { o.Backoff = backoff }
+}
+
+// WithRetriable sets the function deciding if a failed request is retried
+func WithRetriable(retriable func(error) bool) RetryOption {
+	return func(o *RetryOptions) { o.Retriable = retriable }
+}
+
+// WithRetry returns a copy of Resources that retries the requests failing with transient errors, such as the
+// connection errors seen while the apiserver restarts and the throttling of the apiserver, with an exponential
+// backoff. This keeps upgrade and disruption tests from failing over a momentary unavailability of the apiserver.
+//
+// A create that succeeded but whose response was lost is retried as well, in which case the retry fails with
+// an AlreadyExists error.
+func (r *Resources) WithRetry(opts ...RetryOption) *Resources {
+	options := &RetryOptions{Backoff: DefaultTransientRetry, Retriable: IsTransientError}
+	for _, fn := range opts {
+		fn(options)
+	}
+
+	retrying := *r
+	retrying.client = &retryClient{Client: r.client, options: options}
+	return &retrying
+}
+
+// IsTransientError checks if the error is likely to be temporary, so that the request can be retried
+func IsTransientError(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case apierrors.IsTooManyRequests(err),
+		apierrors.IsServerTimeout(err),
+		apierrors.IsTimeout(err),
+		apierrors.IsServiceUnavailable(err):
+		return true
+	case errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		utilnet.IsConnectionReset(err),
+		utilnet.IsConnectionRefused(err),
+		utilnet.IsProbableEOF(err):
+		return true
+	case utilnet.IsTimeout(err):
+		return true
+	}
+	_, delayed := apierrors.SuggestsClientDelay(err)
+	return delayed
+}
+
+// retryClient is a controller-runtime client retrying the requests failing with transient errors
+type retryClient struct {
+	cr.Client
+	options *RetryOptions
+}
+
+func (c *retryClient) retry(ctx context.Context, fn func() error) error {
+	return retry.OnError(c.options.Backoff, func(err error) bool {
+		return ctx.Err() == nil && c.options.Retriable(err)
+	}, fn)
+}
+
+func (c *retryClient) Get(ctx context.Context, key cr.ObjectKey, obj cr.Object, opts ...cr.GetOption) error {
+	return c.retry(ctx, func() error { return c.Client.Get(ctx, key, obj, opts...) })
+}
+
+func (c *retryClient) List(ctx context.Context, list cr.ObjectList, opts ...cr.ListOption) error {
+	return c.retry(ctx, func() error { return c.Client.List(ctx, list, opts...) })
+}
+
+func (c *retryClient) Create(ctx context.Context, obj cr.Object, opts ...cr.CreateOption) error {
+	return c.retry(ctx, func() error { return c.Client.Create(ctx, obj, opts...) })
+}
+
+func (c *retryClient) Delete(ctx context.Context, obj cr.Object, opts ...cr.DeleteOption) error {
+	return c.retry(ctx, func() error { return c.Client.Delete(ctx, obj, opts...) })
+}
+
+func (c *retryClient) Update(ctx context.Context, obj cr.Object, opts ...cr.UpdateOption) error {
+	return c.retry(ctx, func() error { return c.Client.Update(ctx, obj, opts...) })
+}
+
+func (c *retryClient) Patch(ctx context.Context, obj cr.Object, patch cr.Patch, opts ...cr.PatchOption) error {
+	return c.retry(ctx, func() error { return c.Client.Patch(ctx, obj, patch, opts...) })
+}
+
+func (c *retryClient) DeleteAllOf(ctx context.Context, obj cr.Object, opts ...cr.DeleteAllOfOption) error {
+	return c.retry(ctx, func() error { return c.Client.DeleteAllOf(ctx, obj, opts...) })
+}
+
+func (c *retryClient) Status() cr.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *retryClient) SubResource(subResource string) cr.SubResourceClient {
+	return &retrySubResourceClient{SubResourceClient: c.Client.SubResource(subResource), client: c}
+}
+
+// retrySubResourceClient is a controller-runtime subresource client retrying the requests failing with
+// transient errors
+type retrySubResourceClient struct {
+	cr.SubResourceClient
+	client *retryClient
+}
+
+func (c *retrySubResourceClient) Get(ctx context.Context, obj cr.Object, subResource cr.Object, opts ...cr.SubResourceGetOption) error {
+	return c.client.retry(ctx, func() error { return c.SubResourceClient.Get(ctx, obj, subResource, opts...) })
+}
+
+func (c *retrySubResourceClient) Create(ctx context.Context, obj cr.Object, subResource cr.Object, opts ...cr.SubResourceCreateOption) error {
+	return c.client.retry(ctx, func() error { return c.SubResourceClient.Create(ctx, obj, subResource, opts...) })
+}
+
+func (c *retrySubResourceClient) Update(ctx context.Context, obj cr.Object, opts ...cr.SubResourceUpdateOption) error {
+	return c.client.retry(ctx, func() error { return c.SubResourceClient.Update(ctx, obj, opts...) })
+}
+
+func (c *retrySubResourceClient) Patch(ctx context.Context, obj cr.Object, patch cr.Patch, opts ...cr.SubResourcePatchOption) error {
+	return c.client.retry(ctx, func() error { return c.SubResourceClient.Patch(ctx, obj, patch, opts...) })
+}
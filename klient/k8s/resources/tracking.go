@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+// TrackingLabel is the label set on the objects created by a Resources with tracking enabled. Its value
+// identifies the tracking session, so that the objects created by a test can be found in the cluster.
+const TrackingLabel = "e2e-framework.sigs.k8s.io/tracking-id"
+
+// tracker records the objects created by a tracking session
+type tracker struct {
+	id string
+
+	mu      sync.Mutex
+	objects []k8s.Object
+}
+
+// WithTracking returns a copy of Resources that records every object it creates with Create or Apply, and labels
+// them with TrackingLabel. The recorded objects are deleted by CleanupTracked, which removes the need to write
+// the teardown of each object created by a test and prevents objects from leaking from one test to another:
+//
+//	r := client.Resources().WithTracking()
+//	defer r.CleanupTracked(ctx)
+func (r *Resources) WithTracking() *Resources {
+	tracked := *r
+	tracked.tracker = &tracker{id: rand.String(10)}
+	return &tracked
+}
+
+// TrackingID returns the value of the TrackingLabel label set on the objects created with tracking enabled,
+// or an empty string when tracking is not enabled.
+func (r *Resources) TrackingID() string {
+	if r.tracker == nil {
+		return ""
+	}
+	return r.tracker.id
+}
+
+// Tracked returns the objects created since tracking was enabled, in the order they were created in
+func (r *Resources) Tracked() []k8s.Object {
+	if r.tracker == nil {
+		return nil
+	}
+	r.tracker.mu.Lock()
+	defer r.tracker.mu.Unlock()
+	return append([]k8s.Object{}, r.tracker.objects...)
+}
+
+// CleanupTracked deletes the objects created since tracking was enabled, in the reverse order they were created
+// in, so that dependents are deleted before the objects they depend on. Objects that are already gone are ignored,
+// and the deletion carries on past the other errors, which are all returned. It is a no-op when tracking is not
+// enabled.
+func (r *Resources) CleanupTracked(ctx context.Context, opts ...DeleteOption) error {
+	if r.tracker == nil {
+		return nil
+	}
+	r.tracker.mu.Lock()
+	objects := r.tracker.objects
+	r.tracker.objects = nil
+	r.tracker.mu.Unlock()
+
+	var errs []error
+	for i := len(objects) - 1; i >= 0; i-- {
+		obj := objects[i]
+		if err := r.Delete(ctx, obj, opts...); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("delete %s/%s: %w", obj.GetNamespace(), obj.GetName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// labelTracked sets the tracking label on an object about to be created
+func (r *Resources) labelTracked(obj k8s.Object) {
+	if r.tracker == nil {
+		return
+	}
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[TrackingLabel] = r.tracker.id
+	obj.SetLabels(labels)
+}
+
+// track records a created object, once per object
+func (r *Resources) track(obj k8s.Object) {
+	if r.tracker == nil {
+		return
+	}
+	r.tracker.mu.Lock()
+	defer r.tracker.mu.Unlock()
+	for _, tracked := range r.tracker.objects {
+		if tracked.GetUID() == obj.GetUID() {
+			return
+		}
+	}
+	if tracked, ok := obj.DeepCopyObject().(k8s.Object); ok {
+		r.tracker.objects = append(r.tracker.objects, tracked)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+// ObjectPointer is satisfied by the pointer types of the Kubernetes API types, e.g. *v1.Pod for v1.Pod. It lets
+// the typed helpers create objects of the type T.
+type ObjectPointer[T any] interface {
+	*T
+	k8s.Object
+}
+
+// Get gets the object `name` of the type T, and returns it as its concrete type:
+//
+//	pod, err := resources.Get[v1.Pod](ctx, r, "name", "namespace")
+func Get[T any, PT ObjectPointer[T]](ctx context.Context, r *Resources, name, namespace string) (PT, error) {
+	obj := PT(new(T))
+	if err := r.Get(ctx, name, namespace, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// List lists the objects of the type T, and returns them as their concrete type:
+//
+//	pods, err := resources.List[v1.Pod](ctx, r, resources.WithLabelSelector("app=nginx"))
+//
+// The list type of T, e.g. v1.PodList, must be registered with the scheme of Resources.
+func List[T any, PT ObjectPointer[T]](ctx context.Context, r *Resources, opts ...ListOption) ([]PT, error) {
+	gvk, err := apiutil.GVKForObject(PT(new(T)), r.scheme)
+	if err != nil {
+		return nil, err
+	}
+	gvk.Kind += "List"
+	o, err := r.scheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := o.(k8s.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("resources: unexpected type %T does not satisfy k8s.ObjectList", o)
+	}
+	if err := r.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+
+	objs := make([]PT, 0, meta.LenList(list))
+	err = meta.EachListItem(list, func(item runtime.Object) error {
+		obj, ok := item.(PT)
+		if !ok {
+			return fmt.Errorf("resources: unexpected list item type %T", item)
+		}
+		objs = append(objs, obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
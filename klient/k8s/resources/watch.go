@@ -0,0 +1,224 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	klog "k8s.io/klog/v2"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+// watchRetryInterval is the time WatchFor waits before re-establishing a failed watch
+const watchRetryInterval = time.Second
+
+// Event is a change to an object observed by WatchFor
+type Event struct {
+	// Type is one of watch.Added, watch.Modified or watch.Deleted
+	Type watch.EventType
+	// Object is the state of the object after the change, or its last known state when it has been deleted
+	Object k8s.Object
+}
+
+// WatchFor watches the objects of the type of `objs` and returns a channel of the changes made to them, from
+// which intermediate states of the objects can be asserted on. The objects existing when the watch starts are
+// sent as watch.Added events first. When the watch expires, e.g. with a "too old resource version" error, the
+// objects are listed again and the changes missed in between are sent as synthesized events, so the channel
+// keeps reflecting the state of the cluster. The channel is closed once `ctx` is done.
+func (r *Resources) WatchFor(ctx context.Context, objs k8s.ObjectList, opts ...ListOption) (<-chan Event, error) {
+	client, err := cr.NewWithWatch(r.config, cr.Options{Scheme: r.scheme})
+	if err != nil {
+		return nil, err
+	}
+	lo, err := r.listOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &eventWatcher{client: client, list: objs, listOptions: lo, known: map[string]k8s.Object{}, events: make(chan Event)}
+	// the initial list is done synchronously to report errors such as unregistered types to the caller
+	initial, rv, err := w.relist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go w.run(ctx, initial, rv)
+	return w.events, nil
+}
+
+type eventWatcher struct {
+	client      cr.WithWatch
+	list        k8s.ObjectList
+	listOptions *cr.ListOptions
+	known       map[string]k8s.Object
+	events      chan Event
+}
+
+func (w *eventWatcher) run(ctx context.Context, pending []Event, rv string) {
+	defer close(w.events)
+	for {
+		for _, event := range pending {
+			if !w.send(ctx, event) {
+				return
+			}
+		}
+
+		rv = w.watch(ctx, rv)
+		if ctx.Err() != nil {
+			return
+		}
+
+		var err error
+		for {
+			if pending, rv, err = w.relist(ctx); err == nil {
+				break
+			}
+			klog.V(4).InfoS("Failed to list objects, retrying", "err", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(watchRetryInterval):
+			}
+		}
+	}
+}
+
+// watch sends the events received from a watch started at the resource version rv, until the watch fails or
+// the context is done. It returns the resource version to resume from, which is empty when a re-list is needed.
+func (w *eventWatcher) watch(ctx context.Context, rv string) string {
+	for {
+		listOptions := *w.listOptions
+		raw := *w.listOptions.Raw
+		raw.ResourceVersion = rv
+		raw.AllowWatchBookmarks = true
+		listOptions.Raw = &raw
+
+		watcher, err := w.client.Watch(ctx, w.list, &listOptions)
+		if err != nil {
+			klog.V(4).InfoS("Failed to start watch, retrying", "err", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(watchRetryInterval):
+			}
+			return ""
+		}
+		rv = w.consume(ctx, watcher, rv)
+		watcher.Stop()
+		if rv == "" || ctx.Err() != nil {
+			return ""
+		}
+	}
+}
+
+// consume sends the events of the watcher until it is closed. It returns the resource version to resume the watch
+// from, or an empty string when the watch failed and a re-list is needed.
+func (w *eventWatcher) consume(ctx context.Context, watcher watch.Interface, rv string) string {
+	for {
+		select {
+		case <-ctx.Done():
+			return ""
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return rv
+			}
+			switch event.Type {
+			case watch.Error:
+				err := apierrors.FromObject(event.Object)
+				klog.V(4).InfoS("Watch failed, listing objects again", "err", err, "expired", apierrors.IsResourceExpired(err) || apierrors.IsGone(err))
+				return ""
+			case watch.Bookmark:
+				if accessor, err := meta.Accessor(event.Object); err == nil {
+					rv = accessor.GetResourceVersion()
+				}
+			case watch.Added, watch.Modified, watch.Deleted:
+				obj, ok := event.Object.(k8s.Object)
+				if !ok {
+					continue
+				}
+				rv = obj.GetResourceVersion()
+				if event.Type == watch.Deleted {
+					delete(w.known, objectKey(obj))
+				} else {
+					w.known[objectKey(obj)] = obj
+				}
+				if !w.send(ctx, Event{Type: event.Type, Object: obj}) {
+					return ""
+				}
+			}
+		}
+	}
+}
+
+// relist lists the objects and returns the events that bring the known state up to date, along with the
+// resource version to start watching from
+func (w *eventWatcher) relist(ctx context.Context) ([]Event, string, error) {
+	list, ok := w.list.DeepCopyObject().(k8s.ObjectList)
+	if !ok {
+		return nil, "", fmt.Errorf("watch: unexpected list type %T", w.list)
+	}
+	if err := w.client.List(ctx, list, w.listOptions); err != nil {
+		return nil, "", err
+	}
+
+	var events []Event
+	current := map[string]k8s.Object{}
+	err := meta.EachListItem(list, func(item runtime.Object) error {
+		obj, ok := item.(k8s.Object)
+		if !ok {
+			return fmt.Errorf("watch: list item %T is not a k8s.Object", item)
+		}
+		current[objectKey(obj)] = obj
+		previous, found := w.known[objectKey(obj)]
+		switch {
+		case !found:
+			events = append(events, Event{Type: watch.Added, Object: obj})
+		case previous.GetResourceVersion() != obj.GetResourceVersion():
+			events = append(events, Event{Type: watch.Modified, Object: obj})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	for k, obj := range w.known {
+		if _, found := current[k]; !found {
+			events = append(events, Event{Type: watch.Deleted, Object: obj})
+		}
+	}
+	w.known = current
+	return events, list.GetResourceVersion(), nil
+}
+
+func (w *eventWatcher) send(ctx context.Context, event Event) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case w.events <- event:
+		return true
+	}
+}
+
+func objectKey(obj k8s.Object) string {
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
@@ -76,7 +76,11 @@ func (e *EventHandlerFuncs) Start(ctx context.Context) error {
 				if ctx.Err() != nil {
 					return
 				}
-			case event := <-e.watcher.ResultChan():
+			case event, ok := <-e.watcher.ResultChan():
+				// the result channel is closed once the watch is stopped or terminated by the server
+				if !ok {
+					return
+				}
 				// retrieve the event type
 				eventType := event.Type
 
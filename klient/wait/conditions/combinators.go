@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/e2e-framework/klient/wait"
+)
+
+// And is a helper function that can be used to combine a series of conditions into one that is met only when all
+// of them are met. The conditions are checked in order and the check stops at the first one that is not met, which
+// is reported as the reason for the combined condition not being met if the wait times out.
+func And(conditions ...apimachinerywait.ConditionWithContextFunc) apimachinerywait.ConditionWithContextFunc {
+	return func(ctx context.Context) (done bool, err error) {
+		for i, condition := range conditions {
+			done, reason, err := check(ctx, condition)
+			if err != nil {
+				return false, fmt.Errorf("condition %d of %d: %w", i+1, len(conditions), err)
+			}
+			if !done {
+				wait.ReportUnmet(ctx, "condition %d of %d not met%s", i+1, len(conditions), reason)
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// Or is a helper function that can be used to combine a series of conditions into one that is met as soon as any
+// of them is met. If the wait times out, the reason for each of the conditions not being met is reported.
+func Or(conditions ...apimachinerywait.ConditionWithContextFunc) apimachinerywait.ConditionWithContextFunc {
+	return func(ctx context.Context) (done bool, err error) {
+		unmet := make([]string, 0, len(conditions))
+		for i, condition := range conditions {
+			done, reason, err := check(ctx, condition)
+			if err != nil {
+				return false, fmt.Errorf("condition %d of %d: %w", i+1, len(conditions), err)
+			}
+			if done {
+				return true, nil
+			}
+			unmet = append(unmet, fmt.Sprintf("condition %d of %d not met%s", i+1, len(conditions), reason))
+		}
+		wait.ReportUnmet(ctx, "none of the conditions met: [%s]", strings.Join(unmet, "; "))
+		return false, nil
+	}
+}
+
+// Not is a helper function that can be used to invert a condition. The resulting condition is met when the
+// provided condition is not, which can be used to wait for a resource to leave a given state. Errors returned
+// by the provided condition are returned as is.
+func Not(condition apimachinerywait.ConditionWithContextFunc) apimachinerywait.ConditionWithContextFunc {
+	return func(ctx context.Context) (done bool, err error) {
+		done, _, err = check(ctx, condition)
+		if err != nil {
+			return false, err
+		}
+		if done {
+			wait.ReportUnmet(ctx, "negated condition is still met")
+		}
+		return !done, nil
+	}
+}
+
+// check invokes the condition with a dedicated reporter and returns the reason it reported for not being met
+// formatted so that it can be appended to the message of the combined condition.
+func check(ctx context.Context, condition apimachinerywait.ConditionWithContextFunc) (bool, string, error) {
+	ctx = wait.WithUnmetReporter(ctx)
+	done, err := condition(ctx)
+	if reason := wait.UnmetReason(ctx); reason != "" {
+		return done, ": " + reason, err
+	}
+	return done, "", err
+}
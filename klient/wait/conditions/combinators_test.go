@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/e2e-framework/klient/wait"
+)
+
+func met(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func unmet(reason string) apimachinerywait.ConditionWithContextFunc {
+	return func(ctx context.Context) (bool, error) {
+		wait.ReportUnmet(ctx, "%s", reason)
+		return false, nil
+	}
+}
+
+func failing(ctx context.Context) (bool, error) {
+	return false, errors.New("boom")
+}
+
+func TestCombinators(t *testing.T) {
+	tests := []struct {
+		name          string
+		condition     apimachinerywait.ConditionWithContextFunc
+		expectDone    bool
+		expectErr     bool
+		expectMessage string
+	}{
+		{name: "and all met", condition: And(met, met), expectDone: true},
+		{name: "and one unmet", condition: And(met, unmet("pod pending")), expectMessage: "condition 2 of 2 not met: pod pending"},
+		{name: "and error", condition: And(met, failing), expectErr: true},
+		{name: "or one met", condition: Or(unmet("a"), met), expectDone: true},
+		{name: "or none met", condition: Or(unmet("a"), unmet("b")), expectMessage: "none of the conditions met: [condition 1 of 2 not met: a; condition 2 of 2 not met: b]"},
+		{name: "not met", condition: Not(unmet("a")), expectDone: true},
+		{name: "not unmet", condition: Not(met), expectMessage: "negated condition is still met"},
+		{name: "nested", condition: And(met, Or(unmet("a"), Not(met))), expectMessage: "condition 2 of 2 not met: none of the conditions met: [condition 1 of 2 not met: a; condition 2 of 2 not met: negated condition is still met]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := wait.WithUnmetReporter(context.Background())
+			done, err := test.condition(ctx)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if done != test.expectDone {
+				t.Errorf("expected done to be %v, got %v", test.expectDone, done)
+			}
+			if reason := wait.UnmetReason(ctx); reason != test.expectMessage {
+				t.Errorf("expected reason %q, got %q", test.expectMessage, reason)
+			}
+		})
+	}
+}
+
+func TestCombinatorTimeoutReason(t *testing.T) {
+	err := wait.For(And(met, unmet("replicas 1/3")), wait.WithTimeout(500*time.Millisecond), wait.WithInterval(100*time.Millisecond))
+	if err == nil {
+		t.Fatal("expected the wait to time out")
+	}
+	if !strings.Contains(err.Error(), "condition 2 of 2 not met: replicas 1/3") {
+		t.Errorf("expected the error to report the unmet condition, got %q", err)
+	}
+	if !apimachinerywait.Interrupted(err) {
+		t.Errorf("expected the error to still be identified as a timeout, got %q", err)
+	}
+}
+
+func TestTerminalErrorThroughCombinators(t *testing.T) {
+	terminal := func(ctx context.Context) (bool, error) {
+		return false, NewTerminalError("job failed")
+	}
+	err := wait.For(And(met, terminal), wait.WithTimeout(5*time.Second), wait.WithImmediate())
+	if !IsTerminalError(err) {
+		t.Errorf("expected a terminal error, got %v", err)
+	}
+	if IsTerminalError(errors.New("boom")) {
+		t.Error("expected a regular error not to be identified as terminal")
+	}
+}
@@ -18,58 +18,195 @@ package conditions
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync/atomic"
 
-	log "k8s.io/klog/v2"
+	"github.com/go-logr/logr"
+	klog "k8s.io/klog/v2"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 
 	"sigs.k8s.io/e2e-framework/klient/k8s"
 	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
 )
 
 type Condition struct {
 	resources *resources.Resources
+	logger    logr.Logger
 }
 
 // New is used to create a new Condition that can be used to perform a series of pre-defined wait checks
 // against a resource in question
 func New(r *resources.Resources) *Condition {
-	return &Condition{resources: r}
+	return &Condition{resources: r, logger: klog.NewKlogr()}
+}
+
+// WithLogger configures the logger used to report the progress of the wait checks. By default, the checks are
+// logged using klog. This can be used to integrate the output of the checks with the logging setup of the test
+// suite, such as a testr.Logger writing to the *testing.T of the test.
+func (c *Condition) WithLogger(logger logr.Logger) *Condition {
+	c.logger = logger
+	return c
 }
 
 func (c *Condition) namespacedName(obj k8s.Object) string {
-	return fmt.Sprintf("%s [%s/%s]", obj.GetObjectKind().GroupVersionKind().String(), obj.GetNamespace(), obj.GetName())
+	return fmt.Sprintf("%s [%s/%s]", c.gvk(obj).String(), obj.GetNamespace(), obj.GetName())
+}
+
+// gvk returns the GroupVersionKind of the object, using the scheme to resolve it for typed objects where the
+// type information is often left empty
+func (c *Condition) gvk(obj runtime.Object) schema.GroupVersionKind {
+	if gvk := obj.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+		return gvk
+	}
+	gvk, err := apiutil.GVKForObject(obj, c.resources.GetScheme())
+	if err != nil {
+		return schema.GroupVersionKind{}
+	}
+	return gvk
+}
+
+// checkLogger returns a function that provides the logger to use for each check of a condition for the object or
+// list of objects. The logger carries the kind and name of the object along with the number of the check performed.
+func (c *Condition) checkLogger(obj runtime.Object) func() logr.Logger {
+	resource := c.gvk(obj).String()
+	if o, ok := obj.(k8s.Object); ok {
+		resource = c.namespacedName(o)
+	}
+	// the condition can be polled concurrently, e.g. by the parallel features of a test reusing it
+	var polls atomic.Int64
+	return func() logr.Logger {
+		return c.logger.WithValues("resource", resource, "poll", polls.Add(1))
+	}
+}
+
+// maxObservedStatusLength limits the size of the observed status reported when a condition is not met
+const maxObservedStatusLength = 2048
+
+// reportObserved wraps the condition so that the last observed status of the object is reported along with the
+// reason for the condition not being met, if any, and surfaced in the error returned by wait.For on timeout.
+func (c *Condition) reportObserved(obj k8s.Object, condition apimachinerywait.ConditionWithContextFunc) apimachinerywait.ConditionWithContextFunc {
+	return func(ctx context.Context) (done bool, err error) {
+		done, err = condition(ctx)
+		if done || err != nil {
+			return done, err
+		}
+		status, ok := observedStatus(obj)
+		if !ok {
+			return done, err
+		}
+		if reason := wait.UnmetReason(ctx); reason != "" {
+			wait.ReportUnmet(ctx, "%s; last observed status of %s: %s", reason, c.namespacedName(obj), status)
+		} else {
+			wait.ReportUnmet(ctx, "last observed status of %s: %s", c.namespacedName(obj), status)
+		}
+		return done, err
+	}
+}
+
+// observedStatus returns a compact JSON representation of the status of the object. Objects without a status such
+// as ConfigMaps and Secrets are not reported to avoid leaking their content, except for the webhook configurations
+// whose webhooks are reported instead.
+func observedStatus(obj k8s.Object) (string, bool) {
+	var status any
+	if webhooks, ok := observedWebhooks(obj); ok {
+		status = webhooks
+	} else {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return "", false
+		}
+		if status, ok = content["status"]; !ok {
+			return "", false
+		}
+	}
+	b, err := json.Marshal(status)
+	if err != nil {
+		return "", false
+	}
+	if len(b) > maxObservedStatusLength {
+		return string(b[:maxObservedStatusLength]) + "...", true
+	}
+	return string(b), true
+}
+
+// observedWebhook is the state of a webhook reported for the webhook configurations, which have no status
+type observedWebhook struct {
+	Name     string                                    `json:"name"`
+	Service  *admissionregistrationv1.ServiceReference `json:"service,omitempty"`
+	URL      *string                                   `json:"url,omitempty"`
+	CABundle bool                                      `json:"caBundle"`
+}
+
+// observedWebhooks returns the state of the webhooks of a validating or mutating webhook configuration, without
+// their CA bundle
+func observedWebhooks(obj k8s.Object) ([]observedWebhook, bool) {
+	var webhooks []observedWebhook
+	observe := func(name string, clientConfig admissionregistrationv1.WebhookClientConfig) {
+		webhooks = append(webhooks, observedWebhook{Name: name, Service: clientConfig.Service, URL: clientConfig.URL, CABundle: len(clientConfig.CABundle) > 0})
+	}
+	switch wh := obj.(type) {
+	case *admissionregistrationv1.ValidatingWebhookConfiguration:
+		for _, webhook := range wh.Webhooks {
+			observe(webhook.Name, webhook.ClientConfig)
+		}
+	case *admissionregistrationv1.MutatingWebhookConfiguration:
+		for _, webhook := range wh.Webhooks {
+			observe(webhook.Name, webhook.ClientConfig)
+		}
+	default:
+		return nil, false
+	}
+	return webhooks, true
 }
 
 // ResourceScaled is a helper function used to check if the resource under question has a pre-defined number of
 // replicas. This can be leveraged for checking cases such as scaling up and down a deployment or STS and any
 // other scalable resources.
 func (c *Condition) ResourceScaled(obj k8s.Object, scaleFetcher func(object k8s.Object) int32, replica int32) apimachinerywait.ConditionWithContextFunc {
-	return func(ctx context.Context) (done bool, err error) {
-		log.V(4).InfoS("Checking for resource to be scaled", "resource", c.namespacedName(obj), "replica", replica)
+	logger := c.checkLogger(obj)
+	return c.reportObserved(obj, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for resource to be scaled", "replica", replica)
 		if err := c.resources.Get(ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
+			log.V(4).Info("Failed to get resource", "error", err)
 			return false, nil
 		}
-		return scaleFetcher(obj) == replica, nil
-	}
+		current := scaleFetcher(obj)
+		log.V(4).Info("Current replicas of the resource", "current", current)
+		return current == replica, nil
+	})
 }
 
 // ResourceMatch is a helper function used to check if the resource under question has met a pre-defined state. This can
 // be leveraged for checking fields on a resource that may not be immediately present upon creation.
 func (c *Condition) ResourceMatch(obj k8s.Object, matchFetcher func(object k8s.Object) bool) apimachinerywait.ConditionWithContextFunc {
-	return func(ctx context.Context) (done bool, err error) {
+	logger := c.checkLogger(obj)
+	return c.reportObserved(obj, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for resource to match")
 		if err := c.resources.Get(ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
+			log.V(4).Info("Failed to get resource", "error", err)
 			return false, nil
 		}
 		return matchFetcher(obj), nil
-	}
+	})
 }
 
 // ResourceListN is a helper function that can be used to check for a minimum number of returned objects in a list. This function
@@ -81,8 +218,12 @@ func (c *Condition) ResourceListN(list k8s.ObjectList, n int, listOptions ...res
 // ResourceListMatchN is a helper function that can be used to check for a minimum number of returned objects in a list. This function
 // accepts list options and a match function that can be used to adjust the set of objects queried for in the List resource operation.
 func (c *Condition) ResourceListMatchN(list k8s.ObjectList, n int, matchFetcher func(object k8s.Object) bool, listOptions ...resources.ListOption) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(list)
 	return func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for resources in list", "count", n)
 		if err = c.resources.List(ctx, list, listOptions...); err != nil {
+			log.V(4).Info("Failed to list resources", "error", err)
 			return false, nil
 		}
 		var found int
@@ -97,6 +238,7 @@ func (c *Condition) ResourceListMatchN(list k8s.ObjectList, n int, matchFetcher
 				return false, fmt.Errorf("condition: unexpected type %T in list, does not satisfy k8s.Object", obj)
 			}
 		}
+		log.V(4).Info("Current resources found in list", "found", found)
 		return found >= n, nil
 	}
 }
@@ -126,7 +268,10 @@ func (c *Condition) ResourcesMatch(list k8s.ObjectList, matchFetcher func(object
 			objects[obj] = false
 		}
 	}
+	logger := c.checkLogger(list)
 	return func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for resources to be found", "count", len(objects))
 		found := 0
 		for obj, created := range objects {
 			if !created {
@@ -148,7 +293,23 @@ func (c *Condition) ResourcesMatch(list k8s.ObjectList, matchFetcher func(object
 
 // ResourcesDeleted is a helper function that can be used to check for if a set of objects has been deleted. This function
 // accepts a list of named objects and will wait until it is not able to find each.
-func (c *Condition) ResourcesDeleted(list k8s.ObjectList) apimachinerywait.ConditionWithContextFunc {
+//
+// If listOptions are provided, the objects are instead looked up by performing a List operation with those options on
+// each check and the condition is met once no object matches anymore. This can be used to verify the cleanup of
+// resources selected by labels or fields, such as child resources managed by an operator.
+func (c *Condition) ResourcesDeleted(list k8s.ObjectList, listOptions ...resources.ListOption) apimachinerywait.ConditionWithContextFunc {
+	if len(listOptions) > 0 {
+		logger := c.checkLogger(list)
+		return func(ctx context.Context) (done bool, err error) {
+			log := logger()
+			if err := c.resources.List(ctx, list, listOptions...); err != nil {
+				return false, nil
+			}
+			remaining := meta.LenList(list)
+			log.V(4).Info("Checking for listed resources to be garbage collected", "remaining", remaining)
+			return remaining == 0, nil
+		}
+	}
 	metaList, err := meta.ExtractList(list)
 	if err != nil {
 		return func(ctx context.Context) (done bool, err error) { return false, err }
@@ -165,10 +326,12 @@ func (c *Condition) ResourcesDeleted(list k8s.ObjectList) apimachinerywait.Condi
 			objects[obj] = true
 		}
 	}
+	logger := c.checkLogger(list)
 	return func(ctx context.Context) (done bool, err error) {
+		log := logger()
 		for obj, created := range objects {
 			if created {
-				log.V(4).InfoS("Checking for resource to be garbage collected", "resource", c.namespacedName(obj))
+				log.V(4).Info("Checking for resource to be garbage collected", "object", c.namespacedName(obj))
 				if err := c.resources.Get(ctx, obj.GetName(), obj.GetNamespace(), obj); errors.IsNotFound(err) {
 					delete(objects, obj)
 				} else if err != nil {
@@ -187,9 +350,11 @@ func (c *Condition) ResourcesDeleted(list k8s.ObjectList) apimachinerywait.Condi
 // This method can be leveraged against any Kubernetes resource to check the deletion workflow and it does so by
 // checking the resource and waiting until it obtains a v1.StatusReasonNotFound error from the API
 func (c *Condition) ResourceDeleted(obj k8s.Object) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(obj)
 	return func(ctx context.Context) (done bool, err error) {
-		log.V(4).InfoS("Checking for resource to be garbage collected", "resource", c.namespacedName(obj))
-		if err := c.resources.Get(context.Background(), obj.GetName(), obj.GetNamespace(), obj); err != nil {
+		log := logger()
+		log.V(4).Info("Checking for resource to be garbage collected")
+		if err := c.resources.Get(ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
 			if errors.IsNotFound(err) {
 				return true, nil
 			}
@@ -203,68 +368,206 @@ func (c *Condition) ResourceDeleted(obj k8s.Object) apimachinerywait.ConditionWi
 // specific condition. This function accepts both conditionType and conditionState as argument and hence you can use this
 // to match both positive or negative cases with suitable values passed to the arguments.
 func (c *Condition) JobConditionMatch(job k8s.Object, conditionType batchv1.JobConditionType, conditionState v1.ConditionStatus) apimachinerywait.ConditionWithContextFunc {
-	return func(ctx context.Context) (done bool, err error) {
-		log.V(4).InfoS("Checking for condition match", "resource", c.namespacedName(job), "state", conditionState, "conditionType", conditionType)
+	logger := c.checkLogger(job)
+	return c.reportObserved(job, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for condition match", "state", conditionState, "conditionType", conditionType)
 		if err := c.resources.Get(ctx, job.GetName(), job.GetNamespace(), job); err != nil {
 			return false, err
 		}
 		status := job.(*batchv1.Job).Status
-		log.V(4).InfoS("Current Status of the job resource", "status", status)
+		log.V(4).Info("Current Status of the job resource", "status", status)
 		for _, cond := range status.Conditions {
 			if cond.Type == conditionType && cond.Status == conditionState {
 				done = true
 			}
 		}
+		if !done && conditionState == v1.ConditionTrue {
+			if cond, finished := finishedJobCondition(status); finished && cond.Type != conditionType {
+				return false, NewTerminalError("job finished with condition %s: %s", cond.Type, cond.Message)
+			}
+		}
 		return
+	})
+}
+
+// finishedJobCondition returns the condition that marks the Job as finished, if any. Once a Job has either completed
+// or failed, it will no longer transition to the other state.
+func finishedJobCondition(status batchv1.JobStatus) (batchv1.JobCondition, bool) {
+	for _, cond := range status.Conditions {
+		if (cond.Type == batchv1.JobComplete || cond.Type == batchv1.JobFailed) && cond.Status == v1.ConditionTrue {
+			return cond, true
+		}
 	}
+	return batchv1.JobCondition{}, false
+}
+
+// isPodFinished checks if the Pod has reached a terminal phase, where it will no longer be running any container
+func isPodFinished(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+}
+
+// CronJobTriggered is a helper function used to check if the CronJob has spawned at least one Job. The Jobs are
+// identified using their owner references. If job is not nil, the most recently created Job spawned by the CronJob
+// is stored into it once the condition is met so that it can be used for follow-up assertions.
+func (c *Condition) CronJobTriggered(cronJob k8s.Object, job *batchv1.Job) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(cronJob)
+	return c.reportObserved(cronJob, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for cronjob to spawn a job")
+		if err := c.resources.Get(ctx, cronJob.GetName(), cronJob.GetNamespace(), cronJob); err != nil {
+			return false, err
+		}
+		var jobs batchv1.JobList
+		if err := c.resources.GetControllerRuntimeClient().List(ctx, &jobs, cr.InNamespace(cronJob.GetNamespace())); err != nil {
+			return false, err
+		}
+		var latest *batchv1.Job
+		for i := range jobs.Items {
+			if !metav1.IsControlledBy(&jobs.Items[i], cronJob) {
+				continue
+			}
+			if latest == nil || latest.CreationTimestamp.Before(&jobs.Items[i].CreationTimestamp) {
+				latest = &jobs.Items[i]
+			}
+		}
+		if latest == nil {
+			wait.ReportUnmet(ctx, "no job spawned by the cronjob yet")
+			return false, nil
+		}
+		if job != nil {
+			latest.DeepCopyInto(job)
+		}
+		return true, nil
+	})
+}
+
+// CronJobScheduled is a helper function used to check if the CronJob has been scheduled again since the condition
+// was first checked, by waiting for the status.lastScheduleTime of the CronJob to advance.
+func (c *Condition) CronJobScheduled(cronJob k8s.Object) apimachinerywait.ConditionWithContextFunc {
+	var initial *metav1.Time
+	var checked bool
+	logger := c.checkLogger(cronJob)
+	return c.reportObserved(cronJob, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for cronjob to be scheduled")
+		if err := c.resources.Get(ctx, cronJob.GetName(), cronJob.GetNamespace(), cronJob); err != nil {
+			return false, err
+		}
+		lastScheduleTime := cronJob.(*batchv1.CronJob).Status.LastScheduleTime
+		if !checked {
+			initial, checked = lastScheduleTime, true
+			return false, nil
+		}
+		if lastScheduleTime == nil || (initial != nil && !initial.Before(lastScheduleTime)) {
+			wait.ReportUnmet(ctx, "cronjob last schedule time has not advanced past %v", initial)
+			return false, nil
+		}
+		return true, nil
+	})
 }
 
 // DeploymentConditionMatch is a helper function that can be used to check a specific condition match for the Deployment in question.
 func (c *Condition) DeploymentConditionMatch(deployment k8s.Object, conditionType appsv1.DeploymentConditionType, conditionState v1.ConditionStatus) apimachinerywait.ConditionWithContextFunc {
-	return func(ctx context.Context) (done bool, err error) {
+	logger := c.checkLogger(deployment)
+	return c.reportObserved(deployment, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for condition match", "state", conditionState, "conditionType", conditionType)
 		if err := c.resources.Get(ctx, deployment.GetName(), deployment.GetNamespace(), deployment); err != nil {
 			return false, err
 		}
+		log.V(4).Info("Current Status of the deployment resource", "status", deployment.(*appsv1.Deployment).Status)
 		for _, cond := range deployment.(*appsv1.Deployment).Status.Conditions {
 			if cond.Type == conditionType && cond.Status == conditionState {
 				done = true
 			}
 		}
 		return
-	}
+	})
 }
 
 // PodConditionMatch is a helper function that can be used to check a specific condition match for the Pod in question.
 // This is extended into a few simplified match helpers such as PodReady and ContainersReady as well.
 func (c *Condition) PodConditionMatch(pod k8s.Object, conditionType v1.PodConditionType, conditionState v1.ConditionStatus) apimachinerywait.ConditionWithContextFunc {
-	return func(ctx context.Context) (done bool, err error) {
-		log.V(4).InfoS("Checking for condition match", "resource", c.namespacedName(pod), "state", conditionState, "conditionType", conditionType)
+	logger := c.checkLogger(pod)
+	return c.reportObserved(pod, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for condition match", "state", conditionState, "conditionType", conditionType)
 		if err := c.resources.Get(ctx, pod.GetName(), pod.GetNamespace(), pod); err != nil {
 			return false, err
 		}
 		status := pod.(*v1.Pod).Status
-		log.V(4).InfoS("Current Status of the pod resource", "status", status)
+		log.V(4).Info("Current Status of the pod resource", "status", status)
 		for _, cond := range status.Conditions {
 			if cond.Type == conditionType && cond.Status == conditionState {
 				done = true
 			}
 		}
+		if !done && conditionState == v1.ConditionTrue && isPodFinished(pod.(*v1.Pod)) {
+			return false, NewTerminalError("pod finished with phase %s", status.Phase)
+		}
 		return
-	}
+	})
 }
 
 // PodPhaseMatch is a helper function that is used to check and see if the Pod Has reached a specific Phase of the
 // runtime. This can be combined with PodConditionMatch to check if a specific condition and phase has been met.
 // This will enable validation such as checking against CLB of a POD.
 func (c *Condition) PodPhaseMatch(pod k8s.Object, phase v1.PodPhase) apimachinerywait.ConditionWithContextFunc {
-	return func(ctx context.Context) (done bool, err error) {
-		log.V(4).InfoS("Checking for phase match", "resource", c.namespacedName(pod), "phase", phase)
-		if err := c.resources.Get(context.Background(), pod.GetName(), pod.GetNamespace(), pod); err != nil {
+	logger := c.checkLogger(pod)
+	return c.reportObserved(pod, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for phase match", "phase", phase)
+		if err := c.resources.Get(ctx, pod.GetName(), pod.GetNamespace(), pod); err != nil {
 			return false, err
 		}
-		log.V(4).InfoS("Current phase", "phase", pod.(*v1.Pod).Status.Phase)
-		return pod.(*v1.Pod).Status.Phase == phase, nil
-	}
+		current := pod.(*v1.Pod).Status.Phase
+		log.V(4).Info("Current phase", "phase", current)
+		if current != phase && isPodFinished(pod.(*v1.Pod)) {
+			return false, NewTerminalError("pod finished with phase %s", current)
+		}
+		return current == phase, nil
+	})
+}
+
+// ContainerTerminated is a helper function used to check if a container of the Pod has terminated with the expected
+// exit code. The statuses of the init, regular and ephemeral containers are all checked, which makes this usable to
+// validate init containers, sidecar shutdown and one-shot Pods that are not managed by a Job. For containers that
+// have already been restarted, the state of their last termination is used.
+func (c *Condition) ContainerTerminated(pod k8s.Object, containerName string, exitCode int32) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(pod)
+	return c.reportObserved(pod, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for container to be terminated", "container", containerName, "exitCode", exitCode)
+		if err := c.resources.Get(ctx, pod.GetName(), pod.GetNamespace(), pod); err != nil {
+			return false, err
+		}
+		status := pod.(*v1.Pod).Status
+		statuses := append(append(append([]v1.ContainerStatus{}, status.InitContainerStatuses...), status.ContainerStatuses...), status.EphemeralContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.Name != containerName {
+				continue
+			}
+			terminated := cs.State.Terminated
+			if terminated == nil {
+				terminated = cs.LastTerminationState.Terminated
+			}
+			if terminated == nil {
+				wait.ReportUnmet(ctx, "container %q has not terminated yet", containerName)
+				return false, nil
+			}
+			if terminated.ExitCode != exitCode {
+				if isPodFinished(pod.(*v1.Pod)) {
+					return false, NewTerminalError("container %q terminated with exit code %d, expected %d", containerName, terminated.ExitCode, exitCode)
+				}
+				wait.ReportUnmet(ctx, "container %q terminated with exit code %d, expected %d", containerName, terminated.ExitCode, exitCode)
+				return false, nil
+			}
+			return true, nil
+		}
+		wait.ReportUnmet(ctx, "no status found for container %q", containerName)
+		return false, nil
+	})
 }
 
 // PodReady is a helper function used to check if the pod condition v1.PodReady has reached v1.ConditionTrue state
@@ -294,6 +597,57 @@ func (c *Condition) JobFailed(job k8s.Object) apimachinerywait.ConditionWithCont
 	return c.JobConditionMatch(job, batchv1.JobFailed, v1.ConditionTrue)
 }
 
+// JobSucceeded is a helper function used to check if at least minSucceeded pods of the Job have succeeded, based on
+// the status.succeeded counter of the Job. This can be used to follow the progress of Jobs running with a number of
+// completions greater than 1.
+func (c *Condition) JobSucceeded(job k8s.Object, minSucceeded int32) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(job)
+	return c.reportObserved(job, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for succeeded job pods", "succeeded", minSucceeded)
+		if err := c.resources.Get(ctx, job.GetName(), job.GetNamespace(), job); err != nil {
+			return false, err
+		}
+		succeeded := job.(*batchv1.Job).Status.Succeeded
+		if cond, finished := finishedJobCondition(job.(*batchv1.Job).Status); finished && succeeded < minSucceeded {
+			return false, NewTerminalError("job finished with condition %s after %d pods succeeded", cond.Type, succeeded)
+		}
+		if succeeded < minSucceeded {
+			wait.ReportUnmet(ctx, "%d out of %d pods succeeded", succeeded, minSucceeded)
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+// JobFailedPods is a helper function used to check if the Job has completed with at most maxFailed failed pods, based
+// on the status.failed counter of the Job. A terminal error is returned as soon as more than maxFailed pods have
+// failed, or if the Job fails, so that the wait stops without running out of time. This can be used to assert that
+// a Job running with a number of completions greater than 1 completes within a budget of pod failures.
+func (c *Condition) JobFailedPods(job k8s.Object, maxFailed int32) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(job)
+	return c.reportObserved(job, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for failed job pods", "maxFailed", maxFailed)
+		if err := c.resources.Get(ctx, job.GetName(), job.GetNamespace(), job); err != nil {
+			return false, err
+		}
+		status := job.(*batchv1.Job).Status
+		if status.Failed > maxFailed {
+			return false, NewTerminalError("%d pods of the job failed, expected at most %d", status.Failed, maxFailed)
+		}
+		cond, finished := finishedJobCondition(status)
+		if !finished {
+			wait.ReportUnmet(ctx, "job has not completed yet, %d out of at most %d pods failed", status.Failed, maxFailed)
+			return false, nil
+		}
+		if cond.Type != batchv1.JobComplete {
+			return false, NewTerminalError("job finished with condition %s after %d pods failed", cond.Type, status.Failed)
+		}
+		return true, nil
+	})
+}
+
 // DeploymentAvailable is a helper function used to check if the deployment condition appsv1.DeploymentAvailable
 // has reached v1.ConditionTrue state
 func (c *Condition) DeploymentAvailable(name, namespace string) apimachinerywait.ConditionWithContextFunc {
@@ -304,16 +658,433 @@ func (c *Condition) DeploymentAvailable(name, namespace string) apimachinerywait
 	)
 }
 
+// DeploymentRolloutComplete is a helper function used to check if the rollout of the latest revision of the
+// Deployment has completed, the same way `kubectl rollout status deployment` does. This requires the deployment
+// controller to have observed the latest generation of the Deployment and all the replicas to have been updated
+// to the latest pod template and be available, with none of the replicas of the previous revisions left running.
+func (c *Condition) DeploymentRolloutComplete(deployment k8s.Object) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(deployment)
+	return c.reportObserved(deployment, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for deployment rollout to complete")
+		if err := c.resources.Get(ctx, deployment.GetName(), deployment.GetNamespace(), deployment); err != nil {
+			return false, err
+		}
+		d := deployment.(*appsv1.Deployment)
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		status := d.Status
+		log.V(4).Info("Current Status of the deployment resource", "status", status)
+		switch {
+		case status.ObservedGeneration < d.Generation:
+			wait.ReportUnmet(ctx, "waiting for deployment spec update to be observed: observed generation %d, generation %d", status.ObservedGeneration, d.Generation)
+		case status.UpdatedReplicas < replicas:
+			wait.ReportUnmet(ctx, "%d out of %d new replicas have been updated", status.UpdatedReplicas, replicas)
+		case status.Replicas > status.UpdatedReplicas:
+			wait.ReportUnmet(ctx, "%d old replicas are pending termination", status.Replicas-status.UpdatedReplicas)
+		case status.AvailableReplicas < status.UpdatedReplicas || status.UnavailableReplicas > 0:
+			wait.ReportUnmet(ctx, "%d of %d updated replicas are available", status.AvailableReplicas, status.UpdatedReplicas)
+		default:
+			done = true
+		}
+		return
+	})
+}
+
+// RolloutComplete is a helper function used to check if the rollout of the latest revision of a Deployment,
+// StatefulSet or DaemonSet has completed, the same way `kubectl rollout status` does. For Deployments this is
+// the same check as DeploymentRolloutComplete.
+func (c *Condition) RolloutComplete(obj k8s.Object) apimachinerywait.ConditionWithContextFunc {
+	if _, ok := obj.(*appsv1.Deployment); ok {
+		return c.DeploymentRolloutComplete(obj)
+	}
+	logger := c.checkLogger(obj)
+	return c.reportObserved(obj, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for rollout to complete")
+		if err := c.resources.Get(ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
+			return false, err
+		}
+		switch o := obj.(type) {
+		case *appsv1.StatefulSet:
+			replicas := int32(1)
+			if o.Spec.Replicas != nil {
+				replicas = *o.Spec.Replicas
+			}
+			status := o.Status
+			switch {
+			case status.ObservedGeneration < o.Generation:
+				wait.ReportUnmet(ctx, "waiting for statefulset spec update to be observed: observed generation %d, generation %d", status.ObservedGeneration, o.Generation)
+			case status.UpdatedReplicas < replicas:
+				wait.ReportUnmet(ctx, "%d out of %d new replicas have been updated", status.UpdatedReplicas, replicas)
+			case status.ReadyReplicas < replicas:
+				wait.ReportUnmet(ctx, "%d of %d replicas are ready", status.ReadyReplicas, replicas)
+			case o.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType && status.UpdateRevision != status.CurrentRevision:
+				wait.ReportUnmet(ctx, "waiting for statefulset rolling update to complete: current revision %s, update revision %s", status.CurrentRevision, status.UpdateRevision)
+			default:
+				done = true
+			}
+		case *appsv1.DaemonSet:
+			status := o.Status
+			switch {
+			case status.ObservedGeneration < o.Generation:
+				wait.ReportUnmet(ctx, "waiting for daemonset spec update to be observed: observed generation %d, generation %d", status.ObservedGeneration, o.Generation)
+			case status.UpdatedNumberScheduled < status.DesiredNumberScheduled:
+				wait.ReportUnmet(ctx, "%d out of %d new pods have been updated", status.UpdatedNumberScheduled, status.DesiredNumberScheduled)
+			case status.NumberAvailable < status.DesiredNumberScheduled:
+				wait.ReportUnmet(ctx, "%d of %d updated pods are available", status.NumberAvailable, status.DesiredNumberScheduled)
+			default:
+				done = true
+			}
+		default:
+			return false, fmt.Errorf("condition: unsupported type %T for rollout status", obj)
+		}
+		return
+	})
+}
+
+// CertificateReady is a helper function used to check if a cert-manager Certificate has reached the Ready=True
+// condition and the Secret it issues the certificate into exists. The Certificate is accessed as an
+// unstructured.Unstructured object so that cert-manager types do not need to be registered with the scheme.
+func (c *Condition) CertificateReady(name, namespace string) apimachinerywait.ConditionWithContextFunc {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"})
+	certificate.SetName(name)
+	certificate.SetNamespace(namespace)
+	logger := c.checkLogger(certificate)
+	return c.reportObserved(certificate, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for certificate to be ready")
+		if err := c.resources.Get(ctx, name, namespace, certificate); err != nil {
+			return false, err
+		}
+		conditions, _, err := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+		if err != nil {
+			return false, err
+		}
+		var ready map[string]interface{}
+		for _, cond := range conditions {
+			if cond, ok := cond.(map[string]interface{}); ok && cond["type"] == "Ready" {
+				ready = cond
+			}
+		}
+		if ready == nil {
+			wait.ReportUnmet(ctx, "certificate has no Ready condition yet")
+			return false, nil
+		}
+		if ready["status"] != string(v1.ConditionTrue) {
+			wait.ReportUnmet(ctx, "certificate is not ready: %v: %v", ready["reason"], ready["message"])
+			return false, nil
+		}
+		secretName, _, err := unstructured.NestedString(certificate.Object, "spec", "secretName")
+		if err != nil {
+			return false, err
+		}
+		if err := c.resources.Get(ctx, secretName, namespace, &v1.Secret{}); err != nil {
+			if errors.IsNotFound(err) {
+				wait.ReportUnmet(ctx, "certificate secret %q not found", secretName)
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+// APIServiceAvailable is a helper function used to check if an APIService has reached the Available=True condition,
+// i.e. the aggregated API it registers, such as the metrics API of metrics-server, is served. The APIService is
+// accessed as an unstructured.Unstructured object so that the types of the aggregator do not need to be registered
+// with the scheme.
+func (c *Condition) APIServiceAvailable(name string) apimachinerywait.ConditionWithContextFunc {
+	apiService := &unstructured.Unstructured{}
+	apiService.SetGroupVersionKind(schema.GroupVersionKind{Group: "apiregistration.k8s.io", Version: "v1", Kind: "APIService"})
+	apiService.SetName(name)
+	logger := c.checkLogger(apiService)
+	return c.reportObserved(apiService, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for APIService to be available")
+		if err := c.resources.Get(ctx, name, "", apiService); err != nil {
+			return false, err
+		}
+		conditions, _, err := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+		if err != nil {
+			return false, err
+		}
+		for _, cond := range conditions {
+			cond, ok := cond.(map[string]interface{})
+			if ok && cond["type"] == "Available" && cond["status"] == string(v1.ConditionTrue) {
+				return true, nil
+			}
+		}
+		wait.ReportUnmet(ctx, "APIService is not available, conditions: %v", conditions)
+		return false, nil
+	})
+}
+
+// CRDEstablished is a helper function used to check if a CustomResourceDefinition has reached the Established=True
+// condition, i.e. the custom resources it defines are served by the API server and can be created. The
+// CustomResourceDefinition is accessed as an unstructured.Unstructured object so that the apiextensions types do
+// not need to be registered with the scheme.
+func (c *Condition) CRDEstablished(name string) apimachinerywait.ConditionWithContextFunc {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"})
+	crd.SetName(name)
+	logger := c.checkLogger(crd)
+	return c.reportObserved(crd, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for custom resource definition to be established")
+		if err := c.resources.Get(ctx, name, "", crd); err != nil {
+			if errors.IsNotFound(err) {
+				wait.ReportUnmet(ctx, "custom resource definition not found")
+				return false, nil
+			}
+			return false, err
+		}
+		conditions, _, err := unstructured.NestedSlice(crd.Object, "status", "conditions")
+		if err != nil {
+			return false, err
+		}
+		for _, cond := range conditions {
+			cond, ok := cond.(map[string]interface{})
+			if ok && cond["type"] == "Established" && cond["status"] == string(v1.ConditionTrue) {
+				return true, nil
+			}
+		}
+		wait.ReportUnmet(ctx, "custom resource definition is not established, conditions: %v", conditions)
+		return false, nil
+	})
+}
+
+// ConfigMapKeyMatch is a helper function used to check if a ConfigMap exists and contains the key in either its data
+// or binaryData. If matchFetcher is not nil, the value stored under the key must also pass the match validation, which
+// can be used to check for an expected value, e.g. using regexp.MatchString. This can be leveraged to wait for state
+// published by controllers into ConfigMaps such as CA bundles or leader election records.
+func (c *Condition) ConfigMapKeyMatch(configMap k8s.Object, key string, matchFetcher func(value string) bool) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(configMap)
+	return func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for configmap key", "key", key)
+		if err := c.resources.Get(ctx, configMap.GetName(), configMap.GetNamespace(), configMap); err != nil {
+			if errors.IsNotFound(err) {
+				wait.ReportUnmet(ctx, "configmap not found")
+				return false, nil
+			}
+			return false, err
+		}
+		cm := configMap.(*v1.ConfigMap)
+		value, ok := cm.Data[key]
+		if !ok {
+			var binaryValue []byte
+			if binaryValue, ok = cm.BinaryData[key]; ok {
+				value = string(binaryValue)
+			}
+		}
+		return keyMatch(ctx, key, value, ok, matchFetcher), nil
+	}
+}
+
+// SecretKeyMatch is a helper function used to check if a Secret exists and contains the key. If matchFetcher is not
+// nil, the decoded value stored under the key must also pass the match validation.
+func (c *Condition) SecretKeyMatch(secret k8s.Object, key string, matchFetcher func(value string) bool) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(secret)
+	return func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for secret key", "key", key)
+		if err := c.resources.Get(ctx, secret.GetName(), secret.GetNamespace(), secret); err != nil {
+			if errors.IsNotFound(err) {
+				wait.ReportUnmet(ctx, "secret not found")
+				return false, nil
+			}
+			return false, err
+		}
+		value, ok := secret.(*v1.Secret).Data[key]
+		return keyMatch(ctx, key, string(value), ok, matchFetcher), nil
+	}
+}
+
+func keyMatch(ctx context.Context, key, value string, found bool, matchFetcher func(value string) bool) bool {
+	if !found {
+		wait.ReportUnmet(ctx, "key %q not found", key)
+		return false
+	}
+	if matchFetcher != nil && !matchFetcher(value) {
+		wait.ReportUnmet(ctx, "value of key %q does not match", key)
+		return false
+	}
+	return true
+}
+
+// PodDisruptionBudgetSatisfied is a helper function used to check if a PodDisruptionBudget has observed its latest
+// generation and reports enough healthy pods to allow for at least one disruption. This can be used before performing
+// drain or eviction operations that are expected to be allowed by the budget.
+func (c *Condition) PodDisruptionBudgetSatisfied(pdb k8s.Object) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(pdb)
+	return c.reportObserved(pdb, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for pod disruption budget to be satisfied")
+		if err := c.resources.Get(ctx, pdb.GetName(), pdb.GetNamespace(), pdb); err != nil {
+			return false, err
+		}
+		budget := pdb.(*policyv1.PodDisruptionBudget)
+		status := budget.Status
+		log.V(4).Info("Current Status of the pod disruption budget resource", "status", status)
+		switch {
+		case status.ObservedGeneration < budget.Generation:
+			wait.ReportUnmet(ctx, "waiting for pod disruption budget spec update to be observed")
+		case status.CurrentHealthy < status.DesiredHealthy:
+			wait.ReportUnmet(ctx, "%d out of %d desired healthy pods are healthy", status.CurrentHealthy, status.DesiredHealthy)
+		case status.DisruptionsAllowed < 1:
+			wait.ReportUnmet(ctx, "no disruptions allowed")
+		default:
+			done = true
+		}
+		return
+	})
+}
+
+// NodeSchedulableMatch is a helper function used to check if the spec.unschedulable field of the Node has reached
+// the expected value. This can be used to wait for cordon and uncordon operations to be applied to a Node.
+func (c *Condition) NodeSchedulableMatch(node k8s.Object, unschedulable bool) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(node)
+	return c.reportObserved(node, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for node schedulable state", "unschedulable", unschedulable)
+		if err := c.resources.Get(ctx, node.GetName(), node.GetNamespace(), node); err != nil {
+			return false, err
+		}
+		current := node.(*v1.Node).Spec.Unschedulable
+		if current != unschedulable {
+			wait.ReportUnmet(ctx, "node unschedulable is %v, expected %v", current, unschedulable)
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+// NodeCordoned is a helper function used to check if the Node has been marked as unschedulable
+func (c *Condition) NodeCordoned(node k8s.Object) apimachinerywait.ConditionWithContextFunc {
+	return c.NodeSchedulableMatch(node, true)
+}
+
+// NodeSchedulable is a helper function used to check if the Node is no longer marked as unschedulable
+func (c *Condition) NodeSchedulable(node k8s.Object) apimachinerywait.ConditionWithContextFunc {
+	return c.NodeSchedulableMatch(node, false)
+}
+
+// NodeTaintMatch is a helper function used to check for the presence or absence of a taint on the Node. Taints are
+// matched by key and effect, the same way the scheduler does it.
+func (c *Condition) NodeTaintMatch(node k8s.Object, taint v1.Taint, present bool) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(node)
+	return c.reportObserved(node, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for node taint", "taint", taint.ToString(), "present", present)
+		if err := c.resources.Get(ctx, node.GetName(), node.GetNamespace(), node); err != nil {
+			return false, err
+		}
+		found := false
+		for _, t := range node.(*v1.Node).Spec.Taints {
+			if t.MatchTaint(&taint) {
+				found = true
+			}
+		}
+		if found != present {
+			wait.ReportUnmet(ctx, "taint %s present on node is %v, expected %v", taint.ToString(), found, present)
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+// NodeTaintPresent is a helper function used to check if the taint has been added to the Node
+func (c *Condition) NodeTaintPresent(node k8s.Object, taint v1.Taint) apimachinerywait.ConditionWithContextFunc {
+	return c.NodeTaintMatch(node, taint, true)
+}
+
+// NodeTaintAbsent is a helper function used to check if the taint has been removed from the Node
+func (c *Condition) NodeTaintAbsent(node k8s.Object, taint v1.Taint) apimachinerywait.ConditionWithContextFunc {
+	return c.NodeTaintMatch(node, taint, false)
+}
+
+// WebhookReady is a helper function used to check if the webhooks of a ValidatingWebhookConfiguration or a
+// MutatingWebhookConfiguration are ready to serve requests. Each webhook backed by a Service needs that Service to
+// have at least one ready endpoint. If requireCABundle is set, each webhook must also have its CA bundle populated,
+// which can be used when the CA bundle is injected by a tool such as cert-manager after the installation.
+func (c *Condition) WebhookReady(webhookConfiguration k8s.Object, requireCABundle bool) apimachinerywait.ConditionWithContextFunc {
+	logger := c.checkLogger(webhookConfiguration)
+	return c.reportObserved(webhookConfiguration, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for webhook to be ready", "requireCABundle", requireCABundle)
+		if err := c.resources.Get(ctx, webhookConfiguration.GetName(), webhookConfiguration.GetNamespace(), webhookConfiguration); err != nil {
+			return false, err
+		}
+		var clientConfigs map[string]admissionregistrationv1.WebhookClientConfig
+		switch wh := webhookConfiguration.(type) {
+		case *admissionregistrationv1.ValidatingWebhookConfiguration:
+			clientConfigs = make(map[string]admissionregistrationv1.WebhookClientConfig, len(wh.Webhooks))
+			for _, webhook := range wh.Webhooks {
+				clientConfigs[webhook.Name] = webhook.ClientConfig
+			}
+		case *admissionregistrationv1.MutatingWebhookConfiguration:
+			clientConfigs = make(map[string]admissionregistrationv1.WebhookClientConfig, len(wh.Webhooks))
+			for _, webhook := range wh.Webhooks {
+				clientConfigs[webhook.Name] = webhook.ClientConfig
+			}
+		default:
+			return false, fmt.Errorf("condition: unexpected type %T, expected a validating or mutating webhook configuration", webhookConfiguration)
+		}
+		for name, clientConfig := range clientConfigs {
+			if requireCABundle && len(clientConfig.CABundle) == 0 {
+				wait.ReportUnmet(ctx, "webhook %q has no CA bundle", name)
+				return false, nil
+			}
+			if clientConfig.Service == nil {
+				continue
+			}
+			ready, err := c.serviceHasReadyEndpoints(ctx, clientConfig.Service.Name, clientConfig.Service.Namespace)
+			if err != nil {
+				return false, err
+			}
+			if !ready {
+				wait.ReportUnmet(ctx, "service %s/%s of webhook %q has no ready endpoints", clientConfig.Service.Namespace, clientConfig.Service.Name, name)
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// serviceHasReadyEndpoints checks if any of the EndpointSlices of the Service has a ready endpoint
+func (c *Condition) serviceHasReadyEndpoints(ctx context.Context, name, namespace string) (bool, error) {
+	var slices discoveryv1.EndpointSliceList
+	if err := c.resources.GetControllerRuntimeClient().List(ctx, &slices, cr.InNamespace(namespace), cr.MatchingLabels{discoveryv1.LabelServiceName: name}); err != nil {
+		return false, err
+	}
+	for _, slice := range slices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 // DaemonSetReady is a helper function used to check if a daemonset's pods are scheduled and ready
 func (c *Condition) DaemonSetReady(daemonset k8s.Object) apimachinerywait.ConditionWithContextFunc {
-	return func(ctx context.Context) (done bool, err error) {
+	logger := c.checkLogger(daemonset)
+	return c.reportObserved(daemonset, func(ctx context.Context) (done bool, err error) {
+		log := logger()
+		log.V(4).Info("Checking for daemonset to be ready")
 		if err := c.resources.Get(ctx, daemonset.GetName(), daemonset.GetNamespace(), daemonset); err != nil {
 			return false, err
 		}
 		status := daemonset.(*appsv1.DaemonSet).Status
+		log.V(4).Info("Current Status of the daemonset resource", "status", status)
 		if status.NumberReady == status.DesiredNumberScheduled && status.NumberUnavailable == 0 {
 			done = true
 		}
 		return
-	}
+	})
 }
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+)
+
+// fakeAPIServer serves the discovery of the core and cert-manager.io groups along with the given objects, keyed by
+// their path, and NotFound for everything else
+func fakeAPIServer(t *testing.T, objects map[string]any) *resources.Resources {
+	t.Helper()
+	objects["/api/v1"] = map[string]any{
+		"kind":         "APIResourceList",
+		"groupVersion": "v1",
+		"resources":    []any{map[string]any{"name": "secrets", "kind": "Secret", "namespaced": true, "verbs": []string{"get"}}},
+	}
+	objects["/apis/cert-manager.io/v1"] = map[string]any{
+		"kind":         "APIResourceList",
+		"groupVersion": "cert-manager.io/v1",
+		"resources":    []any{map[string]any{"name": "certificates", "kind": "Certificate", "namespaced": true, "verbs": []string{"get"}}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		obj, ok := objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			obj = map[string]any{"kind": "Status", "apiVersion": "v1", "status": "Failure", "reason": "NotFound", "code": http.StatusNotFound}
+		}
+		_ = json.NewEncoder(w).Encode(obj)
+	}))
+	t.Cleanup(server.Close)
+	res, err := resources.New(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("failed to create resources: %v", err)
+	}
+	return res
+}
+
+func certificate(status map[string]any) map[string]any {
+	cert := map[string]any{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]any{"name": "example", "namespace": "default"},
+		"spec":       map[string]any{"secretName": "example-tls"},
+	}
+	if status != nil {
+		cert["status"] = status
+	}
+	return cert
+}
+
+func readyCondition(status, reason, message string) map[string]any {
+	return map[string]any{"conditions": []any{map[string]any{"type": "Ready", "status": status, "reason": reason, "message": message}}}
+}
+
+func TestCertificateReady(t *testing.T) {
+	secret := map[string]any{"apiVersion": "v1", "kind": "Secret", "metadata": map[string]any{"name": "example-tls", "namespace": "default"}}
+	tests := []struct {
+		name          string
+		certificate   map[string]any
+		secret        map[string]any
+		expectDone    bool
+		expectMessage string
+	}{
+		{name: "ready", certificate: certificate(readyCondition("True", "Ready", "Certificate is up to date and has not expired")), secret: secret, expectDone: true},
+		{name: "ready without secret", certificate: certificate(readyCondition("True", "Ready", "Certificate is up to date and has not expired")), expectMessage: `certificate secret "example-tls" not found`},
+		{name: "not ready", certificate: certificate(readyCondition("False", "Issuing", "Issuing certificate as Secret does not exist")), secret: secret, expectMessage: "certificate is not ready: Issuing: Issuing certificate as Secret does not exist"},
+		{name: "no status", certificate: certificate(nil), secret: secret, expectMessage: "certificate has no Ready condition yet"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			objects := map[string]any{"/apis/cert-manager.io/v1/namespaces/default/certificates/example": test.certificate}
+			if test.secret != nil {
+				objects["/api/v1/namespaces/default/secrets/example-tls"] = test.secret
+			}
+			ctx := wait.WithUnmetReporter(context.Background())
+			done, err := New(fakeAPIServer(t, objects)).CertificateReady("example", "default")(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if done != test.expectDone {
+				t.Errorf("expected done to be %v, got %v", test.expectDone, done)
+			}
+			if reason := wait.UnmetReason(ctx); !strings.HasPrefix(reason, test.expectMessage) {
+				t.Errorf("expected reason to start with %q, got %q", test.expectMessage, reason)
+			}
+		})
+	}
+}
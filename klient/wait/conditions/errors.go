@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TerminalError is returned by the conditions that have observed a state of the resource under question from which
+// the condition can no longer be met, such as a Job that has failed while waiting for it to complete. As with any
+// error returned by a condition, this stops the wait right away instead of letting it spin until it times out.
+type TerminalError struct {
+	// Reason describes the state that was observed and prevents the condition from being met
+	Reason string
+}
+
+// NewTerminalError creates a new TerminalError with a formatted reason
+func NewTerminalError(format string, args ...interface{}) *TerminalError {
+	return &TerminalError{Reason: fmt.Sprintf(format, args...)}
+}
+
+func (e *TerminalError) Error() string {
+	return fmt.Sprintf("condition can no longer be met: %s", e.Reason)
+}
+
+// IsTerminalError can be used to check if the error returned by a wait was caused by a condition that reached a
+// state from which it can no longer be met
+func IsTerminalError(err error) bool {
+	var terminalErr *TerminalError
+	return errors.As(err, &terminalErr)
+}
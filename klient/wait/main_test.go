@@ -154,3 +154,30 @@ func createJob(name, cmd, arg string, t *testing.T) *batchv1.Job {
 	}
 	return job
 }
+
+func createCronJob(name string, t *testing.T) *batchv1.CronJob {
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": name}},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "* * * * *",
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{Name: name, Image: "alpine", Command: []string{"echo"}, Args: []string{"kubernetes"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	err := getResourceManager().Create(context.TODO(), cronJob)
+	if err != nil {
+		t.Error("failed to create a cronjob due to an error", err)
+	}
+	return cronJob
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type unmetReporterKey struct{}
+
+// unmetReporter keeps track of the last reason reported by a condition for not being met yet
+type unmetReporter struct {
+	mu     sync.Mutex
+	reason string
+}
+
+// WithUnmetReporter returns a child context of ctx that can be used to collect the reason reported by a condition
+// using ReportUnmet. This is used by For to explain why a wait was interrupted and can be used by functions wrapping
+// other conditions to find out why each of them were not met.
+func WithUnmetReporter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unmetReporterKey{}, &unmetReporter{})
+}
+
+// ReportUnmet records a human readable reason explaining why the condition being checked with ctx has not been met
+// yet. If the wait runs out of time before the condition is met, the last reason reported is included in the error
+// returned by For. Calling this with a context that was not created using WithUnmetReporter is a no-op.
+func ReportUnmet(ctx context.Context, format string, args ...interface{}) {
+	r, ok := ctx.Value(unmetReporterKey{}).(*unmetReporter)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reason = fmt.Sprintf(format, args...)
+}
+
+// UnmetReason returns the last reason reported using ReportUnmet on a context created by WithUnmetReporter
+func UnmetReason(ctx context.Context) string {
+	r, ok := ctx.Value(unmetReporterKey{}).(*unmetReporter)
+	if !ok {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reason
+}
@@ -18,9 +18,15 @@ package wait
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"time"
 
 	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/k8s/watcher"
 )
 
 const (
@@ -34,11 +40,23 @@ type Options struct {
 	// Timeout is used to indicate the total time to be spent in polling for the condition
 	// to be met.
 	Timeout time.Duration
-	// StopChan is used to setup a wait mechanism using the apimachinerywait.PollUntil method
+	// Ctx is the parent context of the wait. It is passed down to each invocation of the condition so that
+	// the API calls made by the condition are cancelled along with the wait itself.
 	Ctx context.Context
 	// Immediate is used to indicate if the apimachinerywait's immediate wait method are to be
 	// called instead of the regular one
 	Immediate bool
+	// BackoffFactor is used to multiply the interval after each check of the condition that is not met. A factor
+	// lower than or equal to 1 disables the backoff and the condition is checked on a fixed interval
+	BackoffFactor float64
+	// MaxInterval is used to cap the interval between two checks of the condition when a backoff is configured
+	MaxInterval time.Duration
+	// Jitter is used to add a random duration of up to Jitter * interval to each interval in between checks of
+	// the condition
+	Jitter float64
+	// Watch is used to drive the condition checks using the events received from a watch on the resources
+	// under question instead of polling on every interval
+	Watch *watcher.EventHandlerFuncs
 }
 
 type Option func(*Options)
@@ -78,6 +96,35 @@ func WithImmediate() Option {
 	}
 }
 
+// WithBackoff configures the interval between the retries to grow exponentially by factor after every check of the
+// condition that is not met, starting from the value configured by WithInterval or defaultPollInterval. The interval
+// will not grow past maxInterval if it is set to a non-zero value. This avoids having to choose between checking the
+// condition too often for slow operations or waiting for too long for the fast ones.
+func WithBackoff(factor float64, maxInterval time.Duration) Option {
+	return func(options *Options) {
+		options.BackoffFactor = factor
+		options.MaxInterval = maxInterval
+	}
+}
+
+// WithJitter configures a random amount of time of up to jitter * interval to be added to each interval between the
+// retries. This can be used to spread the API calls of a large number of concurrent waits.
+func WithJitter(jitter float64) Option {
+	return func(options *Options) {
+		options.Jitter = jitter
+	}
+}
+
+// WithWatch configures the Wait Checks to be driven by a watch on the resources described by the list and the
+// list options instead of polling them on a fixed interval. The condition is checked right away and then once
+// every time an event is received for the watched resources. The value configured by WithInterval or the
+// defaultPollInterval is still used as a resync period so that a missed event does not stall the wait.
+func WithWatch(r *resources.Resources, list k8s.ObjectList, listOptions ...resources.ListOption) Option {
+	return func(options *Options) {
+		options.Watch = r.Watch(list, listOptions...)
+	}
+}
+
 // For provides a way to perform poll checks against the kubernetes resource to make sure the resource under
 // test has reached a suitable state before moving to the next action or fail with an error message.
 //
@@ -105,5 +152,100 @@ func For(conditionFunc apimachinerywait.ConditionWithContextFunc, opts ...Option
 		defer cancel()
 	}
 
-	return apimachinerywait.PollUntilContextCancel(options.Ctx, options.Interval, options.Immediate, conditionFunc)
+	var lastUnmetReason string
+	checkFunc := func(ctx context.Context) (bool, error) {
+		ctx = WithUnmetReporter(ctx)
+		done, err := conditionFunc(ctx)
+		lastUnmetReason = UnmetReason(ctx)
+		return done, err
+	}
+
+	var err error
+	if options.Watch != nil {
+		err = watchUntil(options.Ctx, options.Interval, options.Watch, checkFunc)
+	} else if options.BackoffFactor > 1 || options.Jitter > 0 {
+		err = backoffUntil(options.Ctx, options.backoff(), options.Immediate, checkFunc)
+	} else {
+		err = apimachinerywait.PollUntilContextCancel(options.Ctx, options.Interval, options.Immediate, checkFunc)
+	}
+	if err != nil && apimachinerywait.Interrupted(err) && lastUnmetReason != "" {
+		return fmt.Errorf("%w: %s", err, lastUnmetReason)
+	}
+	return err
+}
+
+// backoff converts the interval, backoff and jitter options into an apimachinerywait.Backoff that never runs out of
+// steps, as the duration of the wait is controlled by the Timeout and Ctx options.
+func (o *Options) backoff() *apimachinerywait.Backoff {
+	factor := o.BackoffFactor
+	if factor < 1 {
+		factor = 1
+	}
+	return &apimachinerywait.Backoff{
+		Duration: o.Interval,
+		Factor:   factor,
+		Jitter:   o.Jitter,
+		Steps:    math.MaxInt32,
+		Cap:      o.MaxInterval,
+	}
+}
+
+// backoffUntil checks the condition after each of the intervals given by the backoff until the condition is met,
+// the condition returns an error or the context is done.
+func backoffUntil(ctx context.Context, backoff *apimachinerywait.Backoff, immediate bool, conditionFunc apimachinerywait.ConditionWithContextFunc) error {
+	if immediate {
+		if done, err := conditionFunc(ctx); err != nil || done {
+			return err
+		}
+	}
+	for {
+		timer := time.NewTimer(backoff.Step())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if done, err := conditionFunc(ctx); err != nil || done {
+			return err
+		}
+	}
+}
+
+// watchUntil checks the condition every time an event is received from the watch, or the interval has elapsed
+// without any event, until the condition is met, the condition returns an error or the context is done.
+func watchUntil(ctx context.Context, interval time.Duration, w *watcher.EventHandlerFuncs, conditionFunc apimachinerywait.ConditionWithContextFunc) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan struct{}, 1)
+	notify := func(interface{}) {
+		select {
+		case events <- struct{}{}:
+		default:
+		}
+	}
+	if err := w.WithAddFunc(notify).WithUpdateFunc(notify).WithDeleteFunc(notify).Start(ctx); err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	resync := time.NewTicker(interval)
+	defer resync.Stop()
+
+	for {
+		done, err := conditionFunc(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-events:
+		case <-resync.C:
+		}
+	}
 }
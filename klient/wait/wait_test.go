@@ -18,15 +18,21 @@ package wait_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr/testr"
 	log "k8s.io/klog/v2"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"sigs.k8s.io/e2e-framework/klient/k8s"
 	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
@@ -43,6 +49,27 @@ func TestPodRunning(t *testing.T) {
 	}
 }
 
+func TestPodRunningWithWatch(t *testing.T) {
+	var err error
+	pod := createPod("p12", t)
+	err = wait.For(
+		conditions.New(getResourceManager()).PodRunning(pod),
+		wait.WithWatch(getResourceManager(), &v1.PodList{}, resources.WithFieldSelector("metadata.name=p12")),
+	)
+	if err != nil {
+		t.Error("failed to wait for pod to reach running condition using a watch", err)
+	}
+}
+
+func TestPodRunningWithLogger(t *testing.T) {
+	var err error
+	pod := createPod("p14", t)
+	err = wait.For(conditions.New(getResourceManager()).WithLogger(testr.NewWithOptions(t, testr.Options{Verbosity: 4})).PodRunning(pod))
+	if err != nil {
+		t.Error("failed to wait for pod to reach running condition", err)
+	}
+}
+
 func TestPodPhaseMatch(t *testing.T) {
 	var err error
 	pod := createPod("p2", t)
@@ -88,6 +115,107 @@ func TestJobFailed(t *testing.T) {
 	}
 }
 
+func TestJobSucceeded(t *testing.T) {
+	var err error
+	job := createJob("j3", "echo", "kubernetes", t)
+	err = wait.For(conditions.New(getResourceManager()).JobSucceeded(job, 1))
+	if err != nil {
+		t.Error("failed waiting for job pods to succeed", err)
+	}
+}
+
+func TestJobFailedPods(t *testing.T) {
+	var err error
+	job := createJob("j4", "echo", "kubernetes", t)
+	err = wait.For(conditions.New(getResourceManager()).JobFailedPods(job, 0))
+	if err != nil {
+		t.Error("failed waiting for job to complete without failed pods", err)
+	}
+}
+
+func TestJobFailedPodsTerminalError(t *testing.T) {
+	var err error
+	job := createJob("j7", "exit", "1", t)
+	err = wait.For(conditions.New(getResourceManager()).JobFailedPods(job, 1), wait.WithTimeout(7*time.Minute))
+	if !conditions.IsTerminalError(err) {
+		t.Error("expected waiting for a job with too many failed pods to return a terminal error", err)
+	}
+}
+
+func TestRolloutRestart(t *testing.T) {
+	var err error
+	deployment := createDeployment("d12", 1, t)
+	err = wait.For(conditions.New(getResourceManager()).RolloutComplete(deployment))
+	if err != nil {
+		t.Error("failed waiting for deployment rollout to complete", err)
+	}
+	err = getResourceManager().RolloutRestart(context.Background(), deployment)
+	if err != nil {
+		t.Fatal("failed to restart deployment rollout due to an error", err)
+	}
+	if _, ok := deployment.Spec.Template.Annotations[resources.RestartedAtAnnotation]; !ok {
+		t.Error("expected the pod template to be annotated with the restart time")
+	}
+	err = wait.For(conditions.New(getResourceManager()).RolloutComplete(deployment))
+	if err != nil {
+		t.Error("failed waiting for restarted deployment rollout to complete", err)
+	}
+}
+
+func TestCronJobTriggered(t *testing.T) {
+	var err error
+	cronJob := createCronJob("cj1", t)
+	var job batchv1.Job
+	err = wait.For(conditions.New(getResourceManager()).CronJobTriggered(cronJob, &job), wait.WithTimeout(3*time.Minute))
+	if err != nil {
+		t.Error("failed waiting for cronjob to spawn a job", err)
+	}
+	if !metav1.IsControlledBy(&job, cronJob) {
+		t.Errorf("expected job %s to be controlled by the cronjob", job.Name)
+	}
+}
+
+func TestCronJobScheduled(t *testing.T) {
+	var err error
+	cronJob := createCronJob("cj2", t)
+	err = wait.For(conditions.New(getResourceManager()).CronJobScheduled(cronJob), wait.WithTimeout(3*time.Minute), wait.WithImmediate())
+	if err != nil {
+		t.Error("failed waiting for cronjob to be scheduled", err)
+	}
+}
+
+func TestContainerTerminated(t *testing.T) {
+	var err error
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "p13", Namespace: namespace},
+		Spec: v1.PodSpec{
+			RestartPolicy:  v1.RestartPolicyNever,
+			InitContainers: []v1.Container{{Name: "init", Image: "alpine", Command: []string{"true"}}},
+			Containers:     []v1.Container{{Name: "main", Image: "alpine", Command: []string{"sh", "-c", "exit 3"}}},
+		},
+	}
+	if err = getResourceManager().Create(context.TODO(), pod); err != nil {
+		t.Fatal("failed to create pod due to an error", err)
+	}
+	err = wait.For(conditions.New(getResourceManager()).ContainerTerminated(pod, "init", 0))
+	if err != nil {
+		t.Error("failed waiting for init container to terminate", err)
+	}
+	err = wait.For(conditions.New(getResourceManager()).ContainerTerminated(pod, "main", 3))
+	if err != nil {
+		t.Error("failed waiting for container to terminate with the expected exit code", err)
+	}
+}
+
+func TestJobCompletedTerminalError(t *testing.T) {
+	var err error
+	job := createJob("j5", "exit", "1", t)
+	err = wait.For(conditions.New(getResourceManager()).JobCompleted(job), wait.WithTimeout(7*time.Minute))
+	if !conditions.IsTerminalError(err) {
+		t.Error("expected waiting for a failed job to complete to return a terminal error", err)
+	}
+}
+
 func TestResourceDeleted(t *testing.T) {
 	var err error
 	pod := createPod("p5", t)
@@ -131,6 +259,124 @@ func TestDeploymentConditionMatch(t *testing.T) {
 	log.Info("Done")
 }
 
+func TestDeploymentRolloutComplete(t *testing.T) {
+	var err error
+	deployment := createDeployment("d9", 2, t)
+	err = wait.For(conditions.New(getResourceManager()).DeploymentRolloutComplete(deployment))
+	if err != nil {
+		t.Error("failed waiting for deployment rollout to complete", err)
+	}
+	deployment.Spec.Template.Spec.Containers[0].Image = "nginx:alpine"
+	err = getResourceManager().Update(context.Background(), deployment)
+	if err != nil {
+		t.Error("failed to update deployment due to an error", err)
+	}
+	err = wait.For(conditions.New(getResourceManager()).DeploymentRolloutComplete(deployment))
+	if err != nil {
+		t.Error("failed waiting for updated deployment rollout to complete", err)
+	}
+	log.Info("Done")
+}
+
+func TestPodDisruptionBudgetSatisfied(t *testing.T) {
+	var err error
+	createDeployment("d10", 2, t)
+	minAvailable := intstr.FromInt32(1)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "pdb1", Namespace: namespace},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "d10"}},
+		},
+	}
+	if err = getResourceManager().Create(context.TODO(), pdb); err != nil {
+		t.Fatal("failed to create pod disruption budget due to an error", err)
+	}
+	err = wait.For(conditions.New(getResourceManager()).PodDisruptionBudgetSatisfied(pdb))
+	if err != nil {
+		t.Error("failed waiting for pod disruption budget to be satisfied", err)
+	}
+}
+
+func TestNodeCordonAndTaint(t *testing.T) {
+	var err error
+	nodes := &v1.NodeList{}
+	if err = getResourceManager().List(context.TODO(), nodes); err != nil || len(nodes.Items) == 0 {
+		t.Fatal("failed to list cluster nodes", err)
+	}
+	node := &nodes.Items[0]
+	taint := v1.Taint{Key: "e2e-framework/wait-test", Value: "true", Effect: v1.TaintEffectPreferNoSchedule}
+
+	node.Spec.Unschedulable = true
+	node.Spec.Taints = append(node.Spec.Taints, taint)
+	if err = getResourceManager().Update(context.TODO(), node); err != nil {
+		t.Fatal("failed to cordon and taint node", err)
+	}
+	err = wait.For(conditions.And(
+		conditions.New(getResourceManager()).NodeCordoned(node.DeepCopy()),
+		conditions.New(getResourceManager()).NodeTaintPresent(node.DeepCopy(), taint),
+	))
+	if err != nil {
+		t.Error("failed waiting for node to be cordoned and tainted", err)
+	}
+
+	if err = getResourceManager().Get(context.TODO(), node.Name, "", node); err != nil {
+		t.Fatal("failed to get node", err)
+	}
+	node.Spec.Unschedulable = false
+	node.Spec.Taints = node.Spec.Taints[:len(node.Spec.Taints)-1]
+	if err = getResourceManager().Update(context.TODO(), node); err != nil {
+		t.Fatal("failed to uncordon and untaint node", err)
+	}
+	err = wait.For(conditions.And(
+		conditions.New(getResourceManager()).NodeSchedulable(node.DeepCopy()),
+		conditions.New(getResourceManager()).NodeTaintAbsent(node.DeepCopy(), taint),
+	))
+	if err != nil {
+		t.Error("failed waiting for node to be uncordoned and untainted", err)
+	}
+}
+
+func TestWebhookReady(t *testing.T) {
+	var err error
+	createDeployment("d11", 1, t)
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "d11", Namespace: namespace},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{"app": "d11"},
+			Ports:    []v1.ServicePort{{Port: 443, TargetPort: intstr.FromInt32(80)}},
+		},
+	}
+	if err = getResourceManager().Create(context.TODO(), service); err != nil {
+		t.Fatal("failed to create service due to an error", err)
+	}
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	failurePolicy := admissionregistrationv1.Ignore
+	webhook := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "wait-test-webhook"},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+			Name:                    "d11.wait-test.e2e-framework.sigs.k8s.io",
+			ClientConfig:            admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{Name: "d11", Namespace: namespace}},
+			SideEffects:             &sideEffects,
+			FailurePolicy:           &failurePolicy,
+			AdmissionReviewVersions: []string{"v1"},
+			ObjectSelector:          &metav1.LabelSelector{MatchLabels: map[string]string{"app": "wait-test-webhook"}},
+		}},
+	}
+	if err = getResourceManager().Create(context.TODO(), webhook); err != nil {
+		t.Fatal("failed to create webhook configuration due to an error", err)
+	}
+	defer func() {
+		if err := getResourceManager().Delete(context.TODO(), webhook); err != nil {
+			t.Error("failed to delete webhook configuration due to an error", err)
+		}
+	}()
+	err = wait.For(conditions.New(getResourceManager()).WebhookReady(webhook, false))
+	if err != nil {
+		t.Error("failed waiting for webhook to be ready", err)
+	}
+}
+
 func TestResourceListN(t *testing.T) {
 	var err error
 	createDeployment("d3", 4, t)
@@ -223,6 +469,61 @@ func TestResourcesDeleted(t *testing.T) {
 	log.Info("Done")
 }
 
+func TestResourcesDeletedWithListOptions(t *testing.T) {
+	var err error
+	deployment := createDeployment("d8", 2, t)
+	selector := resources.WithLabelSelector(labels.FormatLabels(map[string]string{"app": "d8"}))
+	err = wait.For(conditions.New(getResourceManager()).ResourceListN(&v1.PodList{}, 2, selector))
+	if err != nil {
+		t.Error("failed waiting for deployment pods to be created", err)
+	}
+	err = getResourceManager().Delete(context.Background(), deployment)
+	if err != nil {
+		t.Error("failed to delete deployment due to an error", err)
+	}
+	err = wait.For(conditions.New(getResourceManager()).ResourcesDeleted(&v1.PodList{}, selector))
+	if err != nil {
+		t.Error("failed waiting for pods matching the selector to be deleted", err)
+	}
+	log.Info("Done")
+}
+
+func TestConfigMapKeyMatch(t *testing.T) {
+	var err error
+	configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm1", Namespace: namespace}}
+	go func() {
+		time.Sleep(2 * time.Second)
+		cm := configMap.DeepCopy()
+		cm.Data = map[string]string{"ca.crt": "-----BEGIN CERTIFICATE-----"}
+		if err := getResourceManager().Create(context.TODO(), cm); err != nil {
+			log.ErrorS(err, "ran into an error trying to create the configmap")
+		}
+	}()
+	err = wait.For(conditions.New(getResourceManager()).ConfigMapKeyMatch(configMap, "ca.crt", func(value string) bool {
+		return strings.HasPrefix(value, "-----BEGIN CERTIFICATE-----")
+	}), wait.WithInterval(time.Second))
+	if err != nil {
+		t.Error("failed waiting for configmap key", err)
+	}
+}
+
+func TestSecretKeyMatch(t *testing.T) {
+	var err error
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s1", Namespace: namespace},
+		StringData: map[string]string{"password": "s3cr3t"},
+	}
+	if err = getResourceManager().Create(context.TODO(), secret); err != nil {
+		t.Fatal("failed to create secret due to an error", err)
+	}
+	err = wait.For(conditions.New(getResourceManager()).SecretKeyMatch(secret, "password", func(value string) bool {
+		return value == "s3cr3t"
+	}))
+	if err != nil {
+		t.Error("failed waiting for secret key", err)
+	}
+}
+
 func TestResourceMatch(t *testing.T) {
 	var err error
 	deployment := createDeployment("d6", 2, t)
@@ -248,6 +549,18 @@ func TestDeploymentAvailable(t *testing.T) {
 	}
 }
 
+func TestTimeoutReportsObservedStatus(t *testing.T) {
+	var err error
+	job := createJob("j6", "sleep", "3600", t)
+	err = wait.For(conditions.New(getResourceManager()).JobCompleted(job), wait.WithTimeout(20*time.Second))
+	if err == nil {
+		t.Fatal("expected waiting for the job to complete to time out")
+	}
+	if !strings.Contains(err.Error(), "last observed status of") || !strings.Contains(err.Error(), `"active":1`) {
+		t.Errorf("expected the timeout error to report the last observed status of the job, got %q", err)
+	}
+}
+
 func TestForTimeout(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -290,3 +603,50 @@ func TestForCancel(t *testing.T) {
 		t.Error("expected error")
 	}
 }
+
+func TestForPropagatesContext(t *testing.T) {
+	type ctxKey string
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey("key"), "value"))
+	defer cancel()
+
+	err := wait.For(func(ctx context.Context) (bool, error) {
+		return ctx.Value(ctxKey("key")) == "value", nil
+	}, wait.WithContext(ctx), wait.WithTimeout(2*time.Second), wait.WithInterval(100*time.Millisecond), wait.WithImmediate())
+	if err != nil {
+		t.Error("expected the condition to receive the parent context", err)
+	}
+}
+
+func TestForBackoff(t *testing.T) {
+	var checks []time.Time
+	start := time.Now()
+	err := wait.For(func(ctx context.Context) (bool, error) {
+		checks = append(checks, time.Now())
+		return len(checks) == 4, nil
+	}, wait.WithInterval(100*time.Millisecond), wait.WithBackoff(2, 300*time.Millisecond), wait.WithTimeout(5*time.Second), wait.WithImmediate())
+	if err != nil {
+		t.Fatal("expected the condition to be met", err)
+	}
+	if checks[0].Sub(start) > 50*time.Millisecond {
+		t.Error("expected the first check to happen immediately")
+	}
+	// intervals are expected to be 100ms, 200ms and then capped at 300ms
+	for i, expected := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond} {
+		if got := checks[i+1].Sub(checks[i]); got < expected || got > expected+100*time.Millisecond {
+			t.Errorf("expected interval %d to be about %v, got %v", i+1, expected, got)
+		}
+	}
+}
+
+func TestForJitter(t *testing.T) {
+	start := time.Now()
+	err := wait.For(func(ctx context.Context) (bool, error) {
+		return true, nil
+	}, wait.WithInterval(200*time.Millisecond), wait.WithJitter(1), wait.WithTimeout(2*time.Second))
+	if err != nil {
+		t.Fatal("expected the condition to be met", err)
+	}
+	if dur := time.Since(start); dur < 200*time.Millisecond || dur > 500*time.Millisecond {
+		t.Errorf("expected the first check to happen between 200ms and 400ms, got %v", dur)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ctxvalue provides typed helpers to pass values between the setups, assessments and teardowns of a
+// feature, or between environment funcs, through their context.Context, e.g.
+//
+//	ctx = ctxvalue.Set(ctx, deployment)
+//	...
+//	deployment, ok := ctxvalue.Get[*appsv1.Deployment](ctx)
+//
+// The values are keyed by their type, and by a name for the values of the same type, so that values stored by
+// different packages, or under different names, never collide.
+package ctxvalue
+
+import (
+	"context"
+	"fmt"
+)
+
+// key is the key of the values of type T stored under name. As its type includes T, keys of different types are
+// never equal even when they share the same name.
+type key[T any] struct {
+	name string
+}
+
+// Set returns a copy of ctx holding the value of type T, which replaces any value of type T previously set
+func Set[T any](ctx context.Context, value T) context.Context {
+	return SetNamed(ctx, "", value)
+}
+
+// Get returns the value of type T held by ctx, and whether it holds one
+func Get[T any](ctx context.Context) (T, bool) {
+	return GetNamed[T](ctx, "")
+}
+
+// MustGet returns the value of type T held by ctx. It panics if ctx does not hold one.
+func MustGet[T any](ctx context.Context) T {
+	return MustGetNamed[T](ctx, "")
+}
+
+// SetNamed returns a copy of ctx holding the value of type T under name, so that several values of the same type
+// can be held, e.g. the deployments of a frontend and of a backend
+func SetNamed[T any](ctx context.Context, name string, value T) context.Context {
+	return context.WithValue(ctx, key[T]{name: name}, value)
+}
+
+// GetNamed returns the value of type T held by ctx under name, and whether it holds one
+func GetNamed[T any](ctx context.Context, name string) (T, bool) {
+	value, ok := ctx.Value(key[T]{name: name}).(T)
+	return value, ok
+}
+
+// MustGetNamed returns the value of type T held by ctx under name. It panics if ctx does not hold one.
+func MustGetNamed[T any](ctx context.Context, name string) T {
+	value, ok := GetNamed[T](ctx, name)
+	if !ok {
+		var zero T
+		if name == "" {
+			panic(fmt.Sprintf("no value of type %T in context", zero))
+		}
+		panic(fmt.Sprintf("no value of type %T named %q in context", zero, name))
+	}
+	return value
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ctxvalue
+
+import (
+	"context"
+	"testing"
+)
+
+type deployment struct {
+	name string
+}
+
+func TestSetGet(t *testing.T) {
+	ctx := Set(context.Background(), &deployment{name: "default"})
+	ctx = SetNamed(ctx, "frontend", &deployment{name: "frontend"})
+	ctx = Set(ctx, "value")
+
+	if d, ok := Get[*deployment](ctx); !ok || d.name != "default" {
+		t.Errorf("unexpected deployment: %v, %t", d, ok)
+	}
+	if d := MustGetNamed[*deployment](ctx, "frontend"); d.name != "frontend" {
+		t.Errorf("unexpected frontend deployment: %v", d)
+	}
+	if s := MustGet[string](ctx); s != "value" {
+		t.Errorf("unexpected string: %s", s)
+	}
+	if _, ok := GetNamed[*deployment](ctx, "backend"); ok {
+		t.Error("expected no backend deployment")
+	}
+	if _, ok := Get[deployment](ctx); ok {
+		t.Error("expected no value of a type that was not set")
+	}
+
+	ctx = Set(ctx, &deployment{name: "replaced"})
+	if d := MustGet[*deployment](ctx); d.name != "replaced" {
+		t.Errorf("expected the deployment to be replaced, got %v", d)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected MustGet to panic for a missing value")
+		}
+	}()
+	_ = MustGet[int](ctx)
+}
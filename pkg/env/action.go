@@ -19,6 +19,8 @@ package env
 import (
 	"context"
 	"fmt"
+	"runtime/debug"
+	"sync"
 	"testing"
 
 	klog "k8s.io/klog/v2"
@@ -33,8 +35,12 @@ const (
 	roleBeforeTest
 	roleBeforeFeature
 	roleAfterFeature
+	roleBeforeAssessment
+	roleAfterAssessment
 	roleAfterTest
 	roleFinish
+	roleBeforeSuite
+	roleAfterSuite
 )
 
 func (r actionRole) String() string {
@@ -47,10 +53,18 @@ func (r actionRole) String() string {
 		return "BeforeEachFeature"
 	case roleAfterFeature:
 		return "AfterEachFeature"
+	case roleBeforeAssessment:
+		return "BeforeEachAssessment"
+	case roleAfterAssessment:
+		return "AfterEachAssessment"
 	case roleAfterTest:
 		return "AfterEachTest"
 	case roleFinish:
 		return "Finish"
+	case roleBeforeSuite:
+		return "BeforeSuite"
+	case roleAfterSuite:
+		return "AfterSuite"
 	default:
 		panic("unknown role") // this should never happen
 	}
@@ -68,11 +82,20 @@ type action struct {
 
 	// testFuncs store the TestEnvFunc for before/after feature.
 	testFuncs []types.TestEnvFunc
+
+	// assessmentFuncs store the AssessmentEnvFunc for before/after assessment.
+	assessmentFuncs []types.AssessmentEnvFunc
+
+	// once guards the funcs of the BeforeSuite and AfterSuite actions, so that they are run only once by the
+	// environments inheriting or composing the action.
+	once *sync.Once
 }
 
 // runWithT will run the action and inject *testing.T into the callback function.
-func (a *action) runWithT(ctx context.Context, cfg *envconf.Config, t *testing.T) (context.Context, error) {
+func (a *action) runWithT(ctx context.Context, cfg *envconf.Config, t *testing.T) (out context.Context, err error) {
 	t.Helper()
+	out = ctx
+	defer a.recoverPanic(cfg, &err)
 	switch a.role {
 	case roleBeforeTest, roleAfterTest:
 		if cfg.DryRunMode() {
@@ -84,22 +107,23 @@ func (a *action) runWithT(ctx context.Context, cfg *envconf.Config, t *testing.T
 				continue
 			}
 
-			var err error
-			ctx, err = f(ctx, cfg, t)
+			out, err = f(out, cfg, t)
 			if err != nil {
-				return ctx, err
+				return out, err
 			}
 		}
 	default:
-		return ctx, fmt.Errorf("runWithT() is only valid for actions roleBeforeTest and roleAfterTest")
+		return out, fmt.Errorf("runWithT() is only valid for actions roleBeforeTest and roleAfterTest")
 	}
 
-	return ctx, nil
+	return out, nil
 }
 
 // runWithFeature will run the action and inject a FeatureInfo object into the callback function.
-func (a *action) runWithFeature(ctx context.Context, cfg *envconf.Config, t *testing.T, fi types.Feature) (context.Context, error) {
+func (a *action) runWithFeature(ctx context.Context, cfg *envconf.Config, t *testing.T, fi types.Feature) (out context.Context, err error) {
 	t.Helper()
+	out = ctx
+	defer a.recoverPanic(cfg, &err)
 	switch a.role {
 	case roleBeforeFeature, roleAfterFeature:
 		if cfg.DryRunMode() {
@@ -111,19 +135,48 @@ func (a *action) runWithFeature(ctx context.Context, cfg *envconf.Config, t *tes
 				continue
 			}
 
-			var err error
-			ctx, err = f(ctx, cfg, t, fi)
+			out, err = f(out, cfg, t, fi)
 			if err != nil {
-				return ctx, err
+				return out, err
 			}
 		}
 	default:
-		return ctx, fmt.Errorf("runWithFeature() is only valid for actions roleBeforeFeature and roleAfterFeature")
+		return out, fmt.Errorf("runWithFeature() is only valid for actions roleBeforeFeature and roleAfterFeature")
 	}
-	return ctx, nil
+	return out, nil
 }
 
-func (a *action) run(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+// runWithAssessment will run the action and inject the feature and the name of the assessment into the callback
+// function.
+func (a *action) runWithAssessment(ctx context.Context, cfg *envconf.Config, t *testing.T, fi types.Feature, assessment string) (out context.Context, err error) {
+	t.Helper()
+	out = ctx
+	defer a.recoverPanic(cfg, &err)
+	switch a.role {
+	case roleBeforeAssessment, roleAfterAssessment:
+		if cfg.DryRunMode() {
+			klog.V(2).Info("Skipping execution of roleBeforeAssessment and roleAfterAssessment due to framework being in dry-run mode")
+			return ctx, nil
+		}
+		for _, f := range a.assessmentFuncs {
+			if f == nil {
+				continue
+			}
+
+			out, err = f(out, cfg, t, fi, assessment)
+			if err != nil {
+				return out, err
+			}
+		}
+	default:
+		return out, fmt.Errorf("runWithAssessment() is only valid for actions roleBeforeAssessment and roleAfterAssessment")
+	}
+	return out, nil
+}
+
+func (a *action) run(ctx context.Context, cfg *envconf.Config) (out context.Context, err error) {
+	out = ctx
+	defer a.recoverPanic(cfg, &err)
 	if cfg.DryRunMode() {
 		klog.V(2).InfoS("Skipping processing of action due to framework being in dry-run mode")
 		return ctx, nil
@@ -133,12 +186,79 @@ func (a *action) run(ctx context.Context, cfg *envconf.Config) (context.Context,
 			continue
 		}
 
-		var err error
-		ctx, err = f(ctx, cfg)
+		out, err = a.runFunc(out, cfg, f)
 		if err != nil {
-			return ctx, err
+			return out, err
 		}
 	}
 
-	return ctx, nil
+	return out, nil
+}
+
+// runSuite runs the funcs of a BeforeSuite or AfterSuite action, unless they were already run by an environment
+// sharing the action. Unlike run, all the funcs are run even if some of them fail, and the error of each failed
+// func is returned. It also returns whether the funcs were run by this call.
+func (a *action) runSuite(ctx context.Context, cfg *envconf.Config) (out context.Context, ran bool, errs []error) {
+	out = ctx
+	a.once.Do(func() {
+		ran = true
+		if cfg.DryRunMode() {
+			klog.V(2).InfoS("Skipping processing of suite action due to framework being in dry-run mode", "action", a.role)
+			return
+		}
+		for _, f := range a.funcs {
+			if f == nil {
+				continue
+			}
+			var err error
+			if out, err = a.runSuiteFunc(out, cfg, f); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", a.role, funcName(f), err))
+			}
+		}
+	})
+	return out, ran, errs
+}
+
+// runSuiteFunc runs a func of a BeforeSuite or AfterSuite action, recovering from its panic so that the next funcs
+// still run. The context is left unchanged if the func does not return one.
+func (a *action) runSuiteFunc(ctx context.Context, cfg *envconf.Config, f types.EnvFunc) (out context.Context, err error) {
+	out = ctx
+	defer a.recoverPanic(cfg, &err)
+	if out, err = a.runFunc(ctx, cfg, f); out == nil {
+		out = ctx
+	}
+	return out, err
+}
+
+// runFunc runs the setup or finish func of the action within a span named after the role of the action
+func (a *action) runFunc(ctx context.Context, cfg *envconf.Config, f types.EnvFunc) (out context.Context, err error) {
+	spanCtx, span := startSpan(ctx, a.role.String(), attrFunc.String(funcName(f)), attrNamespace.String(cfg.Namespace()))
+	returned := false
+	defer func() {
+		switch {
+		case !returned:
+			endSpan(span, statusFailed, "panic")
+		case err != nil:
+			endSpan(span, statusFailed, err.Error())
+		default:
+			endSpan(span, statusPassed, "")
+		}
+		if out != nil {
+			out = restoreSpan(out, ctx)
+		}
+	}()
+	out, err = f(spanCtx, cfg)
+	returned = true
+	return out, err
+}
+
+// recoverPanic converts a panic of the funcs of the action into an error with the stack of the panic, so that the
+// test and the Finish funcs still run, unless the panic recovery is disabled by the configuration
+func (a *action) recoverPanic(cfg *envconf.Config, err *error) {
+	if cfg.DisableGracefulTeardown() {
+		return
+	}
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+	}
 }
@@ -131,6 +131,16 @@ func TestActionRole_String(t *testing.T) {
 			r:    roleAfterFeature,
 			want: "AfterEachFeature",
 		},
+		{
+			name: "RoleBeforeAssessment",
+			r:    roleBeforeAssessment,
+			want: "BeforeEachAssessment",
+		},
+		{
+			name: "RoleAfterAssessment",
+			r:    roleAfterAssessment,
+			want: "AfterEachAssessment",
+		},
 		{
 			name: "RoleAfterTest",
 			r:    roleAfterTest,
@@ -141,6 +151,16 @@ func TestActionRole_String(t *testing.T) {
 			r:    roleFinish,
 			want: "Finish",
 		},
+		{
+			name: "RoleBeforeSuite",
+			r:    roleBeforeSuite,
+			want: "BeforeSuite",
+		},
+		{
+			name: "RoleAfterSuite",
+			r:    roleAfterSuite,
+			want: "AfterSuite",
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
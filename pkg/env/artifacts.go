@@ -0,0 +1,306 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+)
+
+// artifactsTimeout is the time given to the collection of the artifacts of a failed assessment, regardless of the
+// deadline of the assessment itself
+const artifactsTimeout = 2 * time.Minute
+
+// unsafeArtifactsPathChars matches the characters of the test names that are not used in the artifact paths
+var unsafeArtifactsPathChars = regexp.MustCompile(`[^A-Za-z0-9_./-]`)
+
+// collectArtifacts dumps the objects, events, pod descriptions and container logs of the namespace of the
+// environment to the artifacts directory when the assessment run by t has failed. The artifacts of the assessment
+// are written to a directory named after its test, e.g. <artifacts-dir>/TestX/feature/assessment. Errors are logged
+// with t as the artifacts are only collected to help debugging the failure.
+func (e *testEnv) collectArtifacts(ctx context.Context, t *testing.T) {
+	t.Helper()
+	namespace := e.cfg.Namespace()
+	if e.cfg.ArtifactsDir() == "" || !t.Failed() || e.cfg.DryRunMode() {
+		return
+	}
+	if namespace == "" || (e.cfg.GetClient() == nil && e.cfg.KubeconfigFile() == "") {
+		t.Logf("artifacts not collected: no namespace or cluster configured")
+		return
+	}
+	client, err := e.cfg.NewClient()
+	if err != nil {
+		t.Logf("artifacts not collected: %s", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), artifactsTimeout)
+	defer cancel()
+
+	dir := filepath.Join(e.cfg.ArtifactsDir(), filepath.FromSlash(unsafeArtifactsPathChars.ReplaceAllString(t.Name(), "_")))
+	collector := &artifactsCollector{client: client, namespace: namespace, dir: dir}
+	for _, err := range collector.collect(ctx) {
+		t.Logf("artifacts collection: %s", err)
+	}
+	t.Logf("artifacts of namespace %s collected in %s", namespace, dir)
+}
+
+// CollectArtifacts dumps the objects, events, pod descriptions and container logs of the namespace to dir, the same
+// way they are collected when an assessment fails, see envconf.Config.WithArtifactsDir. The collection carries on
+// after an error to gather as many artifacts as possible, and the errors met along the way are returned joined.
+func CollectArtifacts(ctx context.Context, client klient.Client, namespace, dir string) error {
+	collector := &artifactsCollector{client: client, namespace: namespace, dir: dir}
+	return errors.Join(collector.collect(ctx)...)
+}
+
+type artifactsCollector struct {
+	client    klient.Client
+	namespace string
+	dir       string
+}
+
+// collect dumps the artifacts of the namespace and returns the errors met along the way, the collection carries on
+// after an error to gather as many artifacts as possible
+func (c *artifactsCollector) collect(ctx context.Context) []error {
+	var errs []error
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return []error{err}
+	}
+	errs = append(errs, c.collectObjects(ctx)...)
+
+	var events corev1.EventList
+	if err := c.client.Resources(c.namespace).List(ctx, &events); err != nil {
+		errs = append(errs, fmt.Errorf("list events: %w", err))
+	} else if err := c.write("events.txt", formatEvents(events.Items)); err != nil {
+		errs = append(errs, err)
+	}
+
+	var pods corev1.PodList
+	if err := c.client.Resources(c.namespace).List(ctx, &pods); err != nil {
+		return append(errs, fmt.Errorf("list pods: %w", err))
+	}
+	for i := range pods.Items {
+		errs = append(errs, c.collectPod(ctx, &pods.Items[i], events.Items)...)
+	}
+	return errs
+}
+
+// collectObjects dumps the objects of every kind of namespaced resource that can be listed, but secrets, to
+// objects/<resource>.<group>.yaml
+func (c *artifactsCollector) collectObjects(ctx context.Context) []error {
+	dc, err := discovery.NewDiscoveryClientForConfig(c.client.RESTConfig())
+	if err != nil {
+		return []error{err}
+	}
+	// the partial list of resources is kept when some API groups cannot be discovered
+	lists, err := discovery.ServerPreferredNamespacedResources(dc)
+	var errs []error
+	if err != nil {
+		errs = append(errs, fmt.Errorf("discover resources: %w", err))
+	}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if resource.Name == "secrets" || !hasVerb(resource.Verbs, "list") {
+				continue
+			}
+			objects := &unstructured.UnstructuredList{}
+			objects.SetGroupVersionKind(gv.WithKind(resource.Kind + "List"))
+			if err := c.client.Resources(c.namespace).List(ctx, objects); err != nil {
+				errs = append(errs, fmt.Errorf("list %s: %w", resource.Name, err))
+				continue
+			}
+			if len(objects.Items) == 0 {
+				continue
+			}
+			var buf bytes.Buffer
+			for i := range objects.Items {
+				objects.Items[i].SetManagedFields(nil)
+				data, err := sigsyaml.Marshal(objects.Items[i].Object)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				buf.WriteString("---\n")
+				buf.Write(data)
+			}
+			name := resource.Name
+			if gv.Group != "" {
+				name += "." + gv.Group
+			}
+			if err := c.write(filepath.Join("objects", name+".yaml"), buf.Bytes()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// collectPod writes a description of the pod to pods/<pod>.txt, and the logs of its containers, including the ones
+// of their previous instance when they restarted, to pods/<pod>/<container>.log
+func (c *artifactsCollector) collectPod(ctx context.Context, pod *corev1.Pod, events []corev1.Event) []error {
+	var errs []error
+	if err := c.write(filepath.Join("pods", pod.Name+".txt"), describePod(pod, events)); err != nil {
+		errs = append(errs, err)
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Waiting != nil && status.LastTerminationState.Terminated == nil {
+			// the container has not started yet, there are no logs to get
+			continue
+		}
+		logs, err := c.client.Resources().GetLogs(ctx, c.namespace, pod.Name, resources.WithLogContainer(status.Name))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get logs of %s/%s: %w", pod.Name, status.Name, err))
+		} else if err := c.write(filepath.Join("pods", pod.Name, status.Name+".log"), logs); err != nil {
+			errs = append(errs, err)
+		}
+		if status.RestartCount == 0 {
+			continue
+		}
+		logs, err = c.client.Resources().GetLogs(ctx, c.namespace, pod.Name, resources.WithLogContainer(status.Name), resources.WithPreviousLogs())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get previous logs of %s/%s: %w", pod.Name, status.Name, err))
+		} else if err := c.write(filepath.Join("pods", pod.Name, status.Name+".previous.log"), logs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (c *artifactsCollector) write(name string, data []byte) error {
+	path := filepath.Join(c.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func hasVerb(verbs []string, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// formatEvents lists the events sorted by the time they were last seen, one per line
+func formatEvents(events []corev1.Event) []byte {
+	events = append([]corev1.Event{}, events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	var buf bytes.Buffer
+	for _, event := range events {
+		fmt.Fprintf(&buf, "%s\t%s\t%s\t%s/%s\t%s\n", eventTime(event).UTC().Format(time.RFC3339), event.Type, event.Reason,
+			strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name, strings.TrimSpace(event.Message))
+	}
+	return buf.Bytes()
+}
+
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// describePod returns a summary of the status of the pod and of its containers, along with the events of the pod,
+// similar to the output of kubectl describe
+func describePod(pod *corev1.Pod, events []corev1.Event) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Name:       %s\n", pod.Name)
+	fmt.Fprintf(&buf, "Namespace:  %s\n", pod.Namespace)
+	fmt.Fprintf(&buf, "Node:       %s\n", pod.Spec.NodeName)
+	fmt.Fprintf(&buf, "Phase:      %s\n", pod.Status.Phase)
+	if pod.Status.Reason != "" || pod.Status.Message != "" {
+		fmt.Fprintf(&buf, "Reason:     %s: %s\n", pod.Status.Reason, pod.Status.Message)
+	}
+	buf.WriteString("Conditions:\n")
+	for _, condition := range pod.Status.Conditions {
+		fmt.Fprintf(&buf, "  %s=%s", condition.Type, condition.Status)
+		if condition.Reason != "" || condition.Message != "" {
+			fmt.Fprintf(&buf, " (%s: %s)", condition.Reason, condition.Message)
+		}
+		buf.WriteString("\n")
+	}
+	images := map[string]string{}
+	for _, container := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		images[container.Name] = container.Image
+	}
+	buf.WriteString("Containers:\n")
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		fmt.Fprintf(&buf, "  %s:\n    Image:     %s\n    Ready:     %t\n    Restarts:  %d\n    State:     %s\n",
+			status.Name, images[status.Name], status.Ready, status.RestartCount, containerState(status.State))
+		if status.LastTerminationState.Terminated != nil {
+			fmt.Fprintf(&buf, "    Last:      %s\n", containerState(status.LastTerminationState))
+		}
+	}
+	buf.WriteString("Events:\n")
+	var podEvents []corev1.Event
+	for _, event := range events {
+		if event.InvolvedObject.Kind == "Pod" && event.InvolvedObject.Name == pod.Name {
+			podEvents = append(podEvents, event)
+		}
+	}
+	for _, line := range strings.SplitAfter(string(formatEvents(podEvents)), "\n") {
+		if line != "" {
+			buf.WriteString("  " + line)
+		}
+	}
+	return buf.Bytes()
+}
+
+func containerState(state corev1.ContainerState) string {
+	switch {
+	case state.Waiting != nil:
+		return fmt.Sprintf("Waiting (%s: %s)", state.Waiting.Reason, state.Waiting.Message)
+	case state.Running != nil:
+		return fmt.Sprintf("Running since %s", state.Running.StartedAt.UTC().Format(time.RFC3339))
+	case state.Terminated != nil:
+		return fmt.Sprintf("Terminated (%s, exit code %d: %s)", state.Terminated.Reason, state.Terminated.ExitCode, state.Terminated.Message)
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDescribePod(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "artifacts"},
+		Spec: corev1.PodSpec{
+			NodeName:   "node-1",
+			Containers: []corev1.Container{{Name: "nginx", Image: "nginx:1.27"}},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse, Reason: "ContainersNotReady", Message: "containers with unready status: [nginx]"}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:                 "nginx",
+				RestartCount:         2,
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+			}},
+		},
+	}
+	events := []corev1.Event{
+		{
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			LastTimestamp:  metav1.NewTime(now.Add(time.Minute)),
+		},
+		{
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"},
+			Type:           corev1.EventTypeNormal,
+			Reason:         "Scheduled",
+			Message:        "Successfully assigned artifacts/web to node-1",
+			LastTimestamp:  metav1.NewTime(now),
+		},
+		{
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "other"},
+			Type:           corev1.EventTypeNormal,
+			Reason:         "Scheduled",
+			LastTimestamp:  metav1.NewTime(now),
+		},
+	}
+
+	description := string(describePod(pod, events))
+	for _, expected := range []string{
+		"Node:       node-1",
+		"Ready=False (ContainersNotReady: containers with unready status: [nginx])",
+		"Image:     nginx:1.27",
+		"Restarts:  2",
+		"State:     Waiting (CrashLoopBackOff: )",
+		"Last:      Terminated (Error, exit code 1: )",
+	} {
+		if !strings.Contains(description, expected) {
+			t.Errorf("expected description to contain %q, got:\n%s", expected, description)
+		}
+	}
+	scheduled := strings.Index(description, "Scheduled")
+	backOff := strings.Index(description, "BackOff\t")
+	if scheduled < 0 || backOff < 0 || scheduled > backOff {
+		t.Errorf("expected the events of the pod to be sorted by time, got:\n%s", description)
+	}
+	if strings.Contains(description, "pod/other") {
+		t.Errorf("expected only the events of the pod to be described, got:\n%s", description)
+	}
+}
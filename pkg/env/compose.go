@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/e2e-framework/pkg/types"
+)
+
+// Compose returns a new environment extending the environments, e.g. a base environment with the Setup and Finish
+// funcs shared by the test suites of several packages, along with an environment with the funcs specific to a
+// suite. The funcs of the environments, and the funcs registered with the returned environment, are run in a
+// deterministic order:
+//
+//   - the BeforeSuite, Setup, BeforeEach... funcs are run environment by environment in the order of envs, followed
+//     by the funcs registered with the returned environment;
+//   - the AfterEach..., Finish and AfterSuite funcs are run in the reverse order of the environments, so that the
+//     funcs of the returned environment run first and the funcs of the first environment, such as a cluster teardown,
+//     run last.
+//
+// An environment included more than once, such as a base environment shared by two of envs which were composed
+// with it, is only run once, at its first position. The funcs of each environment are looked up when they are run,
+// so that the funcs registered with the environments after the composition are run too.
+//
+// The returned environment uses the context and the configuration of the last environment, which usually is the
+// most specific one. It panics if one of envs was not created by this package.
+func Compose(envs ...types.Environment) types.Environment {
+	if len(envs) == 0 {
+		return newTestEnv()
+	}
+	var bases []*testEnv
+	seen := map[*testEnv]bool{}
+	add := func(e *testEnv) {
+		if !seen[e] {
+			seen[e] = true
+			bases = append(bases, e)
+		}
+	}
+	var last *testEnv
+	for _, env := range envs {
+		e, ok := env.(*testEnv)
+		if !ok {
+			panic(fmt.Sprintf("cannot compose environment of type %T", env))
+		}
+		for _, base := range e.bases {
+			add(base)
+		}
+		add(e)
+		last = e
+	}
+	return &testEnv{
+		ctx:      last.ctx,
+		cfg:      last.cfg,
+		bases:    bases,
+		reporter: newReporter(),
+	}
+}
+
+// layers returns the environments whose actions of the role are run, in the order they are run
+func (e *testEnv) layers(r actionRole) []*testEnv {
+	layers := append(append([]*testEnv{}, e.bases...), e)
+	switch r {
+	case roleAfterAssessment, roleAfterFeature, roleAfterTest, roleFinish, roleAfterSuite:
+		for i, j := 0, len(layers)-1; i < j; i, j = i+1, j-1 {
+			layers[i], layers[j] = layers[j], layers[i]
+		}
+	}
+	return layers
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+func TestCompose(t *testing.T) {
+	var ran []string
+	record := func(name string) Func {
+		return func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+			ran = append(ran, name)
+			return ctx, nil
+		}
+	}
+	base := New().Setup(record("setup:cluster")).Finish(record("finish:cluster"))
+	crds := Compose(base).Setup(record("setup:crds")).Finish(record("finish:crds"))
+	suite := New().Setup(record("setup:suite")).Finish(record("finish:suite"))
+	cfg := envconf.New().WithNamespace("suite")
+	specific := NewWithConfig(cfg)
+
+	composed := Compose(base, crds, suite, specific).(*testEnv)
+	composed.Setup(record("setup:composed")).Finish(record("finish:composed"))
+	if composed.cfg != cfg {
+		t.Error("expected the composed environment to use the configuration of the last environment")
+	}
+
+	run := func(actions []action) {
+		for _, a := range actions {
+			if _, err := a.run(context.Background(), composed.cfg); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	run(composed.getSetupActions())
+	run(composed.getFinishActions())
+	expected := []string{
+		"setup:cluster", "setup:crds", "setup:suite", "setup:composed",
+		"finish:composed", "finish:suite", "finish:crds", "finish:cluster",
+	}
+	if !reflect.DeepEqual(ran, expected) {
+		t.Errorf("expected the actions to be run in the order:\n%v\ngot:\n%v", expected, ran)
+	}
+}
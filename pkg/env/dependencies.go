@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"sigs.k8s.io/e2e-framework/pkg/types"
+)
+
+// featureDependencies tracks the features of an Env.Test call that are done, so that the features depending on
+// other features are only run once the features they depend on have passed
+type featureDependencies struct {
+	reporter *reporter
+	mu       sync.Mutex
+	// pending is keyed by the names of the features of the call
+	pending map[string]*pendingFeature
+}
+
+type pendingFeature struct {
+	remaining int
+	done      chan struct{}
+}
+
+// newFeatureDependencies returns the dependencies of the features, along with the features sorted so that they
+// come after the features they depend on, keeping their order otherwise. A dependency cycle fails the test, the
+// features of the cycle are then kept in their order and skipped as their dependencies are not met.
+func newFeatureDependencies(t *testing.T, r *reporter, testFeatures []types.Feature) (*featureDependencies, []types.Feature) {
+	t.Helper()
+	d := &featureDependencies{reporter: r, pending: map[string]*pendingFeature{}}
+	for _, f := range testFeatures {
+		p, ok := d.pending[f.Name()]
+		if !ok {
+			p = &pendingFeature{done: make(chan struct{})}
+			d.pending[f.Name()] = p
+		}
+		p.remaining++
+	}
+
+	remaining := map[string]int{}
+	for name, p := range d.pending {
+		remaining[name] = p.remaining
+	}
+	sorted := make([]types.Feature, 0, len(testFeatures))
+	placed := make([]bool, len(testFeatures))
+	for len(sorted) < len(testFeatures) {
+		progress := false
+		for i, f := range testFeatures {
+			if placed[i] || !dependenciesPlaced(f, remaining) {
+				continue
+			}
+			placed[i] = true
+			remaining[f.Name()]--
+			sorted = append(sorted, f)
+			progress = true
+			break
+		}
+		if progress {
+			continue
+		}
+		var cycle []string
+		for i, f := range testFeatures {
+			if !placed[i] {
+				cycle = append(cycle, f.Name())
+				sorted = append(sorted, f)
+			}
+		}
+		t.Errorf("dependency cycle between features: %s", strings.Join(cycle, ", "))
+	}
+	return d, sorted
+}
+
+// dependenciesPlaced checks whether the features of the call f depends on have all been placed before it
+func dependenciesPlaced(f types.Feature, remaining map[string]int) bool {
+	for _, dep := range dependencies(f) {
+		if remaining[dep] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func dependencies(f types.Feature) []string {
+	if df, ok := f.(types.DependentFeature); ok {
+		return df.Dependencies()
+	}
+	return nil
+}
+
+// run runs the feature with run, or skips it when its dependencies are not met. When wait is set, the features of
+// the call the feature depends on are waited for, which must only be done when the features run concurrently.
+func (d *featureDependencies) run(t *testing.T, featName string, f types.Feature, wait bool, run func()) {
+	t.Helper()
+	defer d.finished(f)
+	if wait {
+		for _, dep := range dependencies(f) {
+			if p, ok := d.pending[dep]; ok {
+				<-p.done
+			}
+		}
+	}
+	if skip, message := d.check(t, f); skip {
+		skipFeature(t, d.reporter, featName, message)
+		return
+	}
+	run()
+}
+
+// check returns whether the feature must be skipped as one of its dependencies has not passed, and why
+func (d *featureDependencies) check(t *testing.T, f types.Feature) (skip bool, message string) {
+	for _, dep := range dependencies(f) {
+		if p, ok := d.pending[dep]; ok {
+			select {
+			case <-p.done:
+			default:
+				return true, fmt.Sprintf("Skipping feature %q: prerequisite feature %q has not been run", f.Name(), dep)
+			}
+		}
+		result, ok := d.reporter.lookup(t.Name(), dep)
+		if !ok {
+			return true, fmt.Sprintf("Skipping feature %q: prerequisite feature %q has not been run", f.Name(), dep)
+		}
+		switch result.Status {
+		case statusPassed, statusFlaky:
+		case statusSkipped:
+			return true, fmt.Sprintf("Skipping feature %q: prerequisite feature %q was skipped", f.Name(), dep)
+		default:
+			return true, fmt.Sprintf("Skipping feature %q: prerequisite feature %q failed", f.Name(), dep)
+		}
+	}
+	return false, ""
+}
+
+func (d *featureDependencies) finished(f types.Feature) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	p := d.pending[f.Name()]
+	p.remaining--
+	if p.remaining == 0 {
+		close(p.done)
+	}
+}
@@ -20,16 +20,23 @@ package env
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"runtime/debug"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	klog "k8s.io/klog/v2"
 
-	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/e2e-framework/pkg/featuregate"
 	"sigs.k8s.io/e2e-framework/pkg/features"
@@ -37,16 +44,25 @@ import (
 )
 
 type (
-	Environment = types.Environment
-	Func        = types.EnvFunc
-	FeatureFunc = types.FeatureEnvFunc
-	TestFunc    = types.TestEnvFunc
+	Environment    = types.Environment
+	Func           = types.EnvFunc
+	FeatureFunc    = types.FeatureEnvFunc
+	AssessmentFunc = types.AssessmentEnvFunc
+	TestFunc       = types.TestEnvFunc
+	FeatureResult  = types.FeatureResult
+	StepResult     = types.StepResult
 )
 
 type testEnv struct {
 	ctx     context.Context
 	cfg     *envconf.Config
 	actions []action
+	// bases are the environments the env was composed of, see Compose
+	bases []*testEnv
+	// reporter records the results of the features run, it is shared with the child environments
+	reporter *reporter
+	// lastFailed holds the features that failed in the last run when only those are to be rerun
+	lastFailed map[rerunFeature]bool
 }
 
 // New creates a test environment with no config attached.
@@ -100,20 +116,22 @@ func NewWithContext(ctx context.Context, cfg *envconf.Config) (types.Environment
 	if cfg == nil {
 		return nil, fmt.Errorf("environment config is nil")
 	}
-	return &testEnv{ctx: ctx, cfg: cfg}, nil
+	return &testEnv{ctx: ctx, cfg: cfg, reporter: newReporter()}, nil
 }
 
 func newTestEnv() *testEnv {
 	return &testEnv{
-		ctx: context.Background(),
-		cfg: envconf.New(),
+		ctx:      context.Background(),
+		cfg:      envconf.New(),
+		reporter: newReporter(),
 	}
 }
 
 func newTestEnvWithParallel() *testEnv {
 	return &testEnv{
-		ctx: context.Background(),
-		cfg: envconf.New().WithParallelTestEnabled(),
+		ctx:      context.Background(),
+		cfg:      envconf.New().WithParallelTestEnabled(),
+		reporter: newReporter(),
 	}
 }
 
@@ -126,9 +144,12 @@ type ctxName string
 func newChildTestEnv(e *testEnv) *testEnv {
 	childCtx := context.WithValue(e.ctx, ctxName("parent"), fmt.Sprintf("%s", e.ctx))
 	return &testEnv{
-		ctx:     childCtx,
-		cfg:     e.deepCopyConfig(),
-		actions: append([]action{}, e.actions...),
+		ctx:        childCtx,
+		cfg:        e.deepCopyConfig(),
+		actions:    append([]action{}, e.actions...),
+		bases:      e.bases,
+		reporter:   e.reporter,
+		lastFailed: e.lastFailed,
 	}
 }
 
@@ -139,8 +160,11 @@ func (e *testEnv) WithContext(ctx context.Context) types.Environment {
 		panic("nil context") // this should never happen
 	}
 	env := &testEnv{
-		ctx: ctx,
-		cfg: e.cfg,
+		ctx:        ctx,
+		cfg:        e.cfg,
+		bases:      e.bases,
+		reporter:   e.reporter,
+		lastFailed: e.lastFailed,
 	}
 	env.actions = append(env.actions, e.actions...)
 	return env
@@ -156,6 +180,22 @@ func (e *testEnv) Setup(funcs ...Func) types.Environment {
 	return e
 }
 
+// BeforeSuite registers funcs that are executed once, before the
+// Setup operations. Unlike the Setup operations, all the funcs are
+// run even if some of them fail, and the error of each of them is
+// reported. The features are not tested if any of them fails.
+//
+// The funcs are shared with the child and composed environments, and
+// are run only once within the test binary even if several of these
+// environments are run.
+func (e *testEnv) BeforeSuite(funcs ...Func) types.Environment {
+	if len(funcs) == 0 {
+		return e
+	}
+	e.actions = append(e.actions, action{role: roleBeforeSuite, funcs: funcs, once: &sync.Once{}})
+	return e
+}
+
 // BeforeEachTest registers environment funcs that are executed
 // before each Env.Test(...)
 func (e *testEnv) BeforeEachTest(funcs ...types.TestEnvFunc) types.Environment {
@@ -186,6 +226,30 @@ func (e *testEnv) AfterEachFeature(funcs ...FeatureFunc) types.Environment {
 	return e
 }
 
+// BeforeEachAssessment registers functions that are executed before each
+// assessment of the features tested during an env.Test call. They are
+// provided with the feature and the name of the assessment, and run as
+// part of the test of the assessment.
+func (e *testEnv) BeforeEachAssessment(funcs ...AssessmentFunc) types.Environment {
+	if len(funcs) == 0 {
+		return e
+	}
+	e.actions = append(e.actions, action{role: roleBeforeAssessment, assessmentFuncs: funcs})
+	return e
+}
+
+// AfterEachAssessment registers functions that are executed after each
+// assessment of the features tested during an env.Test call, even when
+// the assessment failed. They are provided with the feature and the name
+// of the assessment, and run as part of the test of the assessment.
+func (e *testEnv) AfterEachAssessment(funcs ...AssessmentFunc) types.Environment {
+	if len(funcs) == 0 {
+		return e
+	}
+	e.actions = append(e.actions, action{role: roleAfterAssessment, assessmentFuncs: funcs})
+	return e
+}
+
 // AfterEachTest registers environment funcs that are executed
 // after each Env.Test(...).
 func (e *testEnv) AfterEachTest(funcs ...types.TestEnvFunc) types.Environment {
@@ -219,6 +283,10 @@ func (e *testEnv) processTestActions(ctx context.Context, t *testing.T, actions
 	return out
 }
 
+// errDeadlineExceeded is the cause of the cancellation of the run context once the deadline of the test suite is
+// exceeded
+var errDeadlineExceeded = errors.New("test suite deadline exceeded")
+
 // processTestFeature is used to trigger the execution of the actual feature. This function wraps the entire
 // workflow of orchestrating the feature execution be running the action configured by BeforeEachFeature /
 // AfterEachFeature.
@@ -226,8 +294,42 @@ func (e *testEnv) processTestFeature(ctx context.Context, t *testing.T, featureN
 	t.Helper()
 	skipped, message := e.requireFeatureProcessing(feature)
 	if skipped {
+		e.reporter.record(featureResult{Test: t.Name(), Name: featureName, Status: statusSkipped, Message: message})
 		t.Skip(message)
 	}
+	if err := context.Cause(ctx); errors.Is(err, errDeadlineExceeded) {
+		skipFeature(t, e.reporter, featureName, fmt.Sprintf("Skipping feature %q: %s", featureName, err))
+		return ctx
+	}
+	if clusters, ok := e.featureClusters(feature); ok {
+		if len(clusters) == 0 {
+			skipFeature(t, e.reporter, featureName, fmt.Sprintf("Skipping feature %q: no cluster registered", featureName))
+		}
+		for _, cluster := range clusters {
+			clusterName := fmt.Sprintf("%s-%s", featureName, cluster)
+			if !e.cfg.HasCluster(cluster) {
+				skipFeature(t, e.reporter, clusterName, fmt.Sprintf("Skipping feature %q: cluster %q is not registered", featureName, cluster))
+				continue
+			}
+			clusterEnv := newChildTestEnv(e)
+			clusterEnv.cfg.WithCurrentCluster(cluster)
+			ctx = clusterEnv.runFeature(ctx, t, clusterName, feature)
+		}
+		return ctx
+	}
+	return e.runFeature(ctx, t, featureName, feature)
+}
+
+// runFeature runs the feature once it passed the filters, unless its requirements are not met
+func (e *testEnv) runFeature(ctx context.Context, t *testing.T, featureName string, feature types.Feature) context.Context {
+	t.Helper()
+	if met, message := e.requirementsMet(ctx, feature); !met {
+		skipFeature(t, e.reporter, featureName, message)
+		return ctx
+	}
+	if e.cfg.ListMode() {
+		e.listFeature(t, featureName, feature)
+	}
 	// execute beforeEachFeature actions
 	ctx = e.processFeatureActions(ctx, t, feature, e.getBeforeFeatureActions())
 
@@ -238,6 +340,60 @@ func (e *testEnv) processTestFeature(ctx context.Context, t *testing.T, featureN
 	return e.processFeatureActions(ctx, t, feature, e.getAfterFeatureActions())
 }
 
+// processAssessmentActions is used to run a series of assessment actions that were configured as
+// BeforeEachAssessment or AfterEachAssessment
+func (e *testEnv) processAssessmentActions(ctx context.Context, t *testing.T, feature types.Feature, assessment string, actions []action) context.Context {
+	t.Helper()
+	var err error
+	out := ctx
+	for _, action := range actions {
+		out, err = action.runWithAssessment(out, e.cfg, t, deepCopyFeature(feature), assessment)
+		if err != nil {
+			t.Fatalf("%s failure: %s", action.role, err)
+		}
+	}
+	return out
+}
+
+// runAssessmentHooks runs the before or after each assessment hooks of the feature
+func (e *testEnv) runAssessmentHooks(ctx context.Context, t *testing.T, hooks []types.AssessmentHookFunc, assessment string) context.Context {
+	t.Helper()
+	if e.cfg.DryRunMode() {
+		return ctx
+	}
+	for _, hook := range hooks {
+		if out := hook(ctx, t, e.cfg, assessment); out != nil {
+			ctx = out
+		}
+	}
+	return ctx
+}
+
+// requirementsMet checks the requirements of the feature, if any, and returns the reason of the first one that is
+// not met. The requirements are not checked in dry-run mode, as they usually rely on the cluster.
+func (e *testEnv) requirementsMet(ctx context.Context, feature types.Feature) (bool, string) {
+	rf, ok := feature.(types.RequiringFeature)
+	if !ok || e.cfg.DryRunMode() {
+		return true, ""
+	}
+	for _, requirement := range rf.Requirements() {
+		if met, reason := requirement(ctx, e.cfg); !met {
+			return false, fmt.Sprintf("Skipping feature %q: requirement not met: %s", feature.Name(), reason)
+		}
+	}
+	return true, ""
+}
+
+// skipFeature reports the feature as skipped with a subtest named after the feature, so that the other features
+// of the test are still run
+func skipFeature(t *testing.T, r *reporter, featName, message string) {
+	t.Helper()
+	r.record(featureResult{Test: t.Name(), Name: featName, Status: statusSkipped, Message: message})
+	t.Run(featName, func(t *testing.T) {
+		t.Skip(message)
+	})
+}
+
 // processFeatureActions is used to run a series of feature action that were configured as
 // BeforeEachFeature or AfterEachFeature
 func (e *testEnv) processFeatureActions(ctx context.Context, t *testing.T, feature types.Feature, actions []action) context.Context {
@@ -283,25 +439,62 @@ func (e *testEnv) processTests(ctx context.Context, t *testing.T, enableParallel
 
 	ctx = dedicatedTestEnv.processTestActions(ctx, t, beforeTestActions)
 
+	recorded := len(dedicatedTestEnv.reporter.results())
+
+	deps, testFeatures := newFeatureDependencies(t, dedicatedTestEnv.reporter, testFeatures)
+	rerun := rerunSelection(dedicatedTestEnv.lastFailed, t.Name(), testFeatures, dedicatedTestEnv.featureRunNames)
 	var wg sync.WaitGroup
+	var parallelFeatures []func()
 	for i, feature := range testFeatures {
 		featureTestEnv := newChildTestEnv(dedicatedTestEnv)
 		featureCopy := feature
-		featName := feature.Name()
-		if featName == "" {
-			featName = fmt.Sprintf("Feature-%d", i+1)
+		featName := featureName(i, feature)
+		if rerun != nil && !rerun[featName] {
+			deps.run(t, featName, featureCopy, false, func() {
+				skipFeature(t, dedicatedTestEnv.reporter, featName, fmt.Sprintf("Skipping feature %q: passed in the last run", featName))
+			})
+			continue
+		}
+		isolated := dedicatedTestEnv.cfg.NamespacePerFeature()
+		if isf, ok := feature.(types.IsolatedFeature); ok && !isolated {
+			isolated = isf.Isolated()
+		}
+		pf, ok := feature.(types.ParallelFeature)
+		parallel := ok && pf.Parallel()
+		// features marked as parallel always run in a namespace of their own, as they run concurrently
+		isolated = isolated || parallel
+		if parallel && !runInParallel {
+			// features marked as parallel are run once the other features are done
+			parallelFeatures = append(parallelFeatures, func() {
+				deps.run(t, featName, featureCopy, true, func() {
+					_ = featureTestEnv.processIsolatedFeature(ctx, t, featName, featureCopy)
+				})
+			})
+			continue
 		}
 		if runInParallel {
 			wg.Add(1)
 			go func(ctx context.Context, w *sync.WaitGroup, featName string, f types.Feature) {
 				defer w.Done()
-				_ = featureTestEnv.processTestFeature(ctx, t, featName, f)
+				deps.run(t, featName, f, true, func() {
+					if isolated {
+						_ = featureTestEnv.processIsolatedFeature(ctx, t, featName, f)
+					} else {
+						_ = featureTestEnv.processTestFeature(ctx, t, featName, f)
+					}
+				})
 			}(ctx, &wg, featName, featureCopy)
 		} else {
-			ctx = featureTestEnv.processTestFeature(ctx, t, featName, featureCopy)
+			deps.run(t, featName, featureCopy, false, func() {
+				if isolated {
+					ctx = featureTestEnv.processIsolatedFeature(ctx, t, featName, featureCopy)
+				} else {
+					ctx = featureTestEnv.processTestFeature(ctx, t, featName, featureCopy)
+				}
+			})
 			// In case if the feature under test has failed, skip reset of the features
 			// that are part of the same test
-			if featureTestEnv.cfg.FailFast() && t.Failed() {
+			if featureTestEnv.cfg.FailFast() && dedicatedTestEnv.reporter.failed(t.Name(), recorded) {
 				break
 			}
 		}
@@ -309,7 +502,59 @@ func (e *testEnv) processTests(ctx context.Context, t *testing.T, enableParallel
 	if runInParallel {
 		wg.Wait()
 	}
-	return dedicatedTestEnv.processTestActions(ctx, t, afterTestActions)
+	if len(parallelFeatures) > 0 && !(dedicatedTestEnv.cfg.FailFast() && dedicatedTestEnv.reporter.failed(t.Name(), recorded)) {
+		klog.V(4).InfoS("Running test features marked as parallel", "count", len(parallelFeatures))
+		for _, run := range parallelFeatures {
+			wg.Add(1)
+			go func(run func()) {
+				defer wg.Done()
+				run()
+			}(run)
+		}
+		wg.Wait()
+	}
+	ctx = dedicatedTestEnv.processTestActions(ctx, t, afterTestActions)
+	return context.WithValue(ctx, resultsKey{}, dedicatedTestEnv.reporter.testResults(t.Name(), recorded))
+}
+
+// namespaceDeletionTimeout is how long the termination of the namespace of an isolated feature is waited for
+const namespaceDeletionTimeout = 5 * time.Minute
+
+// processIsolatedFeature runs an isolated feature, such as a feature marked as parallel, with a namespace of its own
+// set in the config of the env, so that it does not interfere with the other features. The namespace is created
+// before and deleted after the feature when a cluster is configured, with a client, a kubeconfig file or the
+// in-cluster configuration, waiting for its termination so that the resources of the feature are gone before the
+// next features run. The namespace is only set in the config once it has been created.
+func (e *testEnv) processIsolatedFeature(ctx context.Context, t *testing.T, featureName string, feature types.Feature) context.Context {
+	t.Helper()
+	namespace := envconf.RandomName("feature", 20)
+	if (e.cfg.GetClient() != nil || e.cfg.KubeconfigFile() != "" || e.cfg.InCluster()) && !e.cfg.DryRunMode() {
+		client, err := e.cfg.NewClient()
+		if err != nil {
+			t.Errorf("isolated feature %s: %s", featureName, err)
+			return ctx
+		}
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+		if err := client.Resources().Create(ctx, ns); err != nil {
+			t.Errorf("isolated feature %s: failed to create namespace %s: %s", featureName, namespace, err)
+			return ctx
+		}
+		defer func() {
+			// the namespace is deleted even when the feature ran out of time
+			ctx := context.WithoutCancel(ctx)
+			if err := client.Resources().Delete(ctx, ns); err != nil {
+				t.Errorf("isolated feature %s: failed to delete namespace %s: %s", featureName, namespace, err)
+				return
+			}
+			err := wait.For(conditions.New(client.Resources()).ResourceDeleted(ns),
+				wait.WithContext(ctx), wait.WithInterval(time.Second), wait.WithTimeout(namespaceDeletionTimeout))
+			if err != nil {
+				t.Errorf("isolated feature %s: namespace %s was not terminated: %s", featureName, namespace, err)
+			}
+		}()
+	}
+	e.cfg.WithNamespace(namespace)
+	return e.processTestFeature(ctx, t, featureName, feature)
 }
 
 // TestInParallel executes a series a feature tests from within a
@@ -363,24 +608,164 @@ func (e *testEnv) Finish(funcs ...Func) types.Environment {
 	return e
 }
 
+// AfterSuite registers funcs that are executed once, after the
+// Finish operations, including when the test suite is interrupted.
+// All the funcs are run even if some of them fail, and the error
+// of each of them is reported. The test suite fails if any of them
+// fails.
+//
+// As with BeforeSuite, the funcs are run only once within the test
+// binary even if several environments sharing them are run.
+func (e *testEnv) AfterSuite(funcs ...Func) types.Environment {
+	if len(funcs) == 0 {
+		return e
+	}
+	e.actions = append(e.actions, action{role: roleAfterSuite, funcs: funcs, once: &sync.Once{}})
+	return e
+}
+
+// runSuiteActions runs the BeforeSuite or AfterSuite actions of the environment, logging the error of each func
+// that failed. It returns the errors of all the funcs joined together.
+func (e *testEnv) runSuiteActions(ctx context.Context, r actionRole) (context.Context, error) {
+	var errs []error
+	for _, a := range e.getActionsByRole(r) {
+		start := time.Now()
+		var ran bool
+		var actionErrs []error
+		ctx, ran, actionErrs = a.runSuite(ctx, e.cfg)
+		for _, err := range actionErrs {
+			klog.Errorf("%s failure: %s", r, err)
+		}
+		if ran {
+			e.emitAction(a, start, errors.Join(actionErrs...))
+		}
+		errs = append(errs, actionErrs...)
+	}
+	return ctx, errors.Join(errs...)
+}
+
 // EnvConf returns the test environment's environment configuration
 func (e *testEnv) EnvConf() *envconf.Config {
 	cfg := *e.cfg
 	return &cfg
 }
 
+// Results returns the results of the features run so far by the environment, including once Run is done
+func (e *testEnv) Results() []types.FeatureResult {
+	return e.reporter.results()
+}
+
+// resultsKey is the key of the results of the features of an Env.Test call in the context it returns
+type resultsKey struct{}
+
+// TestResults returns the results of the features run by the Env.Test or Env.TestInParallel call that returned
+// ctx, in the order they completed, e.g.
+//
+//	ctx := testenv.Test(t, feature)
+//	for _, result := range env.TestResults(ctx) { ... }
+//
+// The context passed to the Finish and AfterSuite funcs carries the results of all the features run by the test
+// suite, so that these funcs can, for instance, collect more artifacts when a feature failed.
+func TestResults(ctx context.Context) []types.FeatureResult {
+	results, _ := ctx.Value(resultsKey{}).([]types.FeatureResult)
+	return results
+}
+
 // Run is to launch the test suite from a TestMain function.
 // It will run m.Run() and exercise all test functions in the
-// package.  This method will all Env.Setup operations prior to
-// starting the tests and run all Env.Finish operations after
-// before completing the suite.
+// package.  This method will all Env.BeforeSuite and Env.Setup
+// operations prior to starting the tests and run all Env.Finish and
+// Env.AfterSuite operations after before completing the suite.
+//
+// When the test suite receives an interrupt or a SIGTERM, the context
+// of the run is cancelled, the Env.Finish operations are run and the
+// test binary exits with a non-zero code. The same happens once the
+// deadline of the test suite set with envconf.Config.WithTestDeadline
+// is exceeded, except that the features not started yet are skipped
+// and the test binary exits once the tests are done.
 func (e *testEnv) Run(m *testing.M) (exitCode int) {
 	e.panicOnMissingContext()
-	ctx := e.ctx
+	// the setups, features and finish actions are traced as children of the span of the test suite
+	shutdownTracing := setupTracing(e.ctx)
+	suiteCtx, suiteSpan := startSpan(e.ctx, "test suite", attrNamespace.String(e.cfg.Namespace()))
+	if path := e.cfg.Progress(); path != "" {
+		progress, err := openProgressStream(path)
+		if err != nil {
+			klog.Errorf("failed to open the progress stream: %s", err)
+		}
+		e.reporter.progress = progress
+	}
+	suiteStart := time.Now()
+	e.reporter.progress.emit(progressEvent{Event: eventSuiteStarted})
+	defer func() {
+		status := statusPassed
+		if exitCode != 0 {
+			status = statusFailed
+		}
+		message := fmt.Sprintf("exit code %d", exitCode)
+		endSpan(suiteSpan, status, message)
+		shutdownTracing()
+		e.reporter.progress.emit(progressEvent{Event: eventSuiteFinished, Status: status, Elapsed: time.Since(suiteStart).Seconds(), Message: message})
+		if err := e.reporter.progress.close(); err != nil {
+			klog.Errorf("failed to close the progress stream: %s", err)
+		}
+	}()
+	// the run context is cancelled when the test suite is interrupted or runs out of time, so that the setups and
+	// the tests being run can stop before the finish actions clean up
+	runCtx, cancel := context.WithCancelCause(suiteCtx)
+	if deadline := e.cfg.TestDeadline(); deadline > 0 {
+		var cancelDeadline context.CancelFunc
+		runCtx, cancelDeadline = context.WithTimeoutCause(runCtx, deadline, fmt.Errorf("%w: %s", errDeadlineExceeded, deadline))
+		defer cancelDeadline()
+	}
+	run := &runContext{ctx: runCtx}
+
+	var finishOnce sync.Once
+	afterSuiteFailed := false
+	finish := func() {
+		finishOnce.Do(func() {
+			ctx := run.get()
+			if ctx.Err() != nil {
+				ctx = context.WithoutCancel(ctx)
+			}
+			ctx = context.WithValue(ctx, resultsKey{}, e.Results())
+			finishes := e.getFinishActions()
+			// attempt to gracefully clean up.
+			// Upon error, log and continue.
+			for _, fin := range finishes {
+				var err error
+				// context passed down to each finish step
+				start := time.Now()
+				if ctx, err = fin.run(ctx, e.cfg); err != nil {
+					klog.V(2).ErrorS(err, "Cleanup failed", "action", fin.role)
+				}
+				e.emitAction(fin, start, err)
+			}
+			var err error
+			if ctx, err = e.runSuiteActions(ctx, roleAfterSuite); err != nil {
+				afterSuiteFailed = true
+			}
+			run.set(ctx)
+		})
+	}
+	stop := handleInterrupts(cancel, finish)
+
+	if e.cfg.ListMode() {
+		e.listActions(roleBeforeSuite, roleSetup, roleBeforeTest, roleBeforeFeature, roleBeforeAssessment, roleAfterAssessment, roleAfterFeature, roleAfterTest)
+		defer e.listActions(roleFinish, roleAfterSuite)
+	}
+
+	if e.cfg.RerunFailed() {
+		failed, err := readRerunState(e.cfg.StateFile())
+		if err != nil {
+			klog.Errorf("failed to read the features to rerun: %s", err)
+			stop()
+			return 1
+		}
+		e.lastFailed = failed
+	}
 
 	setups := e.getSetupActions()
-	// fail fast on setup, upon err exit
-	var err error
 
 	defer func() {
 		// Recover and see if the panic handler is disabled. If it is disabled, panic and stop the workflow.
@@ -388,6 +773,7 @@ func (e *testEnv) Run(m *testing.M) (exitCode int) {
 		rErr := recover()
 		if rErr != nil {
 			if e.cfg.DisableGracefulTeardown() {
+				stop()
 				panic(rErr)
 			}
 			klog.Errorf("Recovering from panic and running finish actions: %s, stack: %s", rErr, string(debug.Stack()))
@@ -396,40 +782,66 @@ func (e *testEnv) Run(m *testing.M) (exitCode int) {
 			exitCode = 1
 		}
 
-		finishes := e.getFinishActions()
-		// attempt to gracefully clean up.
-		// Upon error, log and continue.
-		for _, fin := range finishes {
-			// context passed down to each finish step
-			if ctx, err = fin.run(ctx, e.cfg); err != nil {
-				klog.V(2).ErrorS(err, "Cleanup failed", "action", fin.role)
-			}
+		finish()
+		stop()
+		if afterSuiteFailed {
+			exitCode = 1
+		}
+		if errors.Is(context.Cause(runCtx), errInterrupted) {
+			exitCode = 1
+		}
+		if err := context.Cause(runCtx); errors.Is(err, errDeadlineExceeded) {
+			klog.Errorf("The test suite did not complete: %s", err)
+			exitCode = 1
 		}
-		e.ctx = ctx
+		e.ctx = run.get()
 	}()
 
+	ctx, err := e.runSuiteActions(run.get(), roleBeforeSuite)
+	run.set(ctx)
+	if err != nil {
+		return 1
+	}
+
 	for _, setup := range setups {
 		// context passed down to each setup
-		if ctx, err = setup.run(ctx, e.cfg); err != nil {
+		start := time.Now()
+		ctx, err := setup.run(run.get(), e.cfg)
+		run.set(ctx)
+		e.emitAction(setup, start, err)
+		// fail fast on setup, upon err exit
+		if err != nil {
 			klog.Errorf("%s failure: %s", setup.role, err)
 			return 1
 		}
 	}
-	e.ctx = ctx
+	e.ctx = run.get()
 
 	// Execute the test suite
-	return m.Run()
+	exitCode = m.Run()
+	if summary := e.reporter.quarantineSummary(); summary != "" {
+		klog.Warning(summary)
+	}
+	if dir := e.cfg.ReportDir(); dir != "" {
+		if err := e.reporter.writeReports(dir); err != nil {
+			klog.Errorf("failed to write the reports: %s", err)
+		}
+	}
+	if path := e.cfg.StateFile(); path != "" && !e.cfg.DryRunMode() {
+		if err := e.reporter.writeState(path); err != nil {
+			klog.Errorf("failed to write the state: %s", err)
+		}
+	}
+	return exitCode
 }
 
 func (e *testEnv) getActionsByRole(r actionRole) []action {
-	if e.actions == nil {
-		return nil
-	}
-
 	var result []action
-	for _, a := range e.actions {
-		if a.role == r {
-			result = append(result, a)
+	for _, layer := range e.layers(r) {
+		for _, a := range layer.actions {
+			if a.role == r {
+				result = append(result, a)
+			}
 		}
 	}
 
@@ -452,6 +864,14 @@ func (e *testEnv) getAfterFeatureActions() []action {
 	return e.getActionsByRole(roleAfterFeature)
 }
 
+func (e *testEnv) getBeforeAssessmentActions() []action {
+	return e.getActionsByRole(roleBeforeAssessment)
+}
+
+func (e *testEnv) getAfterAssessmentActions() []action {
+	return e.getActionsByRole(roleAfterAssessment)
+}
+
 func (e *testEnv) getAfterTestActions() []action {
 	return e.getActionsByRole(roleAfterTest)
 }
@@ -471,31 +891,302 @@ func (e *testEnv) executeSteps(ctx context.Context, t *testing.T, steps []types.
 	if e.cfg.DryRunMode() {
 		return ctx
 	}
-	for _, setup := range steps {
-		ctx = setup.Func()(ctx, t, e.cfg)
+	for _, step := range steps {
+		ctx = e.runStep(ctx, t, step)
 	}
 	return ctx
 }
 
+// executeAssessment runs the assessment, re-running it as configured by the retry policy of the assessment or of
+// its feature. As a failed *testing.T cannot recover, each attempt is run as a subtest of t named attempt-<n>. The
+// failed attempts fail t, as any failed subtest does, but the assessment is reported as flaky rather than failed
+// when a later attempt passes. It returns whether the assessment passed after one or more failed attempts.
+func (e *testEnv) executeAssessment(ctx context.Context, t *testing.T, f types.Feature, assess types.Step) (context.Context, bool) {
+	t.Helper()
+	var policy *types.RetryPolicy
+	if rs, ok := assess.(types.RetriedStep); ok {
+		policy = rs.Retry()
+	}
+	if rf, ok := f.(types.RetriedFeature); ok && policy == nil {
+		policy = rf.Retry()
+	}
+	if e.cfg.DryRunMode() || policy == nil || policy.Attempts <= 1 {
+		return e.executeSteps(ctx, t, []types.Step{assess}), false
+	}
+
+	delay := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		out := ctx
+		skipped := false
+		passed := t.Run(fmt.Sprintf("attempt-%d", attempt), func(attemptT *testing.T) {
+			defer func() {
+				skipped = attemptT.Skipped()
+			}()
+			out = e.runStep(ctx, attemptT, assess)
+		})
+		if passed && skipped {
+			t.Skipf("%s: skipped on attempt %d of %d", assess.Name(), attempt, policy.Attempts)
+		}
+		if passed {
+			if attempt > 1 {
+				t.Logf("%s: flaky: passed on attempt %d of %d", assess.Name(), attempt, policy.Attempts)
+			}
+			return out, attempt > 1
+		}
+		if attempt == policy.Attempts {
+			return out, false
+		}
+		t.Logf("%s: attempt %d of %d failed, retrying in %s", assess.Name(), attempt, policy.Attempts, delay)
+		select {
+		case <-ctx.Done():
+			t.Errorf("%s: not retried: %s", assess.Name(), context.Cause(ctx))
+			return out, false
+		case <-time.After(delay):
+		}
+		if policy.Factor > 1 {
+			delay = time.Duration(float64(delay) * policy.Factor)
+		}
+	}
+}
+
+// runStep runs the step with its timeout, if any. A step without a timeout is run inline, with the context whose
+// cancellation, e.g. by the timeout of the feature or the deadline of the test suite, is up to the step to honor,
+// and is failed if the context is done once it returns. A step with a timeout is run in a separate goroutine so
+// that the step is failed as soon as its timeout is exceeded, even if the step does not return right away after
+// the context is cancelled. Such a step is then abandoned: its goroutine is left running, leaked until it returns,
+// and t is failed with t.FailNow, except for the teardowns which are failed with t.Error so that the remaining
+// teardowns are run. The abandoned step must not use t anymore, which is done by then and whose use is reported as
+// a data race by -race. The context returned carries the values set by the step, but not the cancellation of its
+// timeout. The steps other than teardowns are not run once the context is done, e.g. its deadline has been
+// exceeded or a setup panicked.
+func (e *testEnv) runStep(ctx context.Context, t *testing.T, step types.Step) context.Context {
+	t.Helper()
+	if ctx.Err() != nil && step.Level() != types.LevelTeardown {
+		t.Errorf("%s: not run: %s", step.Name(), context.Cause(ctx))
+		return ctx
+	}
+	var timeout time.Duration
+	if ts, ok := step.(types.TimedStep); ok {
+		timeout = ts.Timeout()
+	}
+	if timeout <= 0 {
+		cancelled := ctx.Err() != nil
+		out, panicked := e.callStep(ctx, t, step)
+		if panicked {
+			return stepPanicked(ctx, t, step)
+		}
+		if !cancelled && ctx.Err() != nil {
+			// the step returned after the cancellation of its context
+			t.Errorf("%s: %s", step.Name(), context.Cause(ctx))
+		}
+		return out
+	}
+	stepCtx, cancel := context.WithTimeoutCause(ctx, timeout, fmt.Errorf("step %q exceeded its timeout of %s", step.Name(), timeout))
+	defer cancel()
+	var out context.Context
+	returned, panicked := false, false
+	var abandoned atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// an abandoned step panics when it uses t once its test is done, which must not crash the test binary
+		defer func() {
+			if abandoned.Load() {
+				_ = recover()
+			}
+		}()
+		out, panicked = e.callStep(stepCtx, t, step)
+		returned = true
+	}()
+	select {
+	case <-done:
+	case <-stepCtx.Done():
+		select {
+		case <-done:
+			// the step returned along with the cancellation of its context
+			t.Errorf("%s: %s", step.Name(), context.Cause(stepCtx))
+		default:
+			abandoned.Store(true)
+			if step.Level() == types.LevelTeardown {
+				t.Errorf("%s: %s, abandoned", step.Name(), context.Cause(stepCtx))
+				return ctx
+			}
+			t.Fatalf("%s: %s, abandoned", step.Name(), context.Cause(stepCtx))
+		}
+	}
+	if !returned {
+		// the step called t.FailNow or t.SkipNow, which only stopped the goroutine of the step
+		if t.Skipped() {
+			t.SkipNow()
+		}
+		t.FailNow()
+	}
+	if panicked {
+		return stepPanicked(ctx, t, step)
+	}
+	if out == nil {
+		return ctx
+	}
+	return valuesContext{Context: ctx, values: out}
+}
+
+// callStep calls the function of the step, recovering from a panic in the step unless the panic recovery is
+// disabled by the configuration. A recovered panic fails the step, with the stack of the panic.
+func (e *testEnv) callStep(ctx context.Context, t *testing.T, step types.Step) (out context.Context, panicked bool) {
+	t.Helper()
+	if e.cfg.DisableGracefulTeardown() {
+		return step.Func()(ctx, t, e.cfg), false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("%s: panic: %v\n%s", step.Name(), r, debug.Stack())
+			out, panicked = nil, true
+		}
+	}()
+	return step.Func()(ctx, t, e.cfg), false
+}
+
+// stepPanicked stops the feature after a step panicked. A panicking assessment stops the test of the assessment,
+// and the remaining assessments are skipped. A panicking setup cancels the context returned, so that the steps
+// other than the teardowns are not run.
+func stepPanicked(ctx context.Context, t *testing.T, step types.Step) context.Context {
+	t.Helper()
+	switch step.Level() {
+	case types.LevelAssess:
+		t.FailNow()
+	case types.LevelSetup:
+		ctx, cancel := context.WithCancelCause(ctx)
+		cancel(fmt.Errorf("setup %q panicked", step.Name()))
+		return ctx
+	}
+	return ctx
+}
+
+// valuesContext is a context carrying the values of the context values, and the deadline and cancellation of the
+// embedded context
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesContext) Value(key any) any {
+	return c.values.Value(key)
+}
+
 func (e *testEnv) execFeature(ctx context.Context, t *testing.T, featName string, f types.Feature) context.Context {
 	t.Helper()
+	result := featureResult{Test: t.Name(), Name: featName}
+	start := time.Now()
+	callerCtx := ctx
+	ctx, span := startSpan(ctx, featName, e.spanAttributes(attrTest.String(t.Name()), attrFeature.String(featName))...)
+	e.reporter.progress.emit(progressEvent{Event: eventFeatureStarted, Test: t.Name(), Feature: featName})
+	defer func() {
+		result.Duration = time.Since(start)
+		endSpan(span, result.Status, result.Message)
+		e.reporter.record(result)
+	}()
+	if reason := quarantineReason(f); reason != "" {
+		result.Quarantined = reason
+		defer reportQuarantined(t, &result)
+	}
 	// feature-level subtest
 	t.Run(featName, func(newT *testing.T) {
+		defer func() {
+			result.Status, result.Message = featureStatus(newT, result)
+		}()
 		newT.Helper()
 
 		if fDescription, ok := f.(types.DescribableFeature); ok && fDescription.Description() != "" {
 			t.Logf("Processing Feature: %s", fDescription.Description())
 		}
 
-		// setups run at feature-level
+		parentCtx := ctx
+		tf, timed := f.(types.TimedFeature)
+		timed = timed && tf.Timeout() > 0
+		if timed {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeoutCause(ctx, tf.Timeout(), fmt.Errorf("feature %q exceeded its timeout of %s", featName, tf.Timeout()))
+			defer cancel()
+		}
+
+		// the steps of the feature register their cleanups to the stack held by the context
+		ctx = features.WithCleanups(ctx)
+
+		// runStep runs a setup or a teardown step, recording its result even when it calls t.FailNow()
+		runStep := func(step types.Step, results *[]stepResult) {
+			failed, stepStart := newT.Failed(), time.Now()
+			stepCtx := ctx
+			var span trace.Span
+			ctx, span = startSpan(ctx, step.Name(), e.spanAttributes(attrFeature.String(featName), attrStep.String(step.Name()), attrLevel.String(step.Level().String()))...)
+			defer func() {
+				stepRes := stepResult{Name: step.Name(), Status: statusPassed, Duration: time.Since(stepStart)}
+				if !failed && newT.Failed() {
+					stepRes.Status = statusFailed
+					stepRes.Message = fmt.Sprintf("%s failed, see the output of %s", step.Name(), newT.Name())
+				}
+				*results = append(*results, stepRes)
+				e.reporter.progress.emit(stepEvent(t.Name(), featName, step.Level().String(), stepRes))
+				endSpan(span, stepRes.Status, stepRes.Message)
+				ctx = restoreSpan(ctx, stepCtx)
+			}()
+			ctx = e.runStep(ctx, newT, step)
+		}
+
+		// teardowns run at feature-level, without the timeout of the feature or the cancellation of a panicking
+		// setup, followed by the teardowns of the setups that succeeded in the reverse order of the setups and
+		// the cleanups registered by the steps, the last registered first
+		var succeeded []types.Step
+		tornDown := false
+		teardown := func() {
+			tornDown = true
+			if timed || ctx.Err() != nil {
+				ctx = valuesContext{Context: parentCtx, values: ctx}
+			}
+			if e.cfg.DryRunMode() {
+				return
+			}
+			teardowns := append(features.GetStepsByLevel(f.Steps(), types.LevelTeardown), undoSteps(succeeded)...)
+			for _, step := range teardowns {
+				runStep(step, &result.Teardowns)
+			}
+			// the cleanups can register cleanups of their own, which are run once they are done
+			for cleanups := features.PopCleanups(ctx); len(cleanups) > 0; cleanups = features.PopCleanups(ctx) {
+				for _, step := range cleanups {
+					runStep(step, &result.Teardowns)
+				}
+			}
+		}
+		// a setup calling t.FailNow() stops the test of the feature, the teardowns are then run on its way out
+		defer func() {
+			if !tornDown && !e.cfg.FailFast() {
+				teardown()
+			}
+		}()
+
+		// setups run at feature-level, a failed setup stops the remaining setups and the assessments
 		setups := features.GetStepsByLevel(f.Steps(), types.LevelSetup)
-		ctx = e.executeSteps(ctx, newT, setups)
+		setupFailed := false
+		if !e.cfg.DryRunMode() {
+			for _, setup := range setups {
+				runStep(setup, &result.Setups)
+				if newT.Failed() {
+					setupFailed = true
+					break
+				}
+				succeeded = append(succeeded, setup)
+			}
+		}
 
 		// assessments run as feature/assessment sub level
 		assessments := features.GetStepsByLevel(f.Steps(), types.LevelAssess)
+		if setupFailed {
+			assessments = nil
+		}
 
-		failed := false
-		for i, assess := range assessments {
+		var resultsMu sync.Mutex
+		// runAssessment runs the assessment as a subtest of the feature with ctx. It returns the context returned
+		// by the assessment, along with whether the next assessments of the feature must not be run.
+		runAssessment := func(ctx context.Context, i int, assess types.Step) (context.Context, bool) {
 			assessName := assess.Name()
 			if dAssess, ok := assess.(types.DescribableStep); ok && dAssess.Description() != "" {
 				t.Logf("Processing Assessment: %s", dAssess.Description())
@@ -505,17 +1196,51 @@ func (e *testEnv) execFeature(ctx context.Context, t *testing.T, featName string
 			}
 			// shouldFailNow catches whether t.FailNow() is called in the assessment.
 			// If it is, we won't proceed with the next assessment.
-			var shouldFailNow bool
+			var shouldFailNow, flaky, assessFailed bool
+			assessStart := time.Now()
 			newT.Run(assessName, func(internalT *testing.T) {
 				internalT.Helper()
+				assessCtx := ctx
+				var span trace.Span
+				ctx, span = startSpan(ctx, assessName, e.spanAttributes(attrFeature.String(featName), attrStep.String(assessName), attrLevel.String(types.LevelAssess.String()))...)
+				defer func() {
+					assessResult := stepResult{Name: assessName, Duration: time.Since(assessStart)}
+					assessResult.Status, assessResult.Message = testStatus(internalT)
+					// the failed attempts of a flaky assessment fail internalT
+					if flaky {
+						assessResult.Status = statusFlaky
+						assessResult.Message = fmt.Sprintf("%s passed after being retried", internalT.Name())
+					}
+					assessFailed = assessResult.Status == statusFailed
+					resultsMu.Lock()
+					result.Assessments = append(result.Assessments, assessResult)
+					resultsMu.Unlock()
+					e.reporter.progress.emit(stepEvent(t.Name(), featName, types.LevelAssess.String(), assessResult))
+					e.collectArtifacts(ctx, internalT)
+					endSpan(span, assessResult.Status, assessResult.Message)
+					ctx = restoreSpan(ctx, assessCtx)
+				}()
 				skipped, message := e.requireAssessmentProcessing(assess, i+1)
 				if skipped {
 					internalT.Skip(message)
 				}
-				// Set shouldFailNow to true before actually running the assessment, because if the assessment
-				// calls t.FailNow(), the function will be abruptly stopped in the middle of `e.executeSteps()`.
+				// Set shouldFailNow to true before actually running the assessment and its hooks, because if they
+				// call t.FailNow(), the function will be abruptly stopped in the middle of `e.executeSteps()`.
 				shouldFailNow = true
-				ctx = e.executeSteps(ctx, internalT, []types.Step{assess})
+				ctx = e.processAssessmentActions(ctx, internalT, f, assessName, e.getBeforeAssessmentActions())
+				var beforeHooks, afterHooks []types.AssessmentHookFunc
+				if hf, ok := f.(types.AssessmentHookedFeature); ok {
+					beforeHooks, afterHooks = hf.BeforeEachAssessment(), hf.AfterEachAssessment()
+				}
+				ctx = e.runAssessmentHooks(ctx, internalT, beforeHooks, assessName)
+				// the after each assessment hooks and actions are also run when the assessment calls t.FailNow()
+				defer func() {
+					ctx = e.runAssessmentHooks(ctx, internalT, afterHooks, assessName)
+					ctx = e.processAssessmentActions(ctx, internalT, f, assessName, e.getAfterAssessmentActions())
+				}()
+				hooksFailed := internalT.Failed()
+				ctx, flaky = e.executeAssessment(ctx, internalT, f, assess)
+				flaky = flaky && !hooksFailed
 				// If we reach this point, it means the assessment did not call t.FailNow().
 				shouldFailNow = false
 			})
@@ -524,25 +1249,62 @@ func (e *testEnv) execFeature(ctx context.Context, t *testing.T, featName string
 			// - a `t.Fail()` or `t.Failed()` invocation
 			// In one of those cases, we need to track that and stop the next set of assessment in the feature
 			// under test from getting executed.
-			if shouldFailNow || (e.cfg.FailFast() && newT.Failed()) {
-				failed = true
-				break
+			return ctx, shouldFailNow || (e.cfg.FailFast() && assessFailed) || ctx.Err() != nil
+		}
+
+		failed := false
+		for i := 0; i < len(assessments) && !failed; {
+			if !parallelStep(assessments[i]) {
+				ctx, failed = runAssessment(ctx, i, assessments[i])
+				i++
+				continue
 			}
+			// consecutive assessments marked as parallel run concurrently with the same context, as the contexts
+			// they return cannot be merged, and the next assessments are run once they are all done
+			var wg sync.WaitGroup
+			var stopped atomic.Bool
+			for ; i < len(assessments) && parallelStep(assessments[i]); i++ {
+				wg.Add(1)
+				go func(i int, assess types.Step) {
+					defer wg.Done()
+					if _, stop := runAssessment(ctx, i, assess); stop {
+						stopped.Store(true)
+					}
+				}(i, assessments[i])
+			}
+			wg.Wait()
+			failed = stopped.Load()
 		}
 
 		// Let us fail the test fast and not run the teardown in case if the framework specific fail-fast mode is
 		// invoked to make sure we leave the traces of the failed test behind to enable better debugging for the
 		// test developers
-		if e.cfg.FailFast() && failed {
+		if e.cfg.FailFast() && (failed || setupFailed) {
+			tornDown = true
 			newT.FailNow()
 		}
 
-		// teardowns run at feature-level
-		teardowns := features.GetStepsByLevel(f.Steps(), types.LevelTeardown)
-		ctx = e.executeSteps(ctx, newT, teardowns)
+		teardown()
 	})
 
-	return ctx
+	return restoreSpan(ctx, callerCtx)
+}
+
+// parallelStep returns true if the step is an assessment marked as parallel
+func parallelStep(step types.Step) bool {
+	ps, ok := step.(types.ParallelStep)
+	return ok && ps.Parallel()
+}
+
+// undoSteps returns the teardowns of the setups, in the reverse order of the setups
+func undoSteps(setups []types.Step) []types.Step {
+	var undos []types.Step
+	for i := len(setups) - 1; i >= 0; i-- {
+		if us, ok := setups[i].(types.UndoableStep); ok && us.Undo() != nil {
+			undos = append(undos, us.Undo())
+		}
+	}
+	return undos
 }
 
 // requireFeatureProcessing is a wrapper around the requireProcessing function to process the feature level validation
@@ -614,6 +1376,14 @@ func (e *testEnv) requireProcessing(kind, testName string, requiredRegexp, skipR
 				}
 			}
 		}
+
+		// the label selectors hold the set-based requirements along with the key=value ones checked above
+		if selector := e.cfg.LabelSelector(); len(selector) > 0 && !selector.Matches(labels) {
+			return true, fmt.Sprintf(`Skipping feature "%s": unmatched label selector "%s"`, testName, selector)
+		}
+		if requirement, matched := e.cfg.SkipLabelSelector().MatchesAny(labels); matched {
+			return true, fmt.Sprintf(`Skipping feature "%s": matched label selector provided in --skip-labels "%s"`, testName, requirement)
+		}
 	}
 	return skip, message
 }
@@ -626,13 +1396,13 @@ func (e *testEnv) deepCopyConfig() *envconf.Config {
 
 	// Manually setting fields that are struct types
 	if client := e.cfg.GetClient(); client != nil {
-		// Need to recreate the underlying client because client.Resource is not thread safe
-		// Panic on error because this should never happen since the client was built once already
-		clientCopy, err := klient.New(client.RESTConfig())
-		if err != nil {
-			panic(err)
+		// Need to clone the client because client.Resource is not thread safe
+		configCopy.WithClient(client.Clone())
+	}
+	for _, cluster := range e.cfg.Clusters() {
+		if client := e.cfg.GetClusterClient(cluster); client != nil {
+			configCopy.WithClusterClient(cluster, client.Clone())
 		}
-		configCopy.WithClient(clientCopy)
 	}
 	if e.cfg.AssessmentRegex() != nil {
 		configCopy.WithAssessmentRegex(e.cfg.AssessmentRegex().String())
@@ -665,6 +1435,37 @@ func (e *testEnv) deepCopyConfig() *envconf.Config {
 	return &configCopy
 }
 
+// featureClusters returns the names of the clusters the feature is run against, if it is run against clusters
+func (e *testEnv) featureClusters(f types.Feature) ([]string, bool) {
+	cf, ok := f.(types.ClusteredFeature)
+	if !ok || len(cf.Clusters()) == 0 {
+		return nil, false
+	}
+	var clusters []string
+	for _, cluster := range cf.Clusters() {
+		if cluster == types.AllClusters {
+			clusters = append(clusters, e.cfg.Clusters()...)
+			continue
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters, true
+}
+
+// featureRunNames returns the names the results of the feature are recorded with, which are the names of the
+// feature run against each of its clusters if any
+func (e *testEnv) featureRunNames(featName string, f types.Feature) []string {
+	clusters, ok := e.featureClusters(f)
+	if !ok {
+		return []string{featName}
+	}
+	names := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		names = append(names, fmt.Sprintf("%s-%s", featName, cluster))
+	}
+	return names
+}
+
 // deepCopyFeature just copies the values from the Feature to create a deep
 // copy to avoid mutation when we just want an informational copy.
 func deepCopyFeature(f types.Feature) types.Feature {
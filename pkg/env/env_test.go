@@ -17,11 +17,28 @@ limitations under the License.
 package env
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/decoder"
 	"sigs.k8s.io/e2e-framework/pkg/types"
 
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
@@ -635,6 +652,39 @@ func TestEnv_Test(t *testing.T) {
 				return
 			},
 		},
+		{
+			name:     "with label selectors",
+			ctx:      context.TODO(),
+			expected: []string{"net-feat", "storage-feat"},
+			setup: func(ctx context.Context, t *testing.T) (val []string) {
+				env := NewWithConfig(envconf.New().WithLabelSelector("tier in (net,storage), !slow").WithSkipLabelSelector("flaky"))
+				feature := func(name string, labels map[string]string) types.Feature {
+					builder := features.New(name)
+					for k, v := range labels {
+						builder = builder.WithLabel(k, v)
+					}
+					return builder.Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+						val = append(val, name)
+						return ctx
+					}).Feature()
+				}
+				// a skipped feature skips the rest of the test, hence the separate tests
+				t.Run("selected", func(t *testing.T) {
+					_ = env.Test(t,
+						feature("net-feat", map[string]string{"tier": "net"}),
+						feature("storage-feat", map[string]string{"tier": "storage"}),
+						feature("compute-feat", map[string]string{"tier": "compute"}),
+					)
+				})
+				t.Run("unselected", func(t *testing.T) {
+					_ = env.Test(t, feature("slow-feat", map[string]string{"tier": "net", "slow": "true"}))
+				})
+				t.Run("skipped", func(t *testing.T) {
+					_ = env.Test(t, feature("flaky-feat", map[string]string{"tier": "net", "flaky": "true"}))
+				})
+				return
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -746,6 +796,955 @@ func TestTestEnv_TestInParallel(t *testing.T) {
 	}
 }
 
+func TestTestEnv_ParallelFeatures(t *testing.T) {
+	env := New()
+	var mu sync.Mutex
+	var order []string
+	namespaces := map[string]bool{}
+	// the parallel features wait for each other, and can only complete if they run concurrently
+	var running sync.WaitGroup
+	running.Add(2)
+
+	record := func(name string, config *envconf.Config) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, name)
+		namespaces[config.Namespace()] = true
+	}
+	parallelFeature := func(name string) types.Feature {
+		return features.New(name).Parallel().
+			Assess("wait for the other parallel feature", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+				record(name, config)
+				running.Done()
+				done := make(chan struct{})
+				go func() {
+					running.Wait()
+					close(done)
+				}()
+				select {
+				case <-done:
+				case <-time.After(10 * time.Second):
+					t.Error("parallel features did not run concurrently")
+				}
+				return ctx
+			}).Feature()
+	}
+	serial := features.New("serial").
+		Assess("runs first", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			record("serial", config)
+			return ctx
+		}).Feature()
+
+	_ = env.Test(t, parallelFeature("parallel-1"), serial, parallelFeature("parallel-2"))
+
+	if len(order) != 3 || order[0] != "serial" {
+		t.Errorf("expected the serial feature to run before the parallel features, got %v", order)
+	}
+	if len(namespaces) != 3 {
+		t.Errorf("expected each parallel feature to use an isolated namespace, got %v", namespaces)
+	}
+}
+
+// parallelOnlyFeature is a feature marked as parallel that does not implement types.IsolatedFeature
+type parallelOnlyFeature struct {
+	types.Feature
+}
+
+func (parallelOnlyFeature) Parallel() bool {
+	return true
+}
+
+func TestTestEnv_ParallelFeaturesInParallel(t *testing.T) {
+	env := newTestEnvWithParallel()
+	var mu sync.Mutex
+	namespaces := map[string]string{}
+	feature := func(name string, parallel bool) types.Feature {
+		f := features.New(name).
+			Assess("record the namespace", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+				mu.Lock()
+				defer mu.Unlock()
+				namespaces[name] = config.Namespace()
+				return ctx
+			}).Feature()
+		if parallel {
+			return parallelOnlyFeature{Feature: f}
+		}
+		return f
+	}
+
+	_ = env.TestInParallel(t, feature("parallel-1", true), feature("parallel-2", true), feature("shared", false))
+
+	if namespaces["parallel-1"] == "" || namespaces["parallel-2"] == "" || namespaces["parallel-1"] == namespaces["parallel-2"] {
+		t.Errorf("expected each parallel feature to use an isolated namespace when the features run in parallel, got %v", namespaces)
+	}
+	if namespaces["shared"] != "" {
+		t.Errorf("expected the feature not marked as parallel to use the namespace of the env, got %v", namespaces)
+	}
+}
+
+func TestTestEnv_IsolatedFeatureInCluster(t *testing.T) {
+	out, passed := runHelperProcess(t, "^TestHelperProcess_IsolatedFeatureInCluster$")
+	if passed {
+		t.Error("expected the isolated feature to fail without a cluster to create its namespace in")
+	}
+	if !strings.Contains(out, "isolated feature in-cluster:") {
+		t.Errorf("expected the client of the in-cluster configuration to be used for the namespace, got:\n%s", out)
+	}
+	if !strings.Contains(out, "ran: false\n") {
+		t.Errorf("expected the feature not to run without its namespace, got:\n%s", out)
+	}
+}
+
+func TestHelperProcess_IsolatedFeatureInCluster(t *testing.T) {
+	helperProcess(t)
+	// the in-cluster configuration cannot be loaded outside of a pod
+	os.Unsetenv("KUBERNETES_SERVICE_HOST")
+	env := newTestEnv()
+	env.cfg.WithInCluster()
+	ran := false
+	f := features.New("in-cluster").Isolated().
+		Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran = true
+			return ctx
+		}).Feature()
+
+	t.Cleanup(func() { t.Logf("ran: %v", ran) })
+	_ = env.Test(t, f)
+}
+
+func TestTestEnv_Timeouts(t *testing.T) {
+	env := New()
+	var assessDeadline, teardownDeadline bool
+	f := features.New("timed-feature").WithTimeout(time.Minute).
+		AssessWithTimeout("timed assessment", 10*time.Second, func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			deadline, ok := ctx.Deadline()
+			if !ok || time.Until(deadline) > 10*time.Second {
+				t.Errorf("expected the context of the assessment to have the deadline of its timeout, got %v", deadline)
+			}
+			return context.WithValue(ctx, &ctxTestKeyString{}, "timed")
+		}).
+		Assess("untimed assessment", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			if ctx.Err() != nil {
+				t.Errorf("expected the context not to be cancelled after the timed assessment, got %v", ctx.Err())
+			}
+			if ctx.Value(&ctxTestKeyString{}) != "timed" {
+				t.Error("expected the value set by the timed assessment to be propagated")
+			}
+			_, assessDeadline = ctx.Deadline()
+			return ctx
+		}).
+		Teardown(func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			_, teardownDeadline = ctx.Deadline()
+			if ctx.Value(&ctxTestKeyString{}) != "timed" {
+				t.Error("expected the value set by the assessments to be propagated to the teardown")
+			}
+			return ctx
+		}).Feature()
+
+	_ = env.Test(t, f)
+	if !assessDeadline {
+		t.Error("expected the assessments to run with the deadline of the feature timeout")
+	}
+	if teardownDeadline {
+		t.Error("expected the teardown to run without the deadline of the feature timeout")
+	}
+}
+
+func TestTestEnv_AbandonedStep(t *testing.T) {
+	start := time.Now()
+	out, passed := runHelperProcess(t, "^TestHelperProcess_HungAssessment$")
+	if passed || !strings.Contains(out, `step "hung assessment" exceeded its timeout of 100ms, abandoned`) {
+		t.Errorf("expected the hung assessment to fail once its timeout is exceeded, got:\n%s", out)
+	}
+	if !strings.Contains(out, "teardown run") {
+		t.Errorf("expected the teardown to be run after the hung assessment, got:\n%s", out)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Second {
+		t.Errorf("expected the hung assessment to fail within its timeout, took %s", elapsed)
+	}
+}
+
+func TestHelperProcess_HungAssessment(t *testing.T) {
+	helperProcess(t)
+	f := features.New("hung-feature").
+		AssessWithTimeout("hung assessment", 100*time.Millisecond, func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			// ignores the cancellation of its context
+			time.Sleep(time.Hour)
+			return ctx
+		}).
+		Teardown(func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			t.Log("teardown run")
+			return ctx
+		}).Feature()
+
+	_ = New().Test(t, f)
+}
+
+func TestTestEnv_FeatureTimeoutInline(t *testing.T) {
+	out, passed := runHelperProcess(t, "^TestHelperProcess_FeatureTimeout$")
+	if passed || !strings.Contains(out, `slow assessment: feature "timed-out-feature" exceeded its timeout of 50ms`) {
+		t.Errorf("expected the assessment to fail once the feature timeout is exceeded, got:\n%s", out)
+	}
+	if !strings.Contains(out, "ran: [slow assessment teardown]\n") {
+		t.Errorf("expected the assessment without a timeout to run to completion before the teardown, got:\n%s", out)
+	}
+}
+
+func TestHelperProcess_FeatureTimeout(t *testing.T) {
+	helperProcess(t)
+	var ran []string
+	f := features.New("timed-out-feature").WithTimeout(50*time.Millisecond).
+		Assess("slow assessment", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			// ignores the cancellation of its context
+			time.Sleep(200 * time.Millisecond)
+			ran = append(ran, "slow assessment")
+			return ctx
+		}).
+		Teardown(func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			ran = append(ran, "teardown")
+			return ctx
+		}).Feature()
+
+	t.Cleanup(func() { t.Logf("ran: %v", ran) })
+	_ = New().Test(t, f)
+}
+
+func TestTestEnv_RetryAssessments(t *testing.T) {
+	out, passed := runHelperProcess(t, "^TestHelperProcess_RetryAssessments$")
+	// the failed attempts are subtests, which fail the test running the feature
+	if passed || !strings.Contains(out, "--- FAIL: TestHelperProcess_RetryAssessments/retried-feature/flaky_assessment/attempt-2") {
+		t.Errorf("expected the failed attempts to be reported by go test, got:\n%s", out)
+	}
+	if !strings.Contains(out, "--- PASS: TestHelperProcess_RetryAssessments/retried-feature/flaky_assessment/attempt-3") {
+		t.Errorf("expected the last attempt to pass, got:\n%s", out)
+	}
+	if !strings.Contains(out, "attempts: 3, feature attempts: 2, retried: true\n") {
+		t.Errorf("expected the assessments to be retried with their policy and the values of the passing attempt to be propagated, got:\n%s", out)
+	}
+	results := helperResults(t, out)
+	if len(results) != 1 || results[0].Status != statusPassed {
+		t.Fatalf("expected the feature with flaky assessments to pass, got %+v", results)
+	}
+	for _, assessment := range results[0].Assessments {
+		if assessment.Status != statusFlaky {
+			t.Errorf("expected assessment %q to be flaky, got %s", assessment.Name, assessment.Status)
+		}
+	}
+}
+
+func TestHelperProcess_RetryAssessments(t *testing.T) {
+	helperProcess(t)
+	env := newTestEnv()
+	// the flaky assessments do not stop the next ones in the fail-fast mode
+	env.cfg.WithFailFast()
+	attempts := 0
+	featureAttempts := 0
+	retried := false
+	f := features.New("retried-feature").WithRetry(features.RetryPolicy{Attempts: 2, Backoff: 10 * time.Millisecond}).
+		AssessWithRetry("flaky assessment", features.RetryPolicy{Attempts: 3, Backoff: 10 * time.Millisecond, Factor: 2}, func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			attempts++
+			if attempts < 3 {
+				t.Fatalf("failing attempt %d on purpose", attempts)
+			}
+			return context.WithValue(ctx, &ctxTestKeyString{}, "retried")
+		}).
+		Assess("assessment retried with the feature policy", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			featureAttempts++
+			if featureAttempts < 2 {
+				t.Errorf("failing attempt %d on purpose", featureAttempts)
+			}
+			retried = ctx.Value(&ctxTestKeyString{}) == "retried"
+			return ctx
+		}).Feature()
+
+	logResults(t, env.reporter)
+	t.Cleanup(func() { t.Logf("attempts: %d, feature attempts: %d, retried: %v", attempts, featureAttempts, retried) })
+	_ = env.Test(t, f)
+}
+
+func TestTestEnv_Reports(t *testing.T) {
+	out, _ := runHelperProcess(t, "^TestHelperProcess_Reports$")
+	results := helperResults(t, out)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 feature result, got %d", len(results))
+	}
+	if results[0].Name != "reported-feature" || results[0].Test != "TestHelperProcess_Reports" || results[0].Status != statusPassed {
+		t.Errorf("unexpected feature result: %+v", results[0])
+	}
+	expected := []resultStatus{statusPassed, statusFlaky, statusSkipped}
+	if len(results[0].Assessments) != len(expected) {
+		t.Fatalf("expected %d assessment results, got %d", len(expected), len(results[0].Assessments))
+	}
+	for i, status := range expected {
+		if results[0].Assessments[i].Status != status {
+			t.Errorf("expected assessment %q to be %s, got %s", results[0].Assessments[i].Name, status, results[0].Assessments[i].Status)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := (&reporter{features: results}).writeReports(dir); err != nil {
+		t.Fatal(err)
+	}
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".test")
+	data, err := os.ReadFile(filepath.Join(dir, name+"-report.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatal(err)
+	}
+	if suites.Tests != 3 || suites.Skipped != 1 || suites.Failures != 0 || len(suites.Suites) != 1 {
+		t.Errorf("unexpected JUnit report: %s", data)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, name+"-report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []featureResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, results) {
+		t.Errorf("unexpected JSON report: %s", data)
+	}
+}
+
+func TestHelperProcess_Reports(t *testing.T) {
+	helperProcess(t)
+	env := newTestEnv()
+	env.cfg.WithSkipAssessmentRegex("skipped")
+	attempts := 0
+	f := features.New("reported-feature").
+		Assess("passing assessment", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			return ctx
+		}).
+		AssessWithRetry("flaky assessment", features.RetryPolicy{Attempts: 2}, func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			attempts++
+			if attempts < 2 {
+				t.Error("failing the first attempt on purpose")
+			}
+			return ctx
+		}).
+		Assess("skipped assessment", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			t.Error("expected the assessment to be skipped")
+			return ctx
+		}).Feature()
+
+	logResults(t, env.reporter)
+	_ = env.Test(t, f)
+}
+
+func TestTestEnv_Quarantine(t *testing.T) {
+	out, passed := runHelperProcess(t, "^TestHelperProcess_Quarantine$")
+	// the quarantined feature is a subtest, whose failures fail the test running it
+	if passed || !strings.Contains(out, "--- FAIL: TestHelperProcess_Quarantine/quarantined/failing_assessment") {
+		t.Errorf("expected the failure of the quarantined feature to be reported by go test, got:\n%s", out)
+	}
+	if !strings.Contains(out, "quarantined failed while quarantined (flaky on slow nodes), failed steps: failing assessment") {
+		t.Errorf("expected the quarantined failure to be logged, got:\n%s", out)
+	}
+	results := helperResults(t, out)
+	if len(results) != 2 || !results[0].QuarantinedFailure() || results[0].Quarantined != "flaky on slow nodes" || results[1].Failed() {
+		t.Fatalf("expected the quarantined feature to fail without stopping the next feature, got %+v", results)
+	}
+	if summary := (&reporter{features: results}).quarantineSummary(); !strings.Contains(summary, "TestHelperProcess_Quarantine/quarantined (flaky on slow nodes)") {
+		t.Errorf("expected the quarantined feature in the summary, got %q", summary)
+	}
+	report := junitReport("quarantine", results)
+	if report.Failures != 0 || report.Skipped != 1 || report.Suites[0].Name != "quarantined/TestHelperProcess_Quarantine/quarantined" {
+		t.Errorf("expected the failure of the quarantined feature to be reported apart, got %+v", report)
+	}
+}
+
+func TestHelperProcess_Quarantine(t *testing.T) {
+	helperProcess(t)
+	env := newTestEnv()
+	// the quarantined failures do not stop the next features in the fail-fast mode
+	env.cfg.WithFailFast()
+	quarantined := features.New("quarantined").Quarantine("flaky on slow nodes").
+		Assess("failing assessment", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			t.Error("failing on purpose")
+			return ctx
+		}).Feature()
+	passing := features.New("passing").
+		Assess("passing assessment", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			return ctx
+		}).Feature()
+
+	logResults(t, env.reporter)
+	_ = env.Test(t, quarantined, passing)
+}
+
+func TestTestEnv_FeatureDependencies(t *testing.T) {
+	env := newTestEnv()
+	env.reporter.record(featureResult{Test: "TestOther", Name: "failed-feature", Status: statusFailed})
+	var mu sync.Mutex
+	var order []string
+	feature := func(name string, deps ...string) types.Feature {
+		return features.New(name).DependsOn(deps...).
+			Assess("record", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, name)
+				return ctx
+			}).Feature()
+	}
+
+	_ = env.Test(t,
+		feature("upgrade", "create"),
+		feature("create", "install"),
+		feature("install"),
+		feature("missing-prerequisite", "missing"),
+		feature("failed-prerequisite", "failed-feature"),
+	)
+	if !reflect.DeepEqual(order, []string{"install", "create", "upgrade"}) {
+		t.Errorf("expected the features to be run after their dependencies, got %v", order)
+	}
+
+	order = nil
+	env.cfg.WithParallelTestEnabled()
+	_ = env.TestInParallel(t, feature("second", "first"), feature("first"))
+	if !reflect.DeepEqual(order, []string{"first", "second"}) {
+		t.Errorf("expected the parallel features to wait for their dependencies, got %v", order)
+	}
+
+	for _, name := range []string{"missing-prerequisite", "failed-prerequisite"} {
+		result, ok := env.reporter.lookup(t.Name(), name)
+		if !ok || result.Status != statusSkipped {
+			t.Errorf("expected feature %s to be skipped, got %+v", name, result)
+		}
+	}
+}
+
+func TestTestEnv_Requirements(t *testing.T) {
+	env := newTestEnv()
+	var ran []string
+	env.BeforeEachFeature(func(ctx context.Context, _ *envconf.Config, _ *testing.T, f features.Feature) (context.Context, error) {
+		ran = append(ran, "before-"+f.Name())
+		return ctx, nil
+	})
+	met := func(context.Context, *envconf.Config) (bool, string) { return true, "" }
+	unmet := func(context.Context, *envconf.Config) (bool, string) { return false, "no GPU node" }
+	feature := func(name string, reqs ...features.Requirement) types.Feature {
+		builder := features.New(name)
+		for _, req := range reqs {
+			builder = builder.WithRequirement(req)
+		}
+		return builder.Setup(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran = append(ran, "setup-"+name)
+			return ctx
+		}).Feature()
+	}
+
+	_ = env.Test(t, feature("unmet-feature", met, unmet), feature("met-feature", met))
+	if !reflect.DeepEqual(ran, []string{"before-met-feature", "setup-met-feature"}) {
+		t.Errorf("expected only the feature with its requirements met to be run, got %v", ran)
+	}
+	result, ok := env.reporter.lookup(t.Name(), "unmet-feature")
+	if !ok || result.Status != statusSkipped || !strings.Contains(result.Message, "no GPU node") {
+		t.Errorf("expected the feature to be skipped with the reason of the requirement, got %+v", result)
+	}
+}
+
+func TestTestEnv_ParameterizedFeatures(t *testing.T) {
+	env := newTestEnv()
+	env.cfg.WithNamespace("shared")
+	type storage struct {
+		class string
+		size  int
+	}
+	var ran []string
+	namespaces := map[string]bool{}
+	generated := features.Parameterize("provision", []features.Case[storage]{
+		{Name: "standard", Params: storage{class: "standard", size: 1}},
+		{Params: storage{class: "fast", size: 10}},
+	}, func(builder *features.FeatureBuilder, params storage) {
+		builder.Assess("provision volume", func(ctx context.Context, t *testing.T, config *envconf.Config) context.Context {
+			ran = append(ran, fmt.Sprintf("%s:%d", params.class, params.size))
+			namespaces[config.Namespace()] = true
+			return ctx
+		})
+	})
+
+	if len(generated) != 2 || generated[0].Name() != "provision-standard" || generated[1].Name() != "provision-2" {
+		t.Fatalf("unexpected generated features: %v", generated)
+	}
+	_ = env.Test(t, generated...)
+	if !reflect.DeepEqual(ran, []string{"standard:1", "fast:10"}) {
+		t.Errorf("expected the template to be run with each parameter set, got %v", ran)
+	}
+	if len(namespaces) != 2 || namespaces["shared"] {
+		t.Errorf("expected each case to use a namespace of its own, got %v", namespaces)
+	}
+	if env.cfg.Namespace() != "shared" {
+		t.Errorf("expected the namespace of the environment to be left unchanged, got %s", env.cfg.Namespace())
+	}
+}
+
+func TestTestEnv_AssessmentHooks(t *testing.T) {
+	env := newTestEnv()
+	var order []string
+	env.BeforeEachAssessment(func(ctx context.Context, _ *envconf.Config, _ *testing.T, f features.Feature, assessment string) (context.Context, error) {
+		order = append(order, "env-before:"+f.Name()+"/"+assessment)
+		return context.WithValue(ctx, &ctxTestKeyString{}, assessment), nil
+	})
+	env.AfterEachAssessment(func(ctx context.Context, _ *envconf.Config, _ *testing.T, _ features.Feature, assessment string) (context.Context, error) {
+		order = append(order, "env-after:"+assessment)
+		return ctx, nil
+	})
+	f := features.New("hooked").
+		BeforeEachAssessment(func(ctx context.Context, t *testing.T, _ *envconf.Config, assessment string) context.Context {
+			order = append(order, "feature-before:"+assessment)
+			return ctx
+		}).
+		AfterEachAssessment(func(ctx context.Context, t *testing.T, _ *envconf.Config, assessment string) context.Context {
+			order = append(order, "feature-after:"+assessment)
+			return ctx
+		}).
+		Setup(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			order = append(order, "setup")
+			return ctx
+		}).
+		Assess("first", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			order = append(order, fmt.Sprintf("first:%v", ctx.Value(&ctxTestKeyString{})))
+			return ctx
+		}).
+		Assess("second", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			order = append(order, fmt.Sprintf("second:%v", ctx.Value(&ctxTestKeyString{})))
+			return ctx
+		}).Feature()
+
+	_ = env.Test(t, f)
+	expected := []string{
+		"setup",
+		"env-before:hooked/first", "feature-before:first", "first:first", "feature-after:first", "env-after:first",
+		"env-before:hooked/second", "feature-before:second", "second:second", "feature-after:second", "env-after:second",
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected hooks to run around each assessment:\n%v\ngot:\n%v", expected, order)
+	}
+}
+
+func TestTestEnv_PanicRecovery(t *testing.T) {
+	out, passed := runHelperProcess(t, "^TestHelperProcess_Panics$")
+	if passed {
+		t.Error("expected the panics to fail the test")
+	}
+	if !strings.Contains(out, "ran: [setup-panic:teardown assess-panic:teardown]\n") {
+		t.Errorf("expected only the teardowns to be run after the panics, got:\n%s", out)
+	}
+
+	out, passed = runHelperProcess(t, "^TestHelperProcess_PanickingAction$")
+	if passed || !strings.Contains(out, "ran: []\n") {
+		t.Errorf("expected the panicking action to fail the test without running the feature, got:\n%s", out)
+	}
+}
+
+func TestHelperProcess_Panics(t *testing.T) {
+	helperProcess(t)
+	var ran []string
+	record := func(name string) features.Func {
+		return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran = append(ran, name)
+			return ctx
+		}
+	}
+	panics := func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		panic("on purpose")
+	}
+	setupPanic := features.New("setup-panic").
+		Setup(panics).
+		Setup(record("setup-panic:setup")).
+		Assess("assess", record("setup-panic:assess")).
+		Teardown(record("setup-panic:teardown")).Feature()
+	assessPanic := features.New("assess-panic").
+		Assess("panics", panics).
+		Assess("assess", record("assess-panic:assess")).
+		Teardown(record("assess-panic:teardown")).Feature()
+
+	t.Cleanup(func() { t.Logf("ran: %v", ran) })
+	_ = newTestEnv().Test(t, setupPanic, assessPanic)
+}
+
+func TestHelperProcess_PanickingAction(t *testing.T) {
+	helperProcess(t)
+	var ran []string
+	// the panicking action stops the test, the steps run are logged once it is done
+	t.Cleanup(func() { t.Logf("ran: %v", ran) })
+	env := newTestEnv()
+	env.BeforeEachFeature(func(ctx context.Context, _ *envconf.Config, _ *testing.T, _ features.Feature) (context.Context, error) {
+		panic("on purpose")
+	})
+	_ = env.Test(t, features.New("feature").Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		ran = append(ran, "action:assess")
+		return ctx
+	}).Feature())
+}
+
+func TestTestEnv_SetupFailureTeardown(t *testing.T) {
+	out, passed := runHelperProcess(t, "^TestHelperProcess_SetupFailure$")
+	if passed {
+		t.Error("expected the failed setup to fail the test")
+	}
+	expected := "ran: [setup:first setup:second teardown teardown:second teardown:first]\n"
+	if !strings.Contains(out, expected) {
+		t.Errorf("expected %q to be logged after the failed setup, got:\n%s", expected, out)
+	}
+}
+
+func TestHelperProcess_SetupFailure(t *testing.T) {
+	helperProcess(t)
+	var ran []string
+	record := func(name string) features.Func {
+		return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran = append(ran, name)
+			return ctx
+		}
+	}
+	failNow := func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		t.Fatal("on purpose")
+		return ctx
+	}
+	failing := features.New("failing").
+		WithSetupTeardown("first", record("setup:first"), record("teardown:first")).
+		WithSetupTeardown("second", record("setup:second"), record("teardown:second")).
+		WithSetupTeardown("third", failNow, record("teardown:third")).
+		Setup(record("setup:fourth")).
+		Assess("assess", record("assess")).
+		Teardown(record("teardown")).Feature()
+
+	t.Cleanup(func() { t.Logf("ran: %v", ran) })
+	_ = newTestEnv().Test(t, failing)
+}
+
+func TestTestEnv_SuiteActions(t *testing.T) {
+	base := newTestEnv()
+	var ran []string
+	record := func(name string, err error) Func {
+		return func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+			ran = append(ran, name)
+			return ctx, err
+		}
+	}
+	base.BeforeSuite(
+		record("before:first", errors.New("first failed")),
+		func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+			ran = append(ran, "before:second")
+			panic("on purpose")
+		},
+		record("before:third", nil),
+	)
+	base.AfterSuite(record("after:base", nil))
+	suite := newTestEnv()
+	suite.AfterSuite(record("after:suite", errors.New("suite failed")))
+	composed := Compose(base, suite).(*testEnv)
+	child := base.WithContext(context.TODO()).(*testEnv)
+
+	_, err := composed.runSuiteActions(context.TODO(), roleBeforeSuite)
+	if err == nil || !strings.Contains(err.Error(), "first failed") || !strings.Contains(err.Error(), "panic: on purpose") {
+		t.Errorf("expected the errors of the failed funcs, got %v", err)
+	}
+	if _, err := child.runSuiteActions(context.TODO(), roleBeforeSuite); err != nil {
+		t.Errorf("expected the funcs already run not to be run again, got %v", err)
+	}
+	if _, err := composed.runSuiteActions(context.TODO(), roleAfterSuite); err == nil || !strings.Contains(err.Error(), "suite failed") {
+		t.Errorf("expected the error of the failed func, got %v", err)
+	}
+	if _, err := base.runSuiteActions(context.TODO(), roleAfterSuite); err != nil {
+		t.Errorf("expected the funcs already run not to be run again, got %v", err)
+	}
+	expected := []string{"before:first", "before:second", "before:third", "after:suite", "after:base"}
+	if !reflect.DeepEqual(ran, expected) {
+		t.Errorf("expected %v to be run once, got %v", expected, ran)
+	}
+}
+
+func TestTestEnv_RegisterCleanup(t *testing.T) {
+	out, passed := runHelperProcess(t, "^TestHelperProcess_RegisterCleanup$")
+	if passed {
+		t.Error("expected the failed assessment to fail the test")
+	}
+	expected := "ran: [create:first create:second create:third teardown delete:third delete:second delete:first]\n"
+	if !strings.Contains(out, expected) {
+		t.Errorf("expected %q to be logged, got:\n%s", expected, out)
+	}
+}
+
+func TestHelperProcess_RegisterCleanup(t *testing.T) {
+	helperProcess(t)
+	var ran []string
+	record := func(name string) features.Func {
+		return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran = append(ran, name)
+			return ctx
+		}
+	}
+	create := func(name string) features.Func {
+		return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran = append(ran, "create:"+name)
+			features.RegisterCleanup(ctx, record("delete:"+name))
+			return ctx
+		}
+	}
+	failing := features.New("failing").
+		Setup(create("first")).
+		Setup(create("second")).
+		Assess("assess", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			ctx = create("third")(ctx, t, cfg)
+			t.Fatal("on purpose")
+			return ctx
+		}).
+		Teardown(record("teardown")).Feature()
+
+	t.Cleanup(func() { t.Logf("ran: %v", ran) })
+	_ = newTestEnv().Test(t, failing)
+}
+
+func TestTestEnv_ListMode(t *testing.T) {
+	var out bytes.Buffer
+	listOutput = &out
+	defer func() { listOutput = os.Stdout }()
+
+	env := NewWithConfig(envconf.New().WithListMode().WithSkipAssessmentRegex("skipped"))
+	ran := false
+	env.Setup(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+		ran = true
+		return ctx, nil
+	})
+	env.(*testEnv).listActions(roleSetup)
+
+	f := features.New("listed").WithLabel("tier", "net").WithLabel("size", "small").
+		WithSetup("create", func(ctx context.Context, _ *testing.T, _ *envconf.Config) context.Context {
+			ran = true
+			return ctx
+		}).
+		Assess("check", func(ctx context.Context, _ *testing.T, _ *envconf.Config) context.Context {
+			ran = true
+			return ctx
+		}).
+		Assess("skipped", func(ctx context.Context, _ *testing.T, _ *envconf.Config) context.Context {
+			ran = true
+			return ctx
+		}).
+		WithTeardown("delete", func(ctx context.Context, _ *testing.T, _ *envconf.Config) context.Context {
+			ran = true
+			return ctx
+		}).Feature()
+	_ = env.Test(t, f)
+
+	if ran {
+		t.Error("expected nothing to be run in list mode")
+	}
+	expected := fmt.Sprintf(`Setup: env.TestTestEnv_ListMode
+Feature: %s/listed (labels: size=small, tier=net)
+    Setup: create
+    Assessment: check
+    Assessment: skipped (skipped)
+    Teardown: delete
+`, t.Name())
+	if out.String() != expected {
+		t.Errorf("expected the listing:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestTestEnv_RerunFailed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "state.json")
+	last := newReporter()
+	last.record(featureResult{Test: t.Name(), Name: "passed", Status: statusPassed})
+	last.record(featureResult{Test: t.Name(), Name: "prerequisite", Status: statusPassed})
+	last.record(featureResult{Test: t.Name(), Name: "failed", Status: statusFailed})
+	if err := last.writeState(path); err != nil {
+		t.Fatal(err)
+	}
+
+	env := newTestEnv()
+	failed, err := readRerunState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.lastFailed = failed
+	var ran []string
+	record := func(name string) features.Func {
+		return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran = append(ran, name)
+			return ctx
+		}
+	}
+	_ = env.Test(t,
+		features.New("passed").Assess("assess", record("passed")).Feature(),
+		features.New("prerequisite").Assess("assess", record("prerequisite")).Feature(),
+		features.New("failed").DependsOn("prerequisite").Assess("assess", record("failed")).Feature(),
+	)
+	if !reflect.DeepEqual(ran, []string{"prerequisite", "failed"}) {
+		t.Errorf("expected the failed feature to be rerun along with its prerequisite, got %v", ran)
+	}
+	if result, ok := env.reporter.lookup(t.Name(), "passed"); !ok || result.Status != statusSkipped {
+		t.Errorf("expected the feature that passed to be skipped, got %+v", result)
+	}
+
+	if err := env.reporter.writeState(path); err != nil {
+		t.Fatal(err)
+	}
+	if failed, err := readRerunState(path); err != nil || failed != nil {
+		t.Errorf("expected no feature to be rerun once all passed, got %v: %v", failed, err)
+	}
+	if failed, err := readRerunState(filepath.Join(t.TempDir(), "missing.json")); err != nil || failed != nil {
+		t.Errorf("expected a missing state file to rerun all the features, got %v: %v", failed, err)
+	}
+}
+
+func TestTestEnv_MultiCluster(t *testing.T) {
+	env := NewWithConfig(envconf.New().WithCluster("hub", "hub.kubeconfig").WithCluster("spoke-1", "spoke-1.kubeconfig"))
+	var mu sync.Mutex
+	var ran []string
+	record := func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+		mu.Lock()
+		defer mu.Unlock()
+		ran = append(ran, fmt.Sprintf("%s:%s", cfg.CurrentCluster(), cfg.KubeconfigFile()))
+		return ctx
+	}
+	_ = env.Test(t,
+		features.New("all").OnAllClusters().Assess("assess", record).Feature(),
+		features.New("hub").OnClusters("hub", "missing").Assess("assess", record).Feature(),
+	)
+	expected := []string{"hub:hub.kubeconfig", "spoke-1:spoke-1.kubeconfig", "hub:hub.kubeconfig"}
+	if !reflect.DeepEqual(ran, expected) {
+		t.Errorf("expected the features to be run against %v, got %v", expected, ran)
+	}
+	if result, ok := env.(*testEnv).reporter.lookup(t.Name(), "hub-missing"); !ok || result.Status != statusSkipped {
+		t.Errorf("expected the feature to be skipped for the missing cluster, got %+v", result)
+	}
+	if env.EnvConf().CurrentCluster() != "" {
+		t.Error("expected the configuration of the environment to be left unchanged")
+	}
+}
+
+func TestTestEnv_NamespacePerFeature(t *testing.T) {
+	env := NewWithConfig(envconf.New().WithNamespace("shared").WithNamespacePerFeature())
+	var namespaces []string
+	record := func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+		namespaces = append(namespaces, cfg.Namespace())
+		return ctx
+	}
+	_ = env.Test(t,
+		features.New("first").Assess("assess", record).Feature(),
+		features.New("second").Assess("assess", record).Feature(),
+	)
+	if len(namespaces) != 2 || namespaces[0] == namespaces[1] || !strings.HasPrefix(namespaces[0], "feature-") {
+		t.Errorf("expected each feature to get a namespace of its own, got %v", namespaces)
+	}
+	if env.EnvConf().Namespace() != "shared" {
+		t.Errorf("expected the namespace of the environment to be left unchanged, got %s", env.EnvConf().Namespace())
+	}
+}
+
+func TestTestEnv_Results(t *testing.T) {
+	env := newTestEnv()
+	noop := func(ctx context.Context, _ *testing.T, _ *envconf.Config) context.Context {
+		return ctx
+	}
+	f := features.New("results").
+		WithSetup("create", noop).
+		Assess("first", noop).
+		Assess("second", noop).
+		WithTeardown("delete", noop).Feature()
+	ctx := env.Test(t, f)
+
+	results := TestResults(ctx)
+	if len(results) != 1 {
+		t.Fatalf("expected the result of the feature, got %+v", results)
+	}
+	result := results[0]
+	if result.Test != t.Name() || result.Name != "results" || result.Failed() {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	names := func(steps []StepResult) []string {
+		var names []string
+		for _, step := range steps {
+			if step.Status != statusPassed {
+				t.Errorf("expected step %s to pass, got %s", step.Name, step.Status)
+			}
+			names = append(names, step.Name)
+		}
+		return names
+	}
+	if got := names(result.Setups); !reflect.DeepEqual(got, []string{"create"}) {
+		t.Errorf("unexpected setups: %v", got)
+	}
+	if got := names(result.Assessments); !reflect.DeepEqual(got, []string{"first", "second"}) {
+		t.Errorf("unexpected assessments: %v", got)
+	}
+	if got := names(result.Teardowns); !reflect.DeepEqual(got, []string{"delete"}) {
+		t.Errorf("unexpected teardowns: %v", got)
+	}
+
+	_ = env.Test(t, features.New("other").Assess("assess", noop).Feature())
+	if got := env.Results(); len(got) != 2 || got[1].Name != "other" {
+		t.Errorf("expected the results of all the features run by the environment, got %+v", got)
+	}
+}
+
+func TestTestEnv_ParallelAssessments(t *testing.T) {
+	env := newTestEnv()
+	var running sync.WaitGroup
+	running.Add(3)
+	var done atomic.Int32
+	probe := func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		running.Done()
+		// each probe waits for the others to be running, which only happens when they run concurrently
+		waited := make(chan struct{})
+		go func() {
+			running.Wait()
+			close(waited)
+		}()
+		select {
+		case <-waited:
+		case <-time.After(10 * time.Second):
+			t.Error("expected the assessments to run concurrently")
+		}
+		done.Add(1)
+		return ctx
+	}
+	after := -1
+	f := features.New("probes").
+		AssessInParallel("probe-1", probe).
+		AssessInParallel("probe-2", probe).
+		AssessInParallel("probe-3", probe).
+		Assess("after", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			after = int(done.Load())
+			return ctx
+		}).Feature()
+
+	results := TestResults(env.Test(t, f))
+	if after != 3 {
+		t.Errorf("expected the next assessment to run once the parallel ones are done, %d were done", after)
+	}
+	if len(results) != 1 || len(results[0].Assessments) != 4 || results[0].Failed() {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
+
+func TestTestEnv_DeadlineExceeded(t *testing.T) {
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 0, errDeadlineExceeded)
+	defer cancel()
+	env, err := NewWithContext(ctx, envconf.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := false
+	results := TestResults(env.Test(t, features.New("late").Assess("assess", func(ctx context.Context, _ *testing.T, _ *envconf.Config) context.Context {
+		ran = true
+		return ctx
+	}).Feature()))
+	if ran {
+		t.Error("expected no feature to be started once the deadline is exceeded")
+	}
+	if len(results) != 1 || results[0].Status != statusSkipped {
+		t.Errorf("expected the feature to be skipped, got %+v", results)
+	}
+}
+
 // Create a dedicated env that can be used to test the parallel execution of tests and features to make sure
 // they don't share the same config object but they inherit the one from the parent env.
 // Meaning that each test inherit the global testEnv and each feature inherit the testEnv of the test.
@@ -942,3 +1941,45 @@ func getFeaturesForTest() []features.Feature {
 		}).Feature()
 	return []features.Feature{f1, f2}
 }
+
+// widget is a custom type which is only known to the scheme of the client it is registered with
+type widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+func (w *widget) DeepCopyObject() runtime.Object {
+	c := *w
+	w.ObjectMeta.DeepCopyInto(&c.ObjectMeta)
+	return &c
+}
+
+func TestTestEnv_DeepCopyConfigKeepsScheme(t *testing.T) {
+	s := runtime.NewScheme()
+	s.AddKnownTypeWithName(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, &widget{})
+	client, err := klient.New(&rest.Config{Host: "https://127.0.0.1:6443"}, klient.WithScheme(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newTestEnv()
+	e.cfg.WithClient(client)
+	e.cfg.WithClusterClient("hub", client)
+
+	cfg := e.deepCopyConfig()
+	for _, name := range []string{"", "hub"} {
+		copied := cfg.GetClient()
+		if name != "" {
+			copied = cfg.GetClusterClient(name)
+		}
+		if copied == client {
+			t.Errorf("expected the client of cluster %q to be copied", name)
+		}
+		obj, err := decoder.DecodeAny(strings.NewReader(`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"w"}}`), decoder.WithScheme(copied.Resources().GetScheme()))
+		if err != nil {
+			t.Fatalf("failed to decode the custom type with the copied client of cluster %q: %v", name, err)
+		}
+		if _, ok := obj.(*widget); !ok {
+			t.Errorf("expected the copied client of cluster %q to decode a *widget, got %T", name, obj)
+		}
+	}
+}
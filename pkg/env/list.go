@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"sigs.k8s.io/e2e-framework/pkg/features"
+	"sigs.k8s.io/e2e-framework/pkg/types"
+)
+
+// listOutput is where the environment actions and the features are listed in list mode, it is replaced in the tests
+var (
+	listOutput   io.Writer = os.Stdout
+	listOutputMu sync.Mutex
+)
+
+// closureSuffix matches the suffix of the name of the closures, e.g. CreateCluster.func1
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// listf writes a line of the listing, the lines of the features run in parallel are not interleaved as each
+// feature is listed at once
+func listf(format string, args ...interface{}) {
+	listOutputMu.Lock()
+	defer listOutputMu.Unlock()
+	_, _ = fmt.Fprintf(listOutput, format, args...)
+}
+
+// listActions lists the functions of the environment actions of the roles
+func (e *testEnv) listActions(roles ...actionRole) {
+	var b strings.Builder
+	for _, role := range roles {
+		for _, a := range e.getActionsByRole(role) {
+			var fns []interface{}
+			for _, fn := range a.funcs {
+				fns = append(fns, fn)
+			}
+			for _, fn := range a.testFuncs {
+				fns = append(fns, fn)
+			}
+			for _, fn := range a.featureFuncs {
+				fns = append(fns, fn)
+			}
+			for _, fn := range a.assessmentFuncs {
+				fns = append(fns, fn)
+			}
+			for _, fn := range fns {
+				fmt.Fprintf(&b, "%s: %s\n", role, funcName(fn))
+			}
+		}
+	}
+	if b.Len() > 0 {
+		listf("%s", b.String())
+	}
+}
+
+// listFeature lists the feature along with its labels and its steps, the assessments filtered out are listed as
+// skipped
+func (e *testEnv) listFeature(t *testing.T, featName string, f types.Feature) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Feature: %s/%s", t.Name(), featName)
+	if labels := formatLabels(f.Labels()); labels != "" {
+		fmt.Fprintf(&b, " (labels: %s)", labels)
+	}
+	if reason := quarantineReason(f); reason != "" {
+		fmt.Fprintf(&b, " (quarantined: %s)", reason)
+	}
+	b.WriteString("\n")
+	setups := features.GetStepsByLevel(f.Steps(), types.LevelSetup)
+	for _, step := range setups {
+		fmt.Fprintf(&b, "    Setup: %s\n", step.Name())
+	}
+	for i, step := range features.GetStepsByLevel(f.Steps(), types.LevelAssess) {
+		name := step.Name()
+		if name == "" {
+			name = fmt.Sprintf("Assessment-%d", i+1)
+		}
+		fmt.Fprintf(&b, "    Assessment: %s", name)
+		if parallelStep(step) {
+			b.WriteString(" (parallel)")
+		}
+		if skipped, _ := e.requireAssessmentProcessing(step, i+1); skipped {
+			b.WriteString(" (skipped)")
+		}
+		b.WriteString("\n")
+	}
+	teardowns := append(features.GetStepsByLevel(f.Steps(), types.LevelTeardown), undoSteps(setups)...)
+	for _, step := range teardowns {
+		fmt.Fprintf(&b, "    Teardown: %s\n", step.Name())
+	}
+	listf("%s", b.String())
+}
+
+// formatLabels formats the labels as a sorted list of key=value pairs
+func formatLabels(labels types.Labels) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, key := range keys {
+		for _, value := range labels[key] {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// funcName returns the name of the function along with its package, e.g. envfuncs.CreateCluster, without the
+// suffix of the closures
+func funcName(fn interface{}) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return "<nil>"
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return "<unknown>"
+	}
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return closureSuffix.ReplaceAllString(name, "")
+}
@@ -18,7 +18,11 @@ package env
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"os"
+	"os/exec"
+	"strings"
 	"testing"
 
 	log "k8s.io/klog/v2"
@@ -88,3 +92,61 @@ func TestMain(m *testing.M) {
 
 	os.Exit(envForTesting.Run(m))
 }
+
+// helperProcessEnv is set in the environment of the test binary run by runHelperProcess
+const helperProcessEnv = "E2E_FRAMEWORK_HELPER_PROCESS"
+
+// runHelperProcess runs the tests matching pattern in a new process of the test binary, with the extra flags of
+// the test binary args such as -test.failfast, and returns its verbose output along with whether it passed. It lets
+// the tests check how the outcome of a test run is reported by go test, including the tests expected to fail,
+// without failing the test binary running them.
+func runHelperProcess(t *testing.T, pattern string, args ...string) (string, bool) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], append([]string{"-test.run=" + pattern, "-test.v", "-test.timeout=1m"}, args...)...)
+	cmd.Env = append(os.Environ(), helperProcessEnv+"=1")
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("failed to run the helper process: %s", err)
+	}
+	return string(out), err == nil
+}
+
+// helperProcess skips the test unless it is run by runHelperProcess
+func helperProcess(t *testing.T) {
+	t.Helper()
+	if os.Getenv(helperProcessEnv) == "" {
+		t.Skip("only run by runHelperProcess")
+	}
+}
+
+// resultsPrefix prefixes the results logged by logResults
+const resultsPrefix = "results: "
+
+// logResults logs the results recorded by the reporter as JSON once the helper process test t is done, so that
+// the test running it can check them with helperResults
+func logResults(t *testing.T, r *reporter) {
+	t.Helper()
+	t.Cleanup(func() {
+		data, err := json.Marshal(r.results())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(resultsPrefix + string(data))
+	})
+}
+
+// helperResults returns the results logged by logResults in the output of a helper process
+func helperResults(t *testing.T, out string) []featureResult {
+	t.Helper()
+	i := strings.Index(out, resultsPrefix)
+	if i < 0 {
+		t.Fatalf("no results logged by the helper process:\n%s", out)
+	}
+	line, _, _ := strings.Cut(out[i+len(resultsPrefix):], "\n")
+	var results []featureResult
+	if err := json.Unmarshal([]byte(line), &results); err != nil {
+		t.Fatalf("failed to decode the results logged by the helper process: %s", err)
+	}
+	return results
+}
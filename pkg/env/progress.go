@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	eventSuiteStarted    = "suite_started"
+	eventSuiteFinished   = "suite_finished"
+	eventActionFinished  = "action_finished"
+	eventFeatureStarted  = "feature_started"
+	eventFeatureFinished = "feature_finished"
+	eventStepFinished    = "step_finished"
+)
+
+// progressEvent is an event of the progress stream. The elapsed time is in seconds, the same way as the events of
+// `go test -json`.
+type progressEvent struct {
+	Time    time.Time    `json:"time"`
+	Event   string       `json:"event"`
+	Action  string       `json:"action,omitempty"`
+	Test    string       `json:"test,omitempty"`
+	Feature string       `json:"feature,omitempty"`
+	Step    string       `json:"step,omitempty"`
+	Level   string       `json:"level,omitempty"`
+	Status  resultStatus `json:"status,omitempty"`
+	Elapsed float64      `json:"elapsed,omitempty"`
+	Message string       `json:"message,omitempty"`
+}
+
+// progressStream writes the progress events of the run as JSON lines as they happen
+type progressStream struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+}
+
+// openProgressStream opens the progress stream writing to the file at path, or to the standard output when path is -
+func openProgressStream(path string) (*progressStream, error) {
+	if path == "-" {
+		return &progressStream{enc: json.NewEncoder(os.Stdout)}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create progress directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create progress file: %w", err)
+	}
+	return &progressStream{enc: json.NewEncoder(f), closer: f}, nil
+}
+
+// emit writes the event, it does nothing on a nil stream so that the events can be emitted when the progress stream
+// is not enabled
+func (p *progressStream) emit(event progressEvent) {
+	if p == nil {
+		return
+	}
+	event.Time = time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// a failure to write the progress must not fail the run, the events are best effort
+	_ = p.enc.Encode(event)
+}
+
+func (p *progressStream) close() error {
+	if p == nil || p.closer == nil {
+		return nil
+	}
+	return p.closer.Close()
+}
+
+// emitAction emits the event for the setup or finish action started at start, which returned err
+func (e *testEnv) emitAction(a action, start time.Time, err error) {
+	event := progressEvent{Event: eventActionFinished, Action: a.role.String(), Status: statusPassed, Elapsed: time.Since(start).Seconds()}
+	if err != nil {
+		event.Status, event.Message = statusFailed, err.Error()
+	}
+	e.reporter.progress.emit(event)
+}
+
+// stepEvent returns the event for the result of a step of the feature run by test
+func stepEvent(test, feature, level string, result stepResult) progressEvent {
+	return progressEvent{
+		Event:   eventStepFinished,
+		Test:    test,
+		Feature: feature,
+		Step:    result.Name,
+		Level:   level,
+		Status:  result.Status,
+		Elapsed: result.Duration.Seconds(),
+		Message: result.Message,
+	}
+}
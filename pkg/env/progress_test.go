@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+func TestProgressStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress", "events.jsonl")
+	progress, err := openProgressStream(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := newTestEnv()
+	env.reporter.progress = progress
+	noop := func(ctx context.Context, _ *testing.T, _ *envconf.Config) context.Context {
+		return ctx
+	}
+	f := features.New("progress").
+		WithSetup("create", noop).
+		Assess("check", noop).
+		WithTeardown("delete", noop).Feature()
+	_ = env.Test(t, f)
+	if err := progress.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var events, steps []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event progressEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid event %s: %s", scanner.Text(), err)
+		}
+		if event.Feature != "progress" || event.Test != t.Name() || event.Time.IsZero() {
+			t.Errorf("unexpected event: %+v", event)
+		}
+		events = append(events, event.Event)
+		if event.Event == eventStepFinished {
+			steps = append(steps, event.Level+":"+event.Step)
+		}
+	}
+	want := []string{eventFeatureStarted, eventStepFinished, eventStepFinished, eventStepFinished, eventFeatureFinished}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("unexpected events %v, want %v", events, want)
+	}
+	if want := []string{"setup:create", "assess:check", "teardown:delete"}; !reflect.DeepEqual(steps, want) {
+		t.Errorf("unexpected steps %v, want %v", steps, want)
+	}
+}
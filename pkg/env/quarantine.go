@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/e2e-framework/pkg/types"
+)
+
+// quarantineReason returns the reason the feature is quarantined for, empty if it is not quarantined
+func quarantineReason(f types.Feature) string {
+	if qf, ok := f.(types.QuarantinedFeature); ok {
+		return qf.QuarantineReason()
+	}
+	return ""
+}
+
+// reportQuarantined logs the outcome of the quarantined feature to t once the test of the feature is done and sets
+// the message of its result accordingly. The failures of the feature fail t, as the failures of any subtest do,
+// but the feature is recorded as quarantined so that it is reported apart and does not stop the other features.
+func reportQuarantined(t *testing.T, result *featureResult) {
+	t.Helper()
+	if result.Status != statusFailed {
+		t.Logf("Quarantined feature %s (%s): %s", result.Name, result.Quarantined, result.Status)
+		return
+	}
+	var failed []string
+	for _, steps := range [][]stepResult{result.Setups, result.Assessments, result.Teardowns} {
+		for _, step := range steps {
+			if step.Status == statusFailed {
+				failed = append(failed, step.Name)
+			}
+		}
+	}
+	result.Message = fmt.Sprintf("%s failed while quarantined (%s)", result.Name, result.Quarantined)
+	if len(failed) > 0 {
+		t.Logf("%s, failed steps: %s", result.Message, strings.Join(failed, ", "))
+	} else {
+		t.Log(result.Message)
+	}
+}
+
+// quarantineSummary returns the summary of the quarantined features that failed, empty if none did
+func (r *reporter) quarantineSummary() string {
+	var b strings.Builder
+	for _, result := range r.results() {
+		if result.QuarantinedFailure() {
+			fmt.Fprintf(&b, "\n    %s/%s (%s)", result.Test, result.Name, result.Quarantined)
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "Quarantined features failed:" + b.String()
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/e2e-framework/pkg/types"
+)
+
+type (
+	resultStatus  = types.ResultStatus
+	stepResult    = types.StepResult
+	featureResult = types.FeatureResult
+)
+
+const (
+	statusPassed  = types.StatusPassed
+	statusFailed  = types.StatusFailed
+	statusSkipped = types.StatusSkipped
+	statusFlaky   = types.StatusFlaky
+)
+
+// reporter records the results of the features run by an environment and its children
+type reporter struct {
+	mu       sync.Mutex
+	features []featureResult
+	// progress is the progress stream of the run, if enabled
+	progress *progressStream
+}
+
+func newReporter() *reporter {
+	return &reporter{}
+}
+
+func (r *reporter) record(result featureResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.features = append(r.features, result)
+	r.progress.emit(progressEvent{
+		Event:   eventFeatureFinished,
+		Test:    result.Test,
+		Feature: result.Name,
+		Status:  result.Status,
+		Elapsed: result.Duration.Seconds(),
+		Message: result.Message,
+	})
+}
+
+func (r *reporter) results() []featureResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]featureResult{}, r.features...)
+}
+
+// testResults returns the results of the test recorded after the first skipped results
+func (r *reporter) testResults(test string, skipped int) []featureResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var results []featureResult
+	for _, result := range r.features[skipped:] {
+		if result.Test == test {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// failed returns true if a feature of the test recorded after the first skipped results failed, the quarantined
+// features aside
+func (r *reporter) failed(test string, skipped int) bool {
+	for _, result := range r.testResults(test, skipped) {
+		if result.Failed() && !result.QuarantinedFailure() {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the last result recorded for the feature named name, preferring the results of test
+func (r *reporter) lookup(test, name string) (featureResult, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found *featureResult
+	for i := len(r.features) - 1; i >= 0; i-- {
+		if r.features[i].Name != name {
+			continue
+		}
+		if r.features[i].Test == test {
+			return r.features[i], true
+		}
+		if found == nil {
+			found = &r.features[i]
+		}
+	}
+	if found == nil {
+		return featureResult{}, false
+	}
+	return *found, true
+}
+
+// testStatus returns the status of t once it is done, along with a message pointing at its output when it failed,
+// as the messages logged by the test cannot be retrieved from the testing package
+func testStatus(t *testing.T) (resultStatus, string) {
+	switch {
+	case t.Failed():
+		return statusFailed, fmt.Sprintf("%s failed, see its output", t.Name())
+	case t.Skipped():
+		return statusSkipped, ""
+	default:
+		return statusPassed, ""
+	}
+}
+
+// featureStatus returns the status of the feature run by t once it is done, from the results of its steps. The
+// failed attempts of a flaky assessment fail t, as any failed subtest does, while they do not fail the feature.
+func featureStatus(t *testing.T, result featureResult) (resultStatus, string) {
+	status, message := testStatus(t)
+	if status != statusFailed {
+		return status, message
+	}
+	flaky := false
+	for _, steps := range [][]stepResult{result.Setups, result.Assessments, result.Teardowns} {
+		for _, step := range steps {
+			switch step.Status {
+			case statusFailed:
+				return status, message
+			case statusFlaky:
+				flaky = true
+			}
+		}
+	}
+	if flaky {
+		return statusPassed, ""
+	}
+	return status, message
+}
+
+// writeReports writes the results recorded as <name>-report.xml in the JUnit XML format and as
+// <name>-report.json to dir, where name is the name of the test binary
+func (r *reporter) writeReports(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".test")
+	results := r.results()
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+"-report.json"), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+
+	data, err = xml.MarshalIndent(junitReport(name, results), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	if err := os.WriteFile(filepath.Join(dir, name+"-report.xml"), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitReport converts the results to JUnit test suites, one per feature with a test case per assessment. The
+// features that failed or were skipped before running any assessment are reported as a single test case.
+func junitReport(name string, results []featureResult) *junitTestSuites {
+	report := &junitTestSuites{Name: name}
+	var total time.Duration
+	for _, feature := range results {
+		suite := junitTestSuite{
+			Name: feature.Test + "/" + feature.Name,
+			Time: junitTime(feature.Duration),
+		}
+		// the quarantined features are reported in suites of their own, their failures as skipped test cases
+		if feature.Quarantined != "" {
+			suite.Name = "quarantined/" + suite.Name
+		}
+		assessments := feature.Assessments
+		if len(assessments) == 0 {
+			assessments = []stepResult{{
+				Name:     feature.Name,
+				Status:   feature.Status,
+				Duration: feature.Duration,
+				Message:  feature.Message,
+			}}
+		}
+		for _, assessment := range assessments {
+			testCase := junitTestCase{
+				Name:      assessment.Name,
+				Classname: suite.Name,
+				Time:      junitTime(assessment.Duration),
+			}
+			switch {
+			case assessment.Status == statusFailed && feature.Quarantined != "":
+				testCase.Skipped = &junitMessage{Message: fmt.Sprintf("quarantined (%s): %s", feature.Quarantined, assessment.Message)}
+				suite.Skipped++
+			case assessment.Status == statusFailed:
+				testCase.Failure = &junitMessage{Message: assessment.Message}
+				suite.Failures++
+			case assessment.Status == statusSkipped:
+				testCase.Skipped = &junitMessage{Message: assessment.Message}
+				suite.Skipped++
+			case assessment.Status == statusFlaky:
+				testCase.SystemOut = assessment.Message
+			}
+			suite.Tests++
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		total += feature.Duration
+		report.Suites = append(report.Suites, suite)
+	}
+	report.Time = junitTime(total)
+	return report
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
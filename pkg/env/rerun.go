@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/e2e-framework/pkg/types"
+)
+
+// rerunState is the content of the state file, it lists the features that failed during a run so that the next
+// run can be restricted to them with --rerun-failed
+type rerunState struct {
+	Failed []rerunFeature `json:"failed"`
+}
+
+// rerunFeature identifies a feature by the name of its test and its own name
+type rerunFeature struct {
+	Test string `json:"test"`
+	Name string `json:"name"`
+}
+
+// writeState writes the features that failed to the state file at path
+func (r *reporter) writeState(path string) error {
+	state := rerunState{Failed: []rerunFeature{}}
+	for _, result := range r.results() {
+		if result.Status == statusFailed {
+			state.Failed = append(state.Failed, rerunFeature{Test: result.Test, Name: result.Name})
+		}
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
+
+// readRerunState returns the features that failed as written to the state file at path. It returns nil when the
+// file does not exist or when no feature failed, in which case all the features are to be run.
+func readRerunState(path string) (map[rerunFeature]bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	var state rerunState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode state %s: %w", path, err)
+	}
+	if len(state.Failed) == 0 {
+		return nil, nil
+	}
+	failed := make(map[rerunFeature]bool, len(state.Failed))
+	for _, f := range state.Failed {
+		failed[f] = true
+	}
+	return failed, nil
+}
+
+// rerunSelection returns the names of the features of the test to be rerun, which are the features that failed
+// in the last run along with the features they depend on. A feature failed if one of the results recorded under
+// the names given by runNames failed. It returns nil when all the features are to be run.
+func rerunSelection(failed map[rerunFeature]bool, test string, testFeatures []types.Feature, runNames func(string, types.Feature) []string) map[string]bool {
+	if failed == nil {
+		return nil
+	}
+	byName := map[string][]types.Feature{}
+	for _, f := range testFeatures {
+		byName[f.Name()] = append(byName[f.Name()], f)
+	}
+	selected := map[string]bool{}
+	var selectWithDeps func(name string)
+	selectWithDeps = func(name string) {
+		if selected[name] {
+			return
+		}
+		selected[name] = true
+		for _, f := range byName[name] {
+			for _, dep := range dependencies(f) {
+				selectWithDeps(dep)
+			}
+		}
+	}
+	for i, f := range testFeatures {
+		name := featureName(i, f)
+		for _, runName := range runNames(name, f) {
+			if failed[rerunFeature{Test: test, Name: runName}] {
+				selectWithDeps(name)
+			}
+		}
+	}
+	return selected
+}
+
+// featureName returns the name of the feature at index i of the features of a test, features without a name are
+// named after their position
+func featureName(i int, f types.Feature) string {
+	if f.Name() == "" {
+		return fmt.Sprintf("Feature-%d", i+1)
+	}
+	return f.Name()
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	klog "k8s.io/klog/v2"
+)
+
+// errInterrupted is the cause of the cancellation of the run context when the test suite is interrupted
+var errInterrupted = errors.New("test suite interrupted")
+
+// interruptSignals are the signals stopping the run of the test suite gracefully
+var interruptSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// notifySignals and exit are used to receive the signals and to exit the test binary once the test suite has
+// been interrupted, they are replaced in the tests
+var (
+	notifySignals = signal.Notify
+	exit          = os.Exit
+)
+
+// runContext holds the context of the run, which is shared by the run and the handling of the interrupts
+type runContext struct {
+	mu  sync.Mutex
+	ctx context.Context
+}
+
+func (r *runContext) get() context.Context {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ctx
+}
+
+func (r *runContext) set(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ctx = ctx
+}
+
+// handleInterrupts waits for one of the interruptSignals until stop is called. When a signal is received, the run
+// context is cancelled with errInterrupted as the cause, finish is called and the test binary exits with a non-zero
+// code, as the tests being run may never return. A second signal received while finishing exits right away.
+func handleInterrupts(cancel context.CancelCauseFunc, finish func()) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	notifySignals(signals, interruptSignals...)
+	done := make(chan struct{})
+
+	go func() {
+		var sig os.Signal
+		select {
+		case <-done:
+			return
+		case sig = <-signals:
+		}
+		klog.Errorf("Received %s, cancelling the run and running finish actions", sig)
+		cancel(fmt.Errorf("%w: received %s", errInterrupted, sig))
+		go func() {
+			select {
+			case <-done:
+			case sig := <-signals:
+				klog.Errorf("Received %s again, exiting without finishing", sig)
+				exit(1)
+			}
+		}()
+		finish()
+		exit(1)
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"testing"
+	"time"
+)
+
+func TestHandleInterrupts(t *testing.T) {
+	exited := make(chan int, 1)
+	var signals chan<- os.Signal
+	notifySignals = func(c chan<- os.Signal, _ ...os.Signal) { signals = c }
+	exit = func(code int) { exited <- code }
+	defer func() {
+		notifySignals = signal.Notify
+		exit = os.Exit
+	}()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	finished := false
+	stop := handleInterrupts(cancel, func() { finished = true })
+	defer stop()
+
+	signals <- os.Interrupt
+
+	select {
+	case code := <-exited:
+		if code == 0 {
+			t.Error("expected a non-zero exit code after the interrupt")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the interrupt to exit")
+	}
+	if !finished {
+		t.Error("expected the finish to be run before exiting")
+	}
+	if !errors.Is(context.Cause(ctx), errInterrupted) {
+		t.Errorf("expected the run context to be cancelled by the interrupt, got %v", context.Cause(ctx))
+	}
+}
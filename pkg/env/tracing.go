@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	klog "k8s.io/klog/v2"
+)
+
+const (
+	// tracerName is the name of the tracer the spans of the framework are started with
+	tracerName = "sigs.k8s.io/e2e-framework"
+	// tracingShutdownTimeout is the time given to the exporter to send the spans left at the end of the run
+	tracingShutdownTimeout = 10 * time.Second
+
+	attrTest      = attribute.Key("e2e.test")
+	attrFeature   = attribute.Key("e2e.feature")
+	attrStep      = attribute.Key("e2e.step")
+	attrLevel     = attribute.Key("e2e.step.level")
+	attrFunc      = attribute.Key("e2e.func")
+	attrOutcome   = attribute.Key("e2e.outcome")
+	attrNamespace = attribute.Key("k8s.namespace.name")
+	attrCluster   = attribute.Key("k8s.cluster.name")
+)
+
+// setupTracing installs a tracer provider exporting the spans with OTLP over HTTP when an endpoint is configured with
+// the standard OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variables, unless
+// OTEL_SDK_DISABLED or OTEL_TRACES_EXPORTER=none disable it. The exporter and the resource of the spans are
+// configured by the other standard OTEL_ environment variables. Without an endpoint, the spans go to the tracer
+// provider set globally with otel.SetTracerProvider, if any. It returns the func flushing the spans left at the end
+// of the run.
+func setupTracing(ctx context.Context) (shutdown func()) {
+	shutdown = func() {}
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return shutdown
+	}
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") || os.Getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return shutdown
+	}
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		klog.Errorf("failed to create the trace exporter: %s", err)
+		return shutdown
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "e2e-framework")),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		klog.Errorf("failed to detect the resource of the traces: %s", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tracingShutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			klog.Errorf("failed to export the traces: %s", err)
+		}
+	}
+}
+
+// spanAttributes returns attrs along with the namespace and the cluster the environment runs the features in
+func (e *testEnv) spanAttributes(attrs ...attribute.KeyValue) []attribute.KeyValue {
+	attrs = append(attrs, attrNamespace.String(e.cfg.Namespace()))
+	if cluster := e.cfg.CurrentCluster(); cluster != "" {
+		attrs = append(attrs, attrCluster.String(cluster))
+	}
+	return attrs
+}
+
+// startSpan starts a span of the framework named name as a child of the span of ctx
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the span with the outcome of the feature or the step it traces
+func endSpan(span trace.Span, status resultStatus, message string) {
+	span.SetAttributes(attrOutcome.String(string(status)))
+	if status == statusFailed {
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+}
+
+// restoreSpan returns ctx holding the span of parent again, so that the spans started from the context returned by
+// a traced step are not children of the span of the step, which has ended
+func restoreSpan(ctx, parent context.Context) context.Context {
+	return trace.ContextWithSpan(ctx, trace.SpanFromContext(parent))
+}
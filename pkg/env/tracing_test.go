@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+	"sigs.k8s.io/e2e-framework/pkg/types"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previous)
+
+	env := newTestEnv()
+	env.cfg.WithNamespace("traced")
+	noop := func(ctx context.Context, _ *testing.T, _ *envconf.Config) context.Context {
+		return ctx
+	}
+	f := features.New("traced").
+		WithSetup("create", noop).
+		Assess("check", noop).
+		WithTeardown("delete", noop).Feature()
+	_ = env.Test(t, f)
+
+	setup := action{role: roleSetup, funcs: []types.EnvFunc{func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+		return ctx, errors.New("setup failed")
+	}}}
+	_, _ = setup.run(context.Background(), env.cfg)
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	feature, ok := spans["traced"]
+	if !ok {
+		t.Fatalf("expected a span for the feature, got %v", spans)
+	}
+	for _, name := range []string{"create", "check", "delete"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("expected a span for the step %s", name)
+			continue
+		}
+		if span.Parent.SpanID() != feature.SpanContext.SpanID() {
+			t.Errorf("expected the span of the step %s to be a child of the span of the feature", name)
+		}
+	}
+	attributes := make(map[string]string)
+	for _, attr := range feature.Attributes {
+		attributes[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attributes["k8s.namespace.name"] != "traced" || attributes["e2e.outcome"] != string(statusPassed) {
+		t.Errorf("unexpected attributes of the span of the feature: %v", attributes)
+	}
+	if span, ok := spans["Setup"]; !ok || span.Status.Code != codes.Error {
+		t.Errorf("expected an error span for the failed setup func, got %+v", span.Status)
+	}
+}
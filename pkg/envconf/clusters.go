@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envconf
+
+import (
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/e2e-framework/klient"
+)
+
+// cluster is a named cluster registered in the configuration, along with its kubeconfig and client
+type cluster struct {
+	kubeconfig string
+	client     klient.Client
+}
+
+// WithCluster registers the cluster named name, reachable with the kubeconfig file, so that its client can be
+// retrieved with Client(name). Registering a cluster again replaces its kubeconfig and client.
+func (c *Config) WithCluster(name, kubeconfig string) *Config {
+	c.setCluster(name, cluster{kubeconfig: kubeconfig})
+	return c
+}
+
+// WithClusterClient sets the client of the cluster named name, registering the cluster if need be
+func (c *Config) WithClusterClient(name string, client klient.Client) *Config {
+	cl := c.clusters[name]
+	cl.client = client
+	c.setCluster(name, cl)
+	return c
+}
+
+// setCluster sets the cluster on a copy of the registered clusters, so that the copies of the configuration
+// do not share them
+func (c *Config) setCluster(name string, cl cluster) {
+	clusters := make(map[string]cluster, len(c.clusters)+1)
+	for n, existing := range c.clusters {
+		clusters[n] = existing
+	}
+	clusters[name] = cl
+	c.clusters = clusters
+}
+
+// Clusters returns the sorted names of the clusters registered in the configuration
+func (c *Config) Clusters() []string {
+	names := make([]string, 0, len(c.clusters))
+	for name := range c.clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasCluster returns true if the cluster named name is registered in the configuration
+func (c *Config) HasCluster(name string) bool {
+	_, ok := c.clusters[name]
+	return ok
+}
+
+// ClusterKubeconfigFile returns the kubeconfig file of the cluster named name
+func (c *Config) ClusterKubeconfigFile(name string) string {
+	return c.clusters[name].kubeconfig
+}
+
+// GetClusterClient returns the client set for the cluster named name, if any
+func (c *Config) GetClusterClient(name string) klient.Client {
+	return c.clusters[name].client
+}
+
+// WithCurrentCluster makes the cluster named name the one used by KubeconfigFile, GetClient and Client when no
+// cluster name is given. It panics if the cluster is not registered.
+func (c *Config) WithCurrentCluster(name string) *Config {
+	cl, ok := c.clusters[name]
+	if !ok {
+		panic(fmt.Sprintf("cluster %q is not registered", name))
+	}
+	c.currentCluster = name
+	c.kubeconfig = cl.kubeconfig
+	c.client = cl.client
+	return c
+}
+
+// CurrentCluster returns the name of the cluster set with WithCurrentCluster, empty if none was set
+func (c *Config) CurrentCluster() string {
+	return c.currentCluster
+}
+
+// clusterClient returns the client of the cluster named by cluster, or the default client if no name is given
+func (c *Config) clusterClient(cluster []string) (klient.Client, error) {
+	switch len(cluster) {
+	case 0:
+		if c.client != nil {
+			return c.client, nil
+		}
+		var client klient.Client
+		var err error
+		if c.inCluster {
+			client, err = klient.NewInCluster()
+		} else {
+			client, err = klient.NewWithKubeConfigContext(c.kubeconfig, c.kubeContext)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("client failed: %w", err)
+		}
+		return client, nil
+	case 1:
+		cl, ok := c.clusters[cluster[0]]
+		if !ok {
+			return nil, fmt.Errorf("client failed: cluster %q is not registered", cluster[0])
+		}
+		if cl.client != nil {
+			return cl.client, nil
+		}
+		client, err := klient.NewWithKubeConfigFile(cl.kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("client failed for cluster %q: %w", cluster[0], err)
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("client failed: expected a single cluster name, got %v", cluster)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"regexp"
+	"time"
 
 	log "k8s.io/klog/v2"
 
@@ -32,18 +33,33 @@ import (
 type Config struct {
 	client                  klient.Client
 	kubeconfig              string
+	clusters                map[string]cluster
+	currentCluster          string
 	namespace               string
 	assessmentRegex         *regexp.Regexp
 	featureRegex            *regexp.Regexp
 	labels                  flags.LabelsMap
+	labelSelector           flags.LabelSelector
 	skipFeatureRegex        *regexp.Regexp
 	skipLabels              flags.LabelsMap
+	skipLabelSelector       flags.LabelSelector
 	skipAssessmentRegex     *regexp.Regexp
 	parallelTests           bool
 	dryRun                  bool
 	failFast                bool
 	disableGracefulTeardown bool
 	kubeContext             string
+	reportDir               string
+	artifactsDir            string
+	progress                string
+	listMode                bool
+	stateFile               string
+	rerunFailed             bool
+	namespacePerFeature     bool
+	testDeadline            time.Duration
+	providerOptions         map[string]string
+	values                  map[string]string
+	inCluster               bool
 }
 
 // New creates and initializes an empty environment configuration
@@ -59,37 +75,81 @@ func NewWithKubeConfig(kubeconfig string) *Config {
 
 // NewFromFlags initializes an environment config using flag values
 // parsed from command-line arguments and returns an error on parsing failure.
+//
+// When a configuration file is set with --config, the config is first
+// initialized from the file, see NewFromFile, and the flags that are set
+// explicitly override the values of the file, whatever their value.
 func NewFromFlags() (*Config, error) {
 	envFlags, err := flags.Parse()
 	if err != nil {
 		log.Fatalf("flags parse failed: %s", err)
 	}
 	e := New()
-	if envFlags.Assessment() != "" {
-		e.assessmentRegex = regexp.MustCompile(envFlags.Assessment())
+	if path := envFlags.ConfigFile(); path != "" {
+		if e, err = NewFromFile(path); err != nil {
+			return nil, err
+		}
 	}
-	if envFlags.Feature() != "" {
-		e.featureRegex = regexp.MustCompile(envFlags.Feature())
+	set := envFlags.IsSet
+	overrideRegex(&e.assessmentRegex, set("assess"), envFlags.Assessment())
+	overrideRegex(&e.featureRegex, set("feature"), envFlags.Feature())
+	if set("labels") {
+		e.labels = envFlags.Labels()
+		e.labelSelector = envFlags.LabelSelector()
 	}
-	e.labels = envFlags.Labels()
-	e.namespace = envFlags.Namespace()
-	e.kubeconfig = envFlags.Kubeconfig()
-	if envFlags.SkipFeatures() != "" {
-		e.skipFeatureRegex = regexp.MustCompile(envFlags.SkipFeatures())
+	overrideString(&e.namespace, set("namespace"), envFlags.Namespace())
+	overrideString(&e.kubeconfig, set("kubeconfig"), envFlags.Kubeconfig())
+	overrideRegex(&e.skipFeatureRegex, set("skip-features"), envFlags.SkipFeatures())
+	overrideRegex(&e.skipAssessmentRegex, set("skip-assessment"), envFlags.SkipAssessment())
+	if set("skip-labels") {
+		e.skipLabels = envFlags.SkipLabels()
+		e.skipLabelSelector = envFlags.SkipLabelSelector()
 	}
-	if envFlags.SkipAssessment() != "" {
-		e.skipAssessmentRegex = regexp.MustCompile(envFlags.SkipAssessment())
+	overrideBool(&e.parallelTests, set("parallel"), envFlags.Parallel())
+	overrideBool(&e.dryRun, set("dry-run"), envFlags.DryRun())
+	overrideBool(&e.failFast, set("fail-fast"), envFlags.FailFast())
+	overrideBool(&e.disableGracefulTeardown, set("disable-graceful-teardown"), envFlags.DisableGracefulTeardown())
+	overrideString(&e.kubeContext, set("context"), envFlags.KubeContext())
+	overrideBool(&e.inCluster, set("in-cluster"), envFlags.InCluster())
+	overrideString(&e.reportDir, set("report-dir"), envFlags.ReportDir())
+	overrideString(&e.artifactsDir, set("artifacts-dir"), envFlags.ArtifactsDir())
+	overrideString(&e.progress, set("progress"), envFlags.Progress())
+	overrideBool(&e.listMode, set("list"), envFlags.List())
+	overrideString(&e.stateFile, set("state-file"), envFlags.StateFile())
+	overrideBool(&e.rerunFailed, set("rerun-failed"), envFlags.RerunFailed())
+	if set("test-deadline") {
+		e.testDeadline = envFlags.TestDeadline()
 	}
-	e.skipLabels = envFlags.SkipLabels()
-	e.parallelTests = envFlags.Parallel()
-	e.dryRun = envFlags.DryRun()
-	e.failFast = envFlags.FailFast()
-	e.disableGracefulTeardown = envFlags.DisableGracefulTeardown()
-	e.kubeContext = envFlags.KubeContext()
 
 	return e, nil
 }
 
+// overrideString overrides the value of the field with the value of a flag, when the flag is set
+func overrideString(field *string, set bool, value string) {
+	if set {
+		*field = value
+	}
+}
+
+// overrideBool overrides the value of the field with the value of a flag, when the flag is set
+func overrideBool(field *bool, set bool, value bool) {
+	if set {
+		*field = value
+	}
+}
+
+// overrideRegex overrides the regular expression of the field with the value of a flag, when the flag is set.
+// An empty value clears the regular expression.
+func overrideRegex(field **regexp.Regexp, set bool, value string) {
+	if !set {
+		return
+	}
+	*field = nil
+	if value != "" {
+		*field = regexp.MustCompile(value)
+	}
+}
+
 // WithKubeconfigFile creates a new klient.Client and injects it in the cfg
 func (c *Config) WithKubeconfigFile(kubecfg string) *Config {
 	c.kubeconfig = kubecfg
@@ -114,17 +174,11 @@ func (c *Config) GetClient() klient.Client {
 // NewClient is a constructor function that returns a previously
 // created klient.Client or create a new one based on configuration
 // previously set. Will return an error if unable to do so.
-func (c *Config) NewClient() (klient.Client, error) {
-	if c.client != nil {
-		return c.client, nil
-	}
-
-	client, err := klient.NewWithKubeConfigFile(c.kubeconfig)
-	if err != nil {
-		return nil, fmt.Errorf("client failed: %w", err)
-	}
-
-	return client, nil
+//
+// The client of a cluster registered with WithCluster is returned
+// when the name of the cluster is given.
+func (c *Config) NewClient(cluster ...string) (klient.Client, error) {
+	return c.clusterClient(cluster)
 }
 
 // Client is a constructor function that returns a previously
@@ -132,14 +186,13 @@ func (c *Config) NewClient() (klient.Client, error) {
 // previously set. Will panic on any error so it is recommended that you
 // are confident in the configuration or call NewClient() to ensure its
 // safe creation.
-func (c *Config) Client() klient.Client {
-	if c.client != nil {
-		return c.client
-	}
-
-	client, err := klient.NewWithKubeConfigFile(c.kubeconfig)
+//
+// The client of a cluster registered with WithCluster is returned
+// when the name of the cluster is given, e.g. cfg.Client("hub").
+func (c *Config) Client(cluster ...string) klient.Client {
+	client, err := c.clusterClient(cluster)
 	if err != nil {
-		panic(fmt.Errorf("client failed: %w", err).Error())
+		panic(err.Error())
 	}
 	return client
 }
@@ -228,6 +281,64 @@ func (c *Config) SkipLabels() map[string][]string {
 	return c.skipLabels
 }
 
+// WithLabelSelector sets the label requirements, such as `tier in (net,storage), !slow`, that the labels of
+// the features must all meet for the features to be run. It panics if the selector cannot be parsed.
+func (c *Config) WithLabelSelector(selector string) *Config {
+	c.labelSelector = mustParseLabelSelector(selector)
+	return c
+}
+
+// LabelSelector returns the environment's label requirements
+func (c *Config) LabelSelector() flags.LabelSelector {
+	return c.labelSelector
+}
+
+// WithSkipLabelSelector sets the label requirements that cause the features whose labels meet any of them to be
+// skipped. It panics if the selector cannot be parsed.
+func (c *Config) WithSkipLabelSelector(selector string) *Config {
+	c.skipLabelSelector = mustParseLabelSelector(selector)
+	return c
+}
+
+// SkipLabelSelector returns the environment's label requirements used to skip features
+func (c *Config) SkipLabelSelector() flags.LabelSelector {
+	return c.skipLabelSelector
+}
+
+func mustParseLabelSelector(selector string) flags.LabelSelector {
+	parsed, err := flags.ParseLabelSelector(selector)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// WithTestDeadline sets the time the test suite is allowed to run for. Once exceeded, no new feature is started,
+// the features running are cancelled through their context and the finish actions are run.
+func (c *Config) WithTestDeadline(deadline time.Duration) *Config {
+	c.testDeadline = deadline
+	return c
+}
+
+// TestDeadline returns the time the test suite is allowed to run for, zero if there is no deadline
+func (c *Config) TestDeadline() time.Duration {
+	return c.testDeadline
+}
+
+// WithNamespacePerFeature runs every feature with a namespace of its own, as if all the features were marked as
+// isolated. The namespace is randomly named, set as the namespace of the copy of the configuration passed to the
+// feature, created before the feature and deleted after it, waiting for its termination, when a cluster is
+// configured.
+func (c *Config) WithNamespacePerFeature() *Config {
+	c.namespacePerFeature = true
+	return c
+}
+
+// NamespacePerFeature returns true if every feature is run with a namespace of its own
+func (c *Config) NamespacePerFeature() bool {
+	return c.namespacePerFeature
+}
+
 // WithParallelTestEnabled can be used to enable parallel run of the test
 // features
 func (c *Config) WithParallelTestEnabled() *Config {
@@ -250,6 +361,42 @@ func (c *Config) DryRunMode() bool {
 	return c.dryRun
 }
 
+// WithListMode enables the list mode, in which the environment actions and the features are listed instead of
+// being run. The list mode implies the dry-run mode.
+func (c *Config) WithListMode() *Config {
+	c.listMode = true
+	c.dryRun = true
+	return c
+}
+
+// WithStateFile sets the path of the file where the features that failed are written at the end of the run
+func (c *Config) WithStateFile(path string) *Config {
+	c.stateFile = path
+	return c
+}
+
+// StateFile returns the path of the file where the features that failed are written at the end of the run
+func (c *Config) StateFile() string {
+	return c.stateFile
+}
+
+// WithRerunFailed restricts the run to the features that failed in the last run, as written to the state file.
+// All the features are run if the state file does not exist or if none failed.
+func (c *Config) WithRerunFailed() *Config {
+	c.rerunFailed = true
+	return c
+}
+
+// RerunFailed returns true if the run is restricted to the features that failed in the last run
+func (c *Config) RerunFailed() bool {
+	return c.rerunFailed
+}
+
+// ListMode returns true if the environment actions and the features are to be listed instead of being run
+func (c *Config) ListMode() bool {
+	return c.listMode
+}
+
 // WithFailFast can be used to enable framework specific fail fast mode
 // that controls the test execution of the features and assessments under
 // test
@@ -278,7 +425,9 @@ func (c *Config) DisableGracefulTeardown() bool {
 	return c.disableGracefulTeardown
 }
 
-// WithKubeContext is used to set the kubeconfig context
+// WithKubeContext is used to set the kubeconfig context the client
+// created by NewClient and Client targets, instead of the current
+// context of the kubeconfig file
 func (c *Config) WithKubeContext(kubeContext string) *Config {
 	c.kubeContext = kubeContext
 	return c
@@ -289,6 +438,58 @@ func (c *Config) KubeContext() string {
 	return c.kubeContext
 }
 
+// WithInCluster makes NewClient and Client create the client from
+// the service account of the pod the tests run in, which is otherwise
+// only done when no kubeconfig file can be found
+func (c *Config) WithInCluster() *Config {
+	c.inCluster = true
+	return c
+}
+
+// InCluster returns true if the client is created from the service
+// account of the pod the tests run in
+func (c *Config) InCluster() bool {
+	return c.inCluster
+}
+
+// WithReportDir sets the directory the JUnit XML and JSON reports of the features run by the environment are
+// written to once the test suite is done
+func (c *Config) WithReportDir(dir string) *Config {
+	c.reportDir = dir
+	return c
+}
+
+// ReportDir returns the directory the reports of the features run are written to, if any
+func (c *Config) ReportDir() string {
+	return c.reportDir
+}
+
+// WithArtifactsDir enables the collection of the objects, events, pod descriptions and container logs of the
+// namespace of the environment when an assessment fails. They are dumped to dir, in a directory named after the
+// test of the assessment.
+func (c *Config) WithArtifactsDir(dir string) *Config {
+	c.artifactsDir = dir
+	return c
+}
+
+// ArtifactsDir returns the directory the artifacts of the failed assessments are dumped to, if any
+func (c *Config) ArtifactsDir() string {
+	return c.artifactsDir
+}
+
+// WithProgress enables the progress stream of the run: the start and the end of the features, the results of their
+// steps and the durations of the environment setup and finish funcs are written to the file at path as JSON lines
+// as the run proceeds. The events are written to the standard output when path is -.
+func (c *Config) WithProgress(path string) *Config {
+	c.progress = path
+	return c
+}
+
+// Progress returns the path of the file the progress events of the run are written to, if any
+func (c *Config) Progress() string {
+	return c.progress
+}
+
 func randNS() string {
 	return RandomName("testns-", 32)
 }
@@ -17,10 +17,15 @@ limitations under the License.
 package envconf
 
 import (
+	"errors"
 	"flag"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
 )
 
 func TestConfig_New(t *testing.T) {
@@ -87,6 +92,183 @@ func TestConfig_New_WithIgnorePanicRecovery(t *testing.T) {
 	}
 }
 
+func TestConfig_WithCluster(t *testing.T) {
+	cfg := New().WithKubeconfigFile("default").WithCluster("spoke-1", "spoke-1.kubeconfig").WithCluster("hub", "hub.kubeconfig")
+	if got := strings.Join(cfg.Clusters(), ","); got != "hub,spoke-1" {
+		t.Errorf("unexpected clusters: %s", got)
+	}
+	if _, err := cfg.NewClient("missing"); err == nil || !strings.Contains(err.Error(), `cluster "missing" is not registered`) {
+		t.Errorf("expected an error for a cluster that is not registered, got %v", err)
+	}
+
+	cfgCopy := *cfg
+	cfgCopy.WithCurrentCluster("hub").WithCluster("spoke-2", "spoke-2.kubeconfig")
+	if cfgCopy.CurrentCluster() != "hub" || cfgCopy.KubeconfigFile() != "hub.kubeconfig" {
+		t.Errorf("expected the hub to be the current cluster, got %q with %q", cfgCopy.CurrentCluster(), cfgCopy.KubeconfigFile())
+	}
+	if cfg.KubeconfigFile() != "default" || cfg.HasCluster("spoke-2") {
+		t.Error("expected the copy of the configuration not to change the clusters of the original")
+	}
+}
+
+func TestConfig_NewFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "e2e.yaml")
+	content := `kubeconfig: /tmp/kubeconfig
+namespace: e2e
+feature: beta
+labels: "tier in (net,storage), !slow"
+testDeadline: 90m
+provider:
+  image: kindest/node:v1.31.0
+values:
+  registry: registry.example.com
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := NewFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.KubeconfigFile() != "/tmp/kubeconfig" || cfg.Namespace() != "e2e" {
+		t.Errorf("unexpected kubeconfig %s and namespace %s", cfg.KubeconfigFile(), cfg.Namespace())
+	}
+	if cfg.FeatureRegex() == nil || cfg.FeatureRegex().String() != "beta" {
+		t.Errorf("unexpected feature regex %v", cfg.FeatureRegex())
+	}
+	if len(cfg.LabelSelector()) != 2 {
+		t.Errorf("unexpected label selector %v", cfg.LabelSelector())
+	}
+	if cfg.TestDeadline() != 90*time.Minute {
+		t.Errorf("unexpected test deadline %s", cfg.TestDeadline())
+	}
+	if cfg.ProviderOption("image") != "kindest/node:v1.31.0" {
+		t.Errorf("unexpected provider option %s", cfg.ProviderOption("image"))
+	}
+	if value, ok := cfg.Value("registry"); !ok || value != "registry.example.com" {
+		t.Errorf("unexpected value %s", value)
+	}
+
+	if err := os.WriteFile(path, []byte("namespaces: e2e\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFromFile(path); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+}
+
+func TestConfig_NewFromFlags_WithConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "e2e.json")
+	if err := os.WriteFile(path, []byte(`{"namespace": "e2e", "reportDir": "reports", "values": {"registry": "registry.example.com"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"test-binary", "--config", path, "--namespace", "override"}
+	flag.CommandLine = &flag.FlagSet{}
+	cfg, err := NewFromFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Namespace() != "override" {
+		t.Errorf("expected the namespace flag to override the file, got %s", cfg.Namespace())
+	}
+	if cfg.ReportDir() != "reports" {
+		t.Errorf("expected the report dir of the file, got %s", cfg.ReportDir())
+	}
+	if _, ok := cfg.Value("registry"); !ok {
+		t.Error("expected the values of the file")
+	}
+}
+
+func TestConfig_NewFromFlags_DisableConfigFileValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "e2e.json")
+	if err := os.WriteFile(path, []byte(`{"namespace": "e2e", "reportDir": "reports", "parallel": true, "feature": "from-file"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"test-binary", "--config", path, "--parallel=false", "--feature", "", "--namespace="}
+	flag.CommandLine = &flag.FlagSet{}
+	cfg, err := NewFromFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ParallelTestEnabled() {
+		t.Error("expected --parallel=false to disable the parallel runs enabled by the file")
+	}
+	if cfg.FeatureRegex() != nil {
+		t.Errorf("expected an empty --feature to clear the feature regex of the file, got %s", cfg.FeatureRegex())
+	}
+	if cfg.Namespace() != "" {
+		t.Errorf("expected an empty --namespace to clear the namespace of the file, got %s", cfg.Namespace())
+	}
+	if cfg.ReportDir() != "reports" {
+		t.Errorf("expected the report dir of the file to be kept, got %s", cfg.ReportDir())
+	}
+}
+
+func TestConfig_WithKubeContext(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	content := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://hub.example.com
+  name: hub
+- cluster:
+    server: https://spoke.example.com
+  name: spoke
+contexts:
+- context:
+    cluster: hub
+    user: user
+  name: hub
+- context:
+    cluster: spoke
+    user: user
+  name: spoke
+users:
+- name: user
+current-context: hub
+`
+	if err := os.WriteFile(kubeconfig, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := New().WithKubeconfigFile(kubeconfig)
+	client, err := cfg.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host := client.RESTConfig().Host; host != "https://hub.example.com" {
+		t.Errorf("expected the current context to be used, got host %s", host)
+	}
+
+	client, err = cfg.WithKubeContext("spoke").NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host := client.RESTConfig().Host; host != "https://spoke.example.com" {
+		t.Errorf("expected the spoke context to be used, got host %s", host)
+	}
+
+	if _, err := cfg.WithKubeContext("unknown").NewClient(); err == nil {
+		t.Error("expected an error for an unknown context")
+	}
+}
+
+func TestConfig_InCluster(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+	t.Setenv("KUBECONFIG", "")
+	t.Setenv("HOME", t.TempDir())
+
+	if _, err := New().WithInCluster().NewClient(); !errors.Is(err, rest.ErrNotInCluster) {
+		t.Errorf("expected the in-cluster config to be used, got %v", err)
+	}
+	if _, err := New().NewClient(); !errors.Is(err, rest.ErrNotInCluster) {
+		t.Errorf("expected a fallback to the in-cluster config without a kubeconfig, got %v", err)
+	}
+}
+
 func TestRandomName(t *testing.T) {
 	t.Run("no prefix yields random name without dash", func(t *testing.T) {
 		out := RandomName("", 16)
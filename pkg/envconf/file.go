@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envconf
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/e2e-framework/pkg/flags"
+)
+
+// fileConfig is the content of an environment configuration file. Its fields are named after the flags of the
+// framework, e.g.
+//
+//	kubeconfig: /home/ci/.kube/config
+//	namespace: e2e
+//	labels: "tier in (net,storage), !slow"
+//	testDeadline: 1h30m
+//	provider:
+//	  image: kindest/node:v1.31.0
+//	values:
+//	  registry: registry.example.com
+type fileConfig struct {
+	Kubeconfig              string            `json:"kubeconfig"`
+	Context                 string            `json:"context"`
+	InCluster               bool              `json:"inCluster"`
+	Namespace               string            `json:"namespace"`
+	Feature                 string            `json:"feature"`
+	Assess                  string            `json:"assess"`
+	SkipFeatures            string            `json:"skipFeatures"`
+	SkipAssessment          string            `json:"skipAssessment"`
+	Labels                  string            `json:"labels"`
+	SkipLabels              string            `json:"skipLabels"`
+	Parallel                bool              `json:"parallel"`
+	DryRun                  bool              `json:"dryRun"`
+	FailFast                bool              `json:"failFast"`
+	DisableGracefulTeardown bool              `json:"disableGracefulTeardown"`
+	NamespacePerFeature     bool              `json:"namespacePerFeature"`
+	ReportDir               string            `json:"reportDir"`
+	ArtifactsDir            string            `json:"artifactsDir"`
+	Progress                string            `json:"progress"`
+	StateFile               string            `json:"stateFile"`
+	TestDeadline            string            `json:"testDeadline"`
+	Provider                map[string]string `json:"provider"`
+	Values                  map[string]string `json:"values"`
+}
+
+// NewFromFile initializes an environment config from a YAML or JSON file holding the kubeconfig, namespace,
+// filters, timeouts, options of the cluster provider and user values of the environment, see ProviderOption and
+// Value. The keys of the file are named after the flags of the framework, in camel case, e.g. skipLabels for
+// --skip-labels. It returns an error if the file cannot be read or holds unknown keys or invalid values.
+func NewFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	var file fileConfig
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	c := New()
+	c.kubeconfig = file.Kubeconfig
+	c.kubeContext = file.Context
+	c.inCluster = file.InCluster
+	c.namespace = file.Namespace
+	regexps := []struct {
+		field *(*regexp.Regexp)
+		expr  string
+	}{
+		{&c.featureRegex, file.Feature},
+		{&c.assessmentRegex, file.Assess},
+		{&c.skipFeatureRegex, file.SkipFeatures},
+		{&c.skipAssessmentRegex, file.SkipAssessment},
+	}
+	for _, r := range regexps {
+		if r.expr == "" {
+			continue
+		}
+		if *r.field, err = regexp.Compile(r.expr); err != nil {
+			return nil, fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+	if c.labelSelector, err = flags.ParseLabelSelector(file.Labels); err != nil {
+		return nil, fmt.Errorf("config file %s: labels: %w", path, err)
+	}
+	if c.skipLabelSelector, err = flags.ParseLabelSelector(file.SkipLabels); err != nil {
+		return nil, fmt.Errorf("config file %s: skipLabels: %w", path, err)
+	}
+	c.parallelTests = file.Parallel
+	c.dryRun = file.DryRun
+	c.failFast = file.FailFast
+	c.disableGracefulTeardown = file.DisableGracefulTeardown
+	c.namespacePerFeature = file.NamespacePerFeature
+	c.reportDir = file.ReportDir
+	c.artifactsDir = file.ArtifactsDir
+	c.progress = file.Progress
+	c.stateFile = file.StateFile
+	if file.TestDeadline != "" {
+		if c.testDeadline, err = time.ParseDuration(file.TestDeadline); err != nil {
+			return nil, fmt.Errorf("config file %s: testDeadline: %w", path, err)
+		}
+	}
+	c.providerOptions = file.Provider
+	c.values = file.Values
+	if c.failFast && c.parallelTests {
+		return nil, fmt.Errorf("config file %s: failFast and parallel are mutually exclusive options", path)
+	}
+	return c, nil
+}
+
+// WithProviderOption sets an option of the cluster provider, such as the image of the nodes of the cluster
+func (c *Config) WithProviderOption(key, value string) *Config {
+	c.providerOptions = withEntry(c.providerOptions, key, value)
+	return c
+}
+
+// ProviderOption returns the option of the cluster provider set for key, empty if none is set
+func (c *Config) ProviderOption(key string) string {
+	return c.providerOptions[key]
+}
+
+// WithValue sets a user value of the environment, e.g. the address of a registry used by the tests
+func (c *Config) WithValue(key, value string) *Config {
+	c.values = withEntry(c.values, key, value)
+	return c
+}
+
+// Value returns the user value of the environment set for key, and whether one is set
+func (c *Config) Value(key string) (string, bool) {
+	value, ok := c.values[key]
+	return value, ok
+}
+
+// withEntry returns a copy of m with the entry, so that the copies of the configuration do not share it
+func withEntry(m map[string]string, key, value string) map[string]string {
+	entries := make(map[string]string, len(m)+1)
+	for k, v := range m {
+		entries[k] = v
+	}
+	entries[key] = value
+	return entries
+}
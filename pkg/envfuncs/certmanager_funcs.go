@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/klient/decoder"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// DefaultCertManagerVersion is the version of cert-manager installed by SetupCertManager when no version is given
+const DefaultCertManagerVersion = "v1.15.1"
+
+const (
+	certManagerManifestURL = "https://github.com/cert-manager/cert-manager/releases/download/%s/cert-manager.yaml"
+	certManagerNamespace   = "cert-manager"
+	certManagerTimeout     = 5 * time.Minute
+)
+
+// certManagerContextKey is the key of the manifest applied by SetupCertManager for a version of cert-manager
+type certManagerContextKey string
+
+// SetupCertManager provides an Environment.Func that installs the version of cert-manager, e.g. v1.15.1, from the
+// manifest of its release, or DefaultCertManagerVersion if the version is empty. It waits for the deployments of
+// cert-manager to be available and its webhooks to be ready to serve requests, so that the Certificates and Issuers
+// of the tests can be created right away. The installation is removed by TeardownCertManager.
+func SetupCertManager(version string) env.Func {
+	if version == "" {
+		version = DefaultCertManagerVersion
+	}
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		manifest, err := fetchManifest(ctx, fmt.Sprintf(certManagerManifestURL, version))
+		if err != nil {
+			return ctx, fmt.Errorf("setup cert-manager func: %w", err)
+		}
+		r, err := resources.New(cfg.Client().RESTConfig())
+		if err != nil {
+			return ctx, fmt.Errorf("setup cert-manager func: %w", err)
+		}
+		if err := decoder.ApplyDir(ctx, r, manifestFS(manifest), manifestFile, nil); err != nil {
+			return ctx, fmt.Errorf("setup cert-manager func: %w", err)
+		}
+
+		cond := conditions.New(r)
+		waitOpts := []wait.Option{wait.WithContext(ctx), wait.WithImmediate(), wait.WithInterval(time.Second), wait.WithTimeout(certManagerTimeout)}
+		for _, name := range []string{"cert-manager", "cert-manager-cainjector", "cert-manager-webhook"} {
+			if err := wait.For(cond.DeploymentAvailable(name, certManagerNamespace), waitOpts...); err != nil {
+				return ctx, fmt.Errorf("setup cert-manager func: failed to wait for deployment %s: %w", name, err)
+			}
+		}
+		webhooks := []k8s.Object{
+			&admissionregistrationv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "cert-manager-webhook"}},
+			&admissionregistrationv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "cert-manager-webhook"}},
+		}
+		for _, webhook := range webhooks {
+			if err := wait.For(cond.WebhookReady(webhook, true), waitOpts...); err != nil {
+				return ctx, fmt.Errorf("setup cert-manager func: failed to wait for webhook %s: %w", webhook.GetName(), err)
+			}
+		}
+		return context.WithValue(ctx, certManagerContextKey(version), manifest), nil
+	}
+}
+
+// TeardownCertManager provides an Environment.Func that removes the version of cert-manager installed by
+// SetupCertManager, along with its CustomResourceDefinitions and the resources of the tests using them. The version
+// must be the one given to SetupCertManager.
+func TeardownCertManager(version string) env.Func {
+	if version == "" {
+		version = DefaultCertManagerVersion
+	}
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		manifest, ok := ctx.Value(certManagerContextKey(version)).([]byte)
+		if !ok {
+			var err error
+			if manifest, err = fetchManifest(ctx, fmt.Sprintf(certManagerManifestURL, version)); err != nil {
+				return ctx, fmt.Errorf("teardown cert-manager func: %w", err)
+			}
+		}
+		r, err := resources.New(cfg.Client().RESTConfig())
+		if err != nil {
+			return ctx, fmt.Errorf("teardown cert-manager func: %w", err)
+		}
+		if err := decoder.DeleteDir(ctx, r, manifestFS(manifest), manifestFile, nil); err != nil {
+			return ctx, fmt.Errorf("teardown cert-manager func: %w", err)
+		}
+		return ctx, nil
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// WaitForClusterReady returns an EnvFunc that waits for up to timeout for the cluster to be ready to run the
+// tests: all the nodes are Ready, the Deployments of kube-system, such as CoreDNS, are available and the default
+// ServiceAccount of the namespace of the env config, or of the default namespace, has been created. It is meant to
+// be run right after the cluster is created, or the namespace of the tests, as the control plane of a new cluster
+// usually reports being up before it can run the workloads of the tests. The error returned on timeout tells which
+// of the checks was not met.
+func WaitForClusterReady(timeout time.Duration) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("wait for cluster ready func: %w", err)
+		}
+		namespace := cfg.Namespace()
+		if namespace == "" {
+			namespace = "default"
+		}
+		err = wait.For(func(ctx context.Context) (bool, error) {
+			return clusterReady(ctx, client.Resources(), namespace), nil
+		}, wait.WithContext(ctx), wait.WithImmediate(), wait.WithInterval(time.Second), wait.WithTimeout(timeout))
+		if err != nil {
+			return ctx, fmt.Errorf("wait for cluster ready func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// clusterReady checks whether the cluster is ready, see WaitForClusterReady. The errors of the API server are
+// reported as the reason the cluster is not ready, as the API server can still be starting.
+func clusterReady(ctx context.Context, r *resources.Resources, namespace string) bool {
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		wait.ReportUnmet(ctx, "failed to list nodes: %s", err)
+		return false
+	}
+	if len(nodes.Items) == 0 {
+		wait.ReportUnmet(ctx, "no node registered")
+		return false
+	}
+	for _, node := range nodes.Items {
+		ready := false
+		for _, cond := range node.Status.Conditions {
+			if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue {
+				ready = true
+			}
+		}
+		if !ready {
+			wait.ReportUnmet(ctx, "node %s is not ready", node.Name)
+			return false
+		}
+	}
+
+	var deployments appsv1.DeploymentList
+	if err := r.GetControllerRuntimeClient().List(ctx, &deployments, cr.InNamespace("kube-system")); err != nil {
+		wait.ReportUnmet(ctx, "failed to list deployments of kube-system: %s", err)
+		return false
+	}
+	for _, deployment := range deployments.Items {
+		available := false
+		for _, cond := range deployment.Status.Conditions {
+			if cond.Type == appsv1.DeploymentAvailable && cond.Status == corev1.ConditionTrue {
+				available = true
+			}
+		}
+		if !available {
+			wait.ReportUnmet(ctx, "deployment kube-system/%s is not available", deployment.Name)
+			return false
+		}
+	}
+
+	if err := r.Get(ctx, "default", namespace, &corev1.ServiceAccount{}); err != nil {
+		wait.ReportUnmet(ctx, "default service account of namespace %s not found: %s", namespace, err)
+		return false
+	}
+	return true
+}
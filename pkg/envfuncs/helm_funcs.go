@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	klog "k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/third_party/helm"
+)
+
+// InstallHelmChart provides an Environment.Func that installs the chart as the release in the namespace with the
+// helm binary, see the helm package, creating the namespace if needed. The chart is a reference to a chart of a
+// repository added beforehand, such as nginx-stable/nginx-ingress, or the path of a local chart. The values are
+// set on the chart as key=value pairs, the same way --set does.
+//
+// The func waits for the resources of the release to be ready, and the notes rendered by the chart are logged once
+// it is installed. The error returned when the install fails includes the error output of helm.
+func InstallHelmChart(release, chart, namespace string, values ...string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		args := []string{"--create-namespace"}
+		for _, value := range values {
+			args = append(args, "--set", value)
+		}
+		var out strings.Builder
+		err := helm.New(cfg.KubeconfigFile()).RunInstall(
+			helm.WithName(release),
+			helm.WithChart(chart),
+			helm.WithNamespace(namespace),
+			helm.WithArgs(args...),
+			helm.WithWait(),
+			helm.WithOutput(&out),
+		)
+		if err != nil {
+			return ctx, fmt.Errorf("install helm chart func: release %s of chart %s: %w", release, chart, err)
+		}
+		klog.Infof("Installed helm release %s of chart %s in namespace %s:\n%s", release, chart, namespace, out.String())
+		return ctx, nil
+	}
+}
+
+// UninstallHelmChart provides an Environment.Func that uninstalls the release installed in the namespace by
+// InstallHelmChart, and waits for the resources of the release to be deleted.
+func UninstallHelmChart(release, namespace string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		err := helm.New(cfg.KubeconfigFile()).RunUninstall(
+			helm.WithName(release),
+			helm.WithNamespace(namespace),
+			helm.WithWait(),
+		)
+		if err != nil {
+			return ctx, fmt.Errorf("uninstall helm chart func: release %s: %w", release, err)
+		}
+		return ctx, nil
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/klient/decoder"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/support/kind"
+)
+
+// DefaultIngressNginxVersion is the version of ingress-nginx installed by SetupIngressNginx when no version is given
+const DefaultIngressNginxVersion = "v1.10.1"
+
+// IngressValueKey is the key of the envconf.Config value set by SetupIngressNginx to the address the ingresses of
+// the cluster are reachable at from the host, e.g. http://localhost, see envconf.Config.Value
+const IngressValueKey = "ingress"
+
+const (
+	ingressNginxManifestURL = "https://raw.githubusercontent.com/kubernetes/ingress-nginx/controller-%s/deploy/static/provider/%s/deploy.yaml"
+	ingressNginxNamespace   = "ingress-nginx"
+	ingressNginxController  = "ingress-nginx-controller"
+	ingressNginxTimeout     = 5 * time.Minute
+)
+
+// ingressNginxContextKey is the key of the manifest applied by SetupIngressNginx for a version of ingress-nginx
+type ingressNginxContextKey string
+
+// SetupIngressNginx provides an Environment.Func that installs the version of the ingress-nginx controller, e.g.
+// v1.10.1, or DefaultIngressNginxVersion if the version is empty, with the manifest of its release suited to the
+// cluster created last by CreateCluster or CreateClusterWithConfig. It waits for the controller to be available and
+// its admission webhook to be ready to serve requests, and sets the address the ingresses are reachable at from the
+// host as the IngressValueKey value of the env config, so that the tests can send HTTP requests to their ingresses.
+//
+// For a kind cluster, the controller listens on the ports 80 and 443 of the node labeled ingress-ready=true, and the
+// ingresses are reachable at http://localhost. The cluster must be created with a kind config mapping these ports of
+// the node to the host:
+//
+//	nodes:
+//	- role: control-plane
+//	  kubeadmConfigPatches:
+//	  - |
+//	    kind: InitConfiguration
+//	    nodeRegistration:
+//	      kubeletExtraArgs:
+//	        node-labels: "ingress-ready=true"
+//	  extraPortMappings:
+//	  - containerPort: 80
+//	    hostPort: 80
+//	  - containerPort: 443
+//	    hostPort: 443
+//
+// For the other clusters, the controller is exposed by a LoadBalancer service, and the ingresses are reachable at the
+// address of its load balancer, once provisioned. The installation is removed by TeardownIngressNginx.
+func SetupIngressNginx(version string) env.Func {
+	if version == "" {
+		version = DefaultIngressNginxVersion
+	}
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		_, onKind := ctx.Value(activeClusterContextKey{}).(*kind.Cluster)
+		provider := "cloud"
+		if onKind {
+			provider = "kind"
+		}
+		manifest, err := fetchManifest(ctx, fmt.Sprintf(ingressNginxManifestURL, version, provider))
+		if err != nil {
+			return ctx, fmt.Errorf("setup ingress-nginx func: %w", err)
+		}
+		r, err := resources.New(cfg.Client().RESTConfig())
+		if err != nil {
+			return ctx, fmt.Errorf("setup ingress-nginx func: %w", err)
+		}
+		if err := decoder.ApplyDir(ctx, r, manifestFS(manifest), manifestFile, nil); err != nil {
+			return ctx, fmt.Errorf("setup ingress-nginx func: %w", err)
+		}
+
+		cond := conditions.New(r)
+		waitOpts := []wait.Option{wait.WithContext(ctx), wait.WithImmediate(), wait.WithInterval(time.Second), wait.WithTimeout(ingressNginxTimeout)}
+		if err := wait.For(cond.DeploymentAvailable(ingressNginxController, ingressNginxNamespace), waitOpts...); err != nil {
+			return ctx, fmt.Errorf("setup ingress-nginx func: failed to wait for deployment %s: %w", ingressNginxController, err)
+		}
+		webhook := &admissionregistrationv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx-admission"}}
+		if err := wait.For(cond.WebhookReady(webhook, true), waitOpts...); err != nil {
+			return ctx, fmt.Errorf("setup ingress-nginx func: failed to wait for webhook %s: %w", webhook.Name, err)
+		}
+
+		address := "http://localhost"
+		if !onKind {
+			service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: ingressNginxController, Namespace: ingressNginxNamespace}}
+			if err := wait.For(cond.ResourceMatch(service, loadBalancerProvisioned), waitOpts...); err != nil {
+				return ctx, fmt.Errorf("setup ingress-nginx func: failed to wait for the load balancer of service %s: %w", service.Name, err)
+			}
+			ingress := service.Status.LoadBalancer.Ingress[0]
+			host := ingress.IP
+			if host == "" {
+				host = ingress.Hostname
+			}
+			address = "http://" + host
+		}
+		cfg.WithValue(IngressValueKey, address)
+		return context.WithValue(ctx, ingressNginxContextKey(version), manifest), nil
+	}
+}
+
+// TeardownIngressNginx provides an Environment.Func that removes the version of ingress-nginx installed by
+// SetupIngressNginx. The version must be the one given to SetupIngressNginx.
+func TeardownIngressNginx(version string) env.Func {
+	if version == "" {
+		version = DefaultIngressNginxVersion
+	}
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		manifest, ok := ctx.Value(ingressNginxContextKey(version)).([]byte)
+		if !ok {
+			return ctx, fmt.Errorf("teardown ingress-nginx func: ingress-nginx %s was not installed by SetupIngressNginx", version)
+		}
+		r, err := resources.New(cfg.Client().RESTConfig())
+		if err != nil {
+			return ctx, fmt.Errorf("teardown ingress-nginx func: %w", err)
+		}
+		if err := decoder.DeleteDir(ctx, r, manifestFS(manifest), manifestFile, nil); err != nil {
+			return ctx, fmt.Errorf("teardown ingress-nginx func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// loadBalancerProvisioned returns whether the load balancer of the service has an address
+func loadBalancerProvisioned(obj k8s.Object) bool {
+	ingress := obj.(*corev1.Service).Status.LoadBalancer.Ingress
+	return len(ingress) > 0 && (ingress[0].IP != "" || ingress[0].Hostname != "")
+}
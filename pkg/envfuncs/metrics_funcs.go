@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/e2e-framework/klient/decoder"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// DefaultMetricsServerVersion is the version of metrics-server installed by SetupMetricsServer when no version is
+// given
+const DefaultMetricsServerVersion = "v0.7.1"
+
+const (
+	metricsServerManifestURL = "https://github.com/kubernetes-sigs/metrics-server/releases/download/%s/components.yaml"
+	metricsServerName        = "metrics-server"
+	metricsServerAPIService  = "v1beta1.metrics.k8s.io"
+	metricsServerTimeout     = 5 * time.Minute
+)
+
+// SetupMetricsServer provides an Environment.Func that installs the version of metrics-server, e.g. v0.7.1, from
+// the manifest of its release, or DefaultMetricsServerVersion if the version is empty. metrics-server is started
+// with --kubelet-insecure-tls, as the kubelets of the local clusters such as kind serve self-signed certificates.
+// It waits for the metrics API to be available, so that the HorizontalPodAutoscalers and the tests reading the
+// resource usage of the pods work right away.
+func SetupMetricsServer(version string) env.Func {
+	if version == "" {
+		version = DefaultMetricsServerVersion
+	}
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		manifest, err := fetchManifest(ctx, fmt.Sprintf(metricsServerManifestURL, version))
+		if err != nil {
+			return ctx, fmt.Errorf("setup metrics-server func: %w", err)
+		}
+		r, err := resources.New(cfg.Client().RESTConfig())
+		if err != nil {
+			return ctx, fmt.Errorf("setup metrics-server func: %w", err)
+		}
+		if err := decoder.ApplyDir(ctx, r, manifestFS(manifest), manifestFile, nil, decoder.MutateOption(insecureKubeletTLS)); err != nil {
+			return ctx, fmt.Errorf("setup metrics-server func: %w", err)
+		}
+
+		waitOpts := []wait.Option{wait.WithContext(ctx), wait.WithImmediate(), wait.WithInterval(time.Second), wait.WithTimeout(metricsServerTimeout)}
+		if err := wait.For(conditions.New(r).DeploymentAvailable(metricsServerName, "kube-system"), waitOpts...); err != nil {
+			return ctx, fmt.Errorf("setup metrics-server func: failed to wait for deployment %s: %w", metricsServerName, err)
+		}
+		if err := wait.For(conditions.New(r).APIServiceAvailable(metricsServerAPIService), waitOpts...); err != nil {
+			return ctx, fmt.Errorf("setup metrics-server func: failed to wait for the metrics API: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// TeardownMetricsServer provides an Environment.Func that removes the version of metrics-server installed by
+// SetupMetricsServer. The version must be the one given to SetupMetricsServer.
+func TeardownMetricsServer(version string) env.Func {
+	if version == "" {
+		version = DefaultMetricsServerVersion
+	}
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		manifest, err := fetchManifest(ctx, fmt.Sprintf(metricsServerManifestURL, version))
+		if err != nil {
+			return ctx, fmt.Errorf("teardown metrics-server func: %w", err)
+		}
+		r, err := resources.New(cfg.Client().RESTConfig())
+		if err != nil {
+			return ctx, fmt.Errorf("teardown metrics-server func: %w", err)
+		}
+		if err := decoder.DeleteDir(ctx, r, manifestFS(manifest), manifestFile, nil); err != nil {
+			return ctx, fmt.Errorf("teardown metrics-server func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// insecureKubeletTLS adds the --kubelet-insecure-tls flag to the metrics-server container of its Deployment
+func insecureKubeletTLS(obj k8s.Object) error {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok || deployment.Name != metricsServerName {
+		return nil
+	}
+	for i, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name == metricsServerName {
+			deployment.Spec.Template.Spec.Containers[i].Args = append(container.Args, "--kubelet-insecure-tls")
+		}
+	}
+	return nil
+}
@@ -19,10 +19,15 @@ package envfuncs
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
 	"sigs.k8s.io/e2e-framework/pkg/env"
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 )
@@ -45,6 +50,33 @@ func WithAnnotations(annotations map[string]string) CreateNamespaceOpts {
 	}
 }
 
+// The levels of the Pod Security Standards that can be set on a namespace with WithPodSecurity.
+const (
+	PodSecurityPrivileged = "privileged"
+	PodSecurityBaseline   = "baseline"
+	PodSecurityRestricted = "restricted"
+)
+
+// WithPodSecurity provides an option to set the Pod Security Standards level of the namespace with the
+// pod-security.kubernetes.io labels of the Pod Security admission. The level is enforced, audited and warned
+// about, unless the modes are given, e.g. "warn" to only warn about the pods violating the level. Unlike
+// WithLabels, it keeps the labels already set on the namespace.
+func WithPodSecurity(level string, modes ...string) CreateNamespaceOpts {
+	if len(modes) == 0 {
+		modes = []string{"enforce", "audit", "warn"}
+	}
+	return func(_ klient.Client, ns *corev1.Namespace) {
+		labels := make(map[string]string, len(ns.GetLabels())+len(modes))
+		for k, v := range ns.GetLabels() {
+			labels[k] = v
+		}
+		for _, mode := range modes {
+			labels["pod-security.kubernetes.io/"+mode] = level
+		}
+		ns.SetLabels(labels)
+	}
+}
+
 // CreateNamespace provides an Environment.Func that
 // creates a new namespace API object and stores it the context
 // using its name as key.
@@ -112,3 +144,73 @@ func DeleteNamespace(name string) env.Func {
 		return ctx, nil
 	}
 }
+
+// serviceAccountTimeout is how long CreateImagePullSecret waits for the default ServiceAccount of the namespace
+const serviceAccountTimeout = time.Minute
+
+// CreateImagePullSecret provides an Environment.Func that creates an image pull secret with the Docker config
+// dockerConfigJSON, e.g. the content of ~/.docker/config.json, in the namespace, and adds it to the image pull
+// secrets of the default ServiceAccount of the namespace, so that the pods of the namespace can pull the images
+// of a private registry without having to reference the secret. It waits for the default ServiceAccount to be
+// created by the cluster if needed. It is meant to be run right after CreateNamespace.
+func CreateImagePullSecret(namespace, name string, dockerConfigJSON []byte) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("create image pull secret func: %w", err)
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+		}
+		if err := client.Resources().Create(ctx, secret); err != nil {
+			return ctx, fmt.Errorf("create image pull secret func: %w", err)
+		}
+
+		sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: namespace}}
+		exists := func(k8s.Object) bool { return true }
+		err = wait.For(conditions.New(client.Resources()).ResourceMatch(sa, exists), wait.WithContext(ctx), wait.WithImmediate(), wait.WithInterval(time.Second), wait.WithTimeout(serviceAccountTimeout))
+		if err != nil {
+			return ctx, fmt.Errorf("create image pull secret func: failed to wait for the default service account: %w", err)
+		}
+		err = client.Resources().UpdateWithRetry(ctx, sa, func(obj k8s.Object) error {
+			sa := obj.(*corev1.ServiceAccount)
+			for _, ref := range sa.ImagePullSecrets {
+				if ref.Name == name {
+					return nil
+				}
+			}
+			sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+			return nil
+		})
+		if err != nil {
+			return ctx, fmt.Errorf("create image pull secret func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// CreateDefaultDenyNetworkPolicy provides an Environment.Func that creates a NetworkPolicy named default-deny in
+// the namespace selecting all of its pods and denying all their ingress traffic, as commonly done for the
+// namespaces of production workloads, so that the tests can check the NetworkPolicies their workloads rely on.
+// The policy is only enforced if the network plugin of the cluster supports NetworkPolicies.
+func CreateDefaultDenyNetworkPolicy(namespace string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("create default deny network policy func: %w", err)
+		}
+		policy := &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: namespace},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{},
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			},
+		}
+		if err := client.Resources().Create(ctx, policy); err != nil {
+			return ctx, fmt.Errorf("create default deny network policy func: %w", err)
+		}
+		return ctx, nil
+	}
+}
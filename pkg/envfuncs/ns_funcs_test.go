@@ -22,12 +22,14 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
 	"sigs.k8s.io/e2e-framework/klient/wait"
 	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/env"
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/e2e-framework/pkg/envfuncs"
 	"sigs.k8s.io/e2e-framework/pkg/features"
@@ -53,6 +55,14 @@ func TestCreateNamespace(t *testing.T) {
 			expectedLabels:      labels,
 			expectedAnnotations: annotations,
 		},
+		{
+			name: "CreateNamespaceWithPodSecurity",
+			opts: []envfuncs.CreateNamespaceOpts{envfuncs.WithLabels(labels), envfuncs.WithPodSecurity(envfuncs.PodSecurityRestricted, "enforce")},
+			expectedLabels: map[string]string{
+				"label":                              "myns-label",
+				"pod-security.kubernetes.io/enforce": "restricted",
+			},
+		},
 	}
 
 	feats := make([]features.Feature, 0, len(tests))
@@ -156,3 +166,50 @@ func TestDeleteNamespace(t *testing.T) {
 
 	nsTestenv.Test(t, feat)
 }
+
+func TestCreateNamespaceDefaults(t *testing.T) {
+	namespace := envconf.RandomName("defaults-ns", 16)
+	feat := features.New("CreateNamespaceDefaults").
+		Setup(func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			funcs := []env.Func{
+				envfuncs.CreateNamespace(namespace, envfuncs.WithPodSecurity(envfuncs.PodSecurityRestricted)),
+				envfuncs.CreateImagePullSecret(namespace, "registry", []byte(`{"auths":{}}`)),
+				envfuncs.CreateDefaultDenyNetworkPolicy(namespace),
+			}
+			for _, fn := range funcs {
+				var err error
+				if ctx, err = fn(ctx, cfg); err != nil {
+					t.Fatal("Error creating namespace defaults", err)
+				}
+			}
+			return ctx
+		}).
+		Assess("image pull secret added to the default service account", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			var sa corev1.ServiceAccount
+			if err := cfg.Client().Resources().Get(ctx, "default", namespace, &sa); err != nil {
+				t.Fatal("error getting service account", err)
+			}
+			expected := []corev1.LocalObjectReference{{Name: "registry"}}
+			if !reflect.DeepEqual(expected, sa.ImagePullSecrets) {
+				t.Errorf("service account image pull secrets do not match. Expected:\n%v but got:\n%v", expected, sa.ImagePullSecrets)
+			}
+			return ctx
+		}).
+		Assess("network policy created", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			var policy networkingv1.NetworkPolicy
+			if err := cfg.Client().Resources().Get(ctx, "default-deny", namespace, &policy); err != nil {
+				t.Error("error getting network policy", err)
+			}
+			return ctx
+		}).
+		Teardown(func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			ctx, err := envfuncs.DeleteNamespace(namespace)(ctx, cfg)
+			if err != nil {
+				t.Error("Error deleting namespace", err)
+			}
+			return ctx
+		}).
+		Feature()
+
+	nsTestenv.Test(t, feat)
+}
@@ -18,7 +18,9 @@ package envfuncs
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 
 	"sigs.k8s.io/e2e-framework/pkg/env"
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
@@ -27,6 +29,9 @@ import (
 
 type clusterNameContextKey string
 
+// activeClusterContextKey is the key of the cluster created last by CreateCluster or CreateClusterWithConfig
+type activeClusterContextKey struct{}
+
 var LoadDockerImageToCluster = LoadImageToCluster
 
 // GetClusterFromContext helps extract the E2EClusterProvider object from the context.
@@ -40,6 +45,12 @@ func GetClusterFromContext(ctx context.Context, clusterName string) (support.E2E
 	return cluster, ok
 }
 
+// withCluster stores the cluster in the context under its name, and as the active cluster of the context
+func withCluster(ctx context.Context, clusterName string, cluster support.E2EClusterProvider) context.Context {
+	ctx = context.WithValue(ctx, clusterNameContextKey(clusterName), cluster)
+	return context.WithValue(ctx, activeClusterContextKey{}, cluster)
+}
+
 // CreateCluster returns an env.Func that is used to
 // create an E2E provider cluster that is then injected in the context
 // using the name as a key.
@@ -63,7 +74,7 @@ func CreateCluster(p support.E2EClusterProvider, clusterName string) env.Func {
 		}
 
 		// store entire cluster value in ctx for future access using the cluster name
-		return context.WithValue(ctx, clusterNameContextKey(clusterName), k), nil
+		return withCluster(ctx, clusterName, k), nil
 	}
 }
 
@@ -90,7 +101,7 @@ func CreateClusterWithConfig(p support.E2EClusterProvider, clusterName, configFi
 		}
 
 		// store entire cluster value in ctx for future access using the cluster name
-		return context.WithValue(ctx, clusterNameContextKey(clusterName), k), nil
+		return withCluster(ctx, clusterName, k), nil
 	}
 }
 
@@ -141,6 +152,31 @@ func LoadImageToCluster(name, image string) env.Func {
 	}
 }
 
+// LoadImageToActiveCluster returns an EnvFunc that loads a container image from the host into the cluster created
+// last by CreateCluster or CreateClusterWithConfig, without having to know its name or the provider that created it.
+// The image is loaded with the native workflow of that provider, e.g. kind load docker-image for a kind cluster, so
+// that the suites building an image locally run the same way on any provider implementing
+// support.E2EClusterProviderWithImageLoader.
+func LoadImageToActiveCluster(image string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		clusterVal := ctx.Value(activeClusterContextKey{})
+		if clusterVal == nil {
+			return ctx, fmt.Errorf("load image func: no cluster was created in the context")
+		}
+
+		cluster, ok := clusterVal.(support.E2EClusterProviderWithImageLoader)
+		if !ok {
+			return ctx, fmt.Errorf("load image func: cluster provider %T does not support LoadImage helper", clusterVal)
+		}
+
+		if err := cluster.LoadImage(ctx, image); err != nil {
+			return ctx, fmt.Errorf("load image: %w", err)
+		}
+
+		return ctx, nil
+	}
+}
+
 // LoadImageArchiveToCluster returns an EnvFunc that
 // retrieves a previously saved e2e provider Cluster in the context (using the name), and then loads a container image TAR archive
 // from the host into the cluster.
@@ -186,3 +222,56 @@ func ExportClusterLogs(name, dest string) env.Func {
 		return ctx, nil
 	}
 }
+
+// CollectClusterLogs returns an EnvFunc, meant to be run as a Finish step, that collects the logs of the cluster
+// into dir, or into the cluster directory of the artifacts directory of the env config if dir is empty:
+//
+//   - the logs of the nodes, including the journals of the kubelet and the container runtime, exported in
+//     dir/nodes by the provider of the cluster previously saved in the context under clusterName, see
+//     ExportClusterLogs. They are skipped if no cluster was saved under clusterName, e.g. for an existing cluster;
+//   - the objects, events, pod descriptions and container logs of kube-system, in dir/kube-system, see
+//     env.CollectArtifacts.
+//
+// If onFailure is set, the logs are only collected when a feature of the test suite failed, see env.TestResults.
+// The collection carries on after an error to gather as many logs as possible.
+func CollectClusterLogs(clusterName, dir string, onFailure bool) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		if onFailure && !suiteFailed(ctx) {
+			return ctx, nil
+		}
+		dir := dir
+		if dir == "" {
+			if cfg.ArtifactsDir() == "" {
+				return ctx, fmt.Errorf("collect cluster logs: no directory given and no artifacts directory configured")
+			}
+			dir = filepath.Join(cfg.ArtifactsDir(), "cluster")
+		}
+
+		var errs []error
+		if cluster, ok := GetClusterFromContext(ctx, clusterName); ok {
+			if err := cluster.ExportLogs(ctx, filepath.Join(dir, "nodes")); err != nil {
+				errs = append(errs, fmt.Errorf("export node logs: %w", err))
+			}
+		}
+		client, err := cfg.NewClient()
+		if err != nil {
+			errs = append(errs, err)
+		} else if err := env.CollectArtifacts(ctx, client, "kube-system", filepath.Join(dir, "kube-system")); err != nil {
+			errs = append(errs, err)
+		}
+		if err := errors.Join(errs...); err != nil {
+			return ctx, fmt.Errorf("collect cluster logs: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// suiteFailed returns whether a feature of the test suite, whose results are carried by ctx, failed
+func suiteFailed(ctx context.Context) bool {
+	for _, result := range env.TestResults(ctx) {
+		if result.Failed() {
+			return true
+		}
+	}
+	return false
+}
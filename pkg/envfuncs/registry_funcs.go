@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/support"
+	"sigs.k8s.io/e2e-framework/support/utils"
+)
+
+// RegistryValueKey is the key of the envconf.Config value set by SetupLocalRegistry to the host of the registry,
+// e.g. localhost:5001, to which the tests push the images they build, see envconf.Config.Value
+const RegistryValueKey = "registry"
+
+// registryImage is the image of the registry run by SetupLocalRegistry
+const registryImage = "registry:2"
+
+// SetupLocalRegistry returns an EnvFunc that runs a local registry as a docker container named registryName,
+// published on the port of localhost, unless the container already exists, and wires it into the cluster created
+// in the context under clusterName, see support.E2EClusterProviderWithRegistry. The images pushed to
+// localhost:port from the host can then be used by the pods of the cluster under the same name, without an
+// external registry. The registry is also advertised to the tools run against the cluster with the
+// local-registry-hosting ConfigMap of kube-public.
+//
+// The host of the registry is set as the RegistryValueKey value of the env config. The registry is removed by
+// TeardownLocalRegistry.
+func SetupLocalRegistry(clusterName, registryName string, port int) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		cluster, ok := GetClusterFromContext(ctx, clusterName)
+		if !ok {
+			return ctx, fmt.Errorf("setup local registry func: context cluster is nil")
+		}
+		withRegistry, ok := cluster.(support.E2EClusterProviderWithRegistry)
+		if !ok {
+			return ctx, fmt.Errorf("setup local registry func: cluster provider %T does not support ConnectRegistry helper", cluster)
+		}
+
+		if p := utils.RunCommand(fmt.Sprintf("docker inspect %s", registryName)); p.Err() != nil {
+			p = utils.RunCommand(fmt.Sprintf("docker run -d --restart=always -p 127.0.0.1:%d:5000 --name %s %s", port, registryName, registryImage))
+			if p.Err() != nil {
+				return ctx, fmt.Errorf("setup local registry func: run registry %s: %s: %s", registryName, p.Err(), p.Result())
+			}
+		}
+
+		host := fmt.Sprintf("localhost:%d", port)
+		if err := withRegistry.ConnectRegistry(ctx, registryName, host); err != nil {
+			return ctx, fmt.Errorf("setup local registry func: %w", err)
+		}
+
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("setup local registry func: %w", err)
+		}
+		hosting := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "local-registry-hosting", Namespace: "kube-public"},
+			Data: map[string]string{
+				"localRegistryHosting.v1": fmt.Sprintf("host: %q\nhelp: \"https://kind.sigs.k8s.io/docs/user/local-registry/\"\n", host),
+			},
+		}
+		if err := client.Resources().Create(ctx, hosting); err != nil && !errors.IsAlreadyExists(err) {
+			return ctx, fmt.Errorf("setup local registry func: %w", err)
+		}
+
+		cfg.WithValue(RegistryValueKey, host)
+		return ctx, nil
+	}
+}
+
+// TeardownLocalRegistry returns an EnvFunc that removes the registry container started by SetupLocalRegistry,
+// along with the images pushed to it.
+func TeardownLocalRegistry(registryName string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		if p := utils.RunCommand(fmt.Sprintf("docker rm -f -v %s", registryName)); p.Err() != nil {
+			return ctx, fmt.Errorf("teardown local registry func: remove registry %s: %s: %s", registryName, p.Err(), p.Result())
+		}
+		return ctx, nil
+	}
+}
@@ -18,9 +18,18 @@ package envfuncs
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"testing/fstest"
+	"time"
 
 	"sigs.k8s.io/e2e-framework/klient/decoder"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
 	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
 	"sigs.k8s.io/e2e-framework/pkg/env"
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 )
@@ -49,3 +58,150 @@ func TeardownCRDs(crdPath, pattern string) env.Func {
 		return ctx, decoder.DeleteWithManifestDir(ctx, r, crdPath, pattern, []resources.DeleteOption{})
 	}
 }
+
+// ApplyManifests is provided as a helper env.Func handler that server-side applies the resources found in the files
+// of fsys matching the pattern, such as the fixtures shared by the tests of a suite, see decoder.ApplyDir. The
+// resources are applied in the order of their dependencies, and the CustomResourceDefinitions among them are waited
+// for to be established before the custom resources are applied. The options are used to decode the files, e.g.
+// decoder.MutateNamespace to install the resources in the namespace of the suite.
+func ApplyManifests(fsys fs.FS, pattern string, options ...decoder.DecodeOption) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		r, err := resources.New(c.Client().RESTConfig())
+		if err != nil {
+			return ctx, err
+		}
+		if err := decoder.ApplyDir(ctx, r, fsys, pattern, nil, options...); err != nil {
+			return ctx, fmt.Errorf("apply manifests func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// DeleteManifests is provided as a handler function that can be hooked into your test's teardown sequence to delete
+// the resources applied by the ApplyManifests hook with the same arguments, in the reverse order they were applied
+// in, see decoder.DeleteDir. The resources that are already gone are ignored.
+func DeleteManifests(fsys fs.FS, pattern string, options ...decoder.DecodeOption) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		r, err := resources.New(c.Client().RESTConfig())
+		if err != nil {
+			return ctx, err
+		}
+		if err := decoder.DeleteDir(ctx, r, fsys, pattern, nil, options...); err != nil {
+			return ctx, fmt.Errorf("delete manifests func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// InstallCRDs is provided as a helper env.Func handler that server-side applies the CustomResourceDefinitions found
+// in the files of fsys matching the pattern, and waits for all of them to be established before returning, so that
+// the custom resources can be created right away. The other resources found in the files, such as a kustomization,
+// are ignored. The CRDs are waited for with an interval of one second and a timeout of one minute, which can be
+// changed with waitOpts.
+func InstallCRDs(fsys fs.FS, pattern string, waitOpts ...wait.Option) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		r, err := resources.New(c.Client().RESTConfig())
+		if err != nil {
+			return ctx, err
+		}
+		crds, err := decodeCRDs(ctx, fsys, pattern)
+		if err != nil {
+			return ctx, err
+		}
+		handler := decoder.ApplyHandler(r)
+		for _, crd := range crds {
+			if err := handler(ctx, crd); err != nil {
+				return ctx, fmt.Errorf("failed to apply CustomResourceDefinition %s: %w", crd.GetName(), err)
+			}
+		}
+		cond := conditions.New(r)
+		for _, crd := range crds {
+			if err := wait.For(cond.CRDEstablished(crd.GetName()), crdWaitOptions(ctx, waitOpts)...); err != nil {
+				return ctx, fmt.Errorf("failed to wait for CustomResourceDefinition %s to be established: %w", crd.GetName(), err)
+			}
+		}
+		return ctx, nil
+	}
+}
+
+// UninstallCRDs is provided as a handler function that can be hooked into your test's teardown sequence to delete
+// the CustomResourceDefinitions installed by the InstallCRDs hook with the same fsys and pattern. It waits for the
+// CRDs, along with their custom resources, to be gone, so that a later suite can install them again. The CRDs that
+// are already gone are ignored.
+func UninstallCRDs(fsys fs.FS, pattern string, waitOpts ...wait.Option) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		r, err := resources.New(c.Client().RESTConfig())
+		if err != nil {
+			return ctx, err
+		}
+		crds, err := decodeCRDs(ctx, fsys, pattern)
+		if err != nil {
+			return ctx, err
+		}
+		handler := decoder.DeleteIgnoreNotFound(r)
+		for _, crd := range crds {
+			if err := handler(ctx, crd); err != nil {
+				return ctx, fmt.Errorf("failed to delete CustomResourceDefinition %s: %w", crd.GetName(), err)
+			}
+		}
+		cond := conditions.New(r)
+		for _, crd := range crds {
+			if err := wait.For(cond.ResourceDeleted(crd), crdWaitOptions(ctx, waitOpts)...); err != nil {
+				return ctx, fmt.Errorf("failed to wait for CustomResourceDefinition %s to be deleted: %w", crd.GetName(), err)
+			}
+		}
+		return ctx, nil
+	}
+}
+
+// crdEstablishedTimeout is how long InstallCRDs and UninstallCRDs wait for each CustomResourceDefinition by default
+const crdEstablishedTimeout = time.Minute
+
+// crdWaitOptions returns the options of the waits of InstallCRDs and UninstallCRDs, the defaults followed by opts
+func crdWaitOptions(ctx context.Context, opts []wait.Option) []wait.Option {
+	return append([]wait.Option{wait.WithContext(ctx), wait.WithImmediate(), wait.WithInterval(time.Second), wait.WithTimeout(crdEstablishedTimeout)}, opts...)
+}
+
+// decodeCRDs returns the CustomResourceDefinitions found in the files of fsys matching the pattern
+func decodeCRDs(ctx context.Context, fsys fs.FS, pattern string) ([]k8s.Object, error) {
+	objects, err := decoder.DecodeAllFiles(ctx, fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	var crds []k8s.Object
+	for _, obj := range objects {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		if gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition" {
+			crds = append(crds, obj)
+		}
+	}
+	if len(crds) == 0 {
+		return nil, fmt.Errorf("no CustomResourceDefinition found in the files matching %s", pattern)
+	}
+	return crds, nil
+}
+
+// manifestFile is the name of the file of the filesystem returned by manifestFS
+const manifestFile = "manifest.yaml"
+
+// manifestFS returns a filesystem with the manifest as its only file, so that it can be applied with the decoder
+func manifestFS(manifest []byte) fstest.MapFS {
+	return fstest.MapFS{manifestFile: &fstest.MapFile{Data: manifest}}
+}
+
+// fetchManifest downloads the manifest at the url
+func fetchManifest(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download manifest %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download manifest %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
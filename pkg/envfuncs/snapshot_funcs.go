@@ -0,0 +1,234 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/decoder"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// namespaceSnapshotContextKey is the key of the snapshot of a namespace taken by SnapshotNamespace
+type namespaceSnapshotContextKey string
+
+// unsnapshottedResources are the resources whose objects are managed by the cluster itself, and are neither
+// snapshotted nor restored
+var unsnapshottedResources = map[string]bool{
+	"events":                          true,
+	"events.events.k8s.io":            true,
+	"endpoints":                       true,
+	"endpointslices.discovery.k8s.io": true,
+	"leases.coordination.k8s.io":      true,
+}
+
+// volatileFields are the fields of the objects that are set by the cluster, and are stripped from the snapshots so
+// that the objects can be recreated from them
+var volatileFields = [][]string{
+	{"metadata", "uid"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "deletionTimestamp"},
+	{"metadata", "deletionGracePeriodSeconds"},
+	{"metadata", "selfLink"},
+	{"metadata", "managedFields"},
+	{"status"},
+}
+
+// SnapshotNamespace provides an Environment.Func that takes a snapshot of the objects of the namespace and stores it
+// in the context, so that the namespace can be brought back to this state with RestoreNamespace, e.g. after each
+// destructive feature, instead of running the whole setup again. The objects of every kind of namespaced resource
+// that can be listed, created, updated and deleted are snapshotted, with the fields set by the cluster, such as the
+// status, stripped. The objects managed by the cluster, such as the events and the endpoints, and by a controller,
+// such as the pods of a Deployment, are not part of the snapshot, as they are recreated by the cluster.
+func SnapshotNamespace(namespace string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("snapshot namespace func: %w", err)
+		}
+		objects, err := listSnapshotted(ctx, client, namespace)
+		if err != nil {
+			return ctx, fmt.Errorf("snapshot namespace func: %w", err)
+		}
+		for _, obj := range objects {
+			for _, field := range volatileFields {
+				unstructured.RemoveNestedField(obj.Object, field...)
+			}
+			if obj.GetKind() == "Service" && obj.GroupVersionKind().Group == "" {
+				// the cluster IPs are allocated again when the service is recreated
+				unstructured.RemoveNestedField(obj.Object, "spec", "clusterIP")
+				unstructured.RemoveNestedField(obj.Object, "spec", "clusterIPs")
+			}
+		}
+		return context.WithValue(ctx, namespaceSnapshotContextKey(namespace), objects), nil
+	}
+}
+
+// RestoreNamespace provides an Environment.Func that brings the namespace back to the snapshot taken by
+// SnapshotNamespace: the objects created since the snapshot are deleted, the ones deleted are recreated and the ones
+// changed are updated to their snapshotted state. The objects not part of the snapshot, such as the pods of a
+// Deployment, are left to the cluster to reconcile. The snapshot is kept in the context, so that the namespace can
+// be restored any number of times.
+func RestoreNamespace(namespace string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		snapshot, ok := ctx.Value(namespaceSnapshotContextKey(namespace)).([]*unstructured.Unstructured)
+		if !ok {
+			return ctx, fmt.Errorf("restore namespace func: no snapshot of namespace %s in the context", namespace)
+		}
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("restore namespace func: %w", err)
+		}
+		current, err := listSnapshotted(ctx, client, namespace)
+		if err != nil {
+			return ctx, fmt.Errorf("restore namespace func: %w", err)
+		}
+
+		var errs []error
+		snapshotted := make(map[string]bool, len(snapshot))
+		for _, obj := range snapshot {
+			snapshotted[snapshotKey(obj)] = true
+		}
+		for _, obj := range current {
+			if snapshotted[snapshotKey(obj)] {
+				continue
+			}
+			if err := client.Resources().Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("delete %s %s: %w", obj.GetKind(), obj.GetName(), err))
+			}
+		}
+		restored := make([]k8s.Object, 0, len(snapshot))
+		for _, obj := range snapshot {
+			restored = append(restored, obj.DeepCopy())
+		}
+		// the objects are restored in the same order as they are applied, e.g. the service accounts before the pods
+		decoder.SortForApply(restored)
+		for _, obj := range restored {
+			if err := restoreObject(ctx, client, obj.(*unstructured.Unstructured)); err != nil {
+				errs = append(errs, fmt.Errorf("restore %s %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return ctx, fmt.Errorf("restore namespace func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// restoreObject recreates the snapshotted object, or updates it to its snapshotted state if it still exists
+func restoreObject(ctx context.Context, client klient.Client, obj *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	err := client.Resources().Get(ctx, obj.GetName(), obj.GetNamespace(), existing)
+	if apierrors.IsNotFound(err) {
+		return client.Resources().Create(ctx, obj)
+	}
+	if err != nil {
+		return err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return client.Resources().Update(ctx, obj)
+}
+
+// listSnapshotted lists the objects of the namespace that are part of its snapshots, see SnapshotNamespace
+func listSnapshotted(ctx context.Context, client klient.Client, namespace string) ([]*unstructured.Unstructured, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(client.RESTConfig())
+	if err != nil {
+		return nil, err
+	}
+	// the partial list of resources is kept when some API groups, such as an aggregated API, cannot be discovered
+	lists, err := discovery.ServerPreferredNamespacedResources(dc)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("discover resources: %w", err)
+	}
+	var objects []*unstructured.Unstructured
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range list.APIResources {
+			name := resource.Name
+			if gv.Group != "" {
+				name += "." + gv.Group
+			}
+			if unsnapshottedResources[name] || !hasVerbs(resource.Verbs, "list", "create", "update", "delete") {
+				continue
+			}
+			items := &unstructured.UnstructuredList{}
+			items.SetGroupVersionKind(gv.WithKind(resource.Kind + "List"))
+			if err := client.Resources(namespace).List(ctx, items); err != nil {
+				return nil, fmt.Errorf("list %s: %w", name, err)
+			}
+			for i := range items.Items {
+				obj := &items.Items[i]
+				obj.SetGroupVersionKind(gv.WithKind(resource.Kind))
+				if snapshotted(obj) {
+					objects = append(objects, obj)
+				}
+			}
+		}
+	}
+	return objects, nil
+}
+
+// snapshotted returns whether the object is part of the snapshots, i.e. it is neither managed by a controller nor
+// a token of a service account
+func snapshotted(obj *unstructured.Unstructured) bool {
+	if metav1.GetControllerOf(obj) != nil {
+		return false
+	}
+	if obj.GetKind() == "Secret" && obj.GroupVersionKind().Group == "" {
+		secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+		return secretType != string(corev1.SecretTypeServiceAccountToken)
+	}
+	return true
+}
+
+// snapshotKey identifies an object of the namespace across snapshots
+func snapshotKey(obj *unstructured.Unstructured) string {
+	return obj.GroupVersionKind().GroupKind().String() + "/" + obj.GetName()
+}
+
+// hasVerbs returns whether all the verbs are supported by the resource
+func hasVerbs(supported metav1.Verbs, verbs ...string) bool {
+	for _, verb := range verbs {
+		found := false
+		for _, v := range supported {
+			if v == verb {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
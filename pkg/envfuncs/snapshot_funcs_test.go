@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/envfuncs"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+func TestSnapshotNamespace(t *testing.T) {
+	namespace := envconf.RandomName("snapshot-ns", 16)
+	baseline := map[string]string{"key": "baseline"}
+	feat := features.New("SnapshotNamespace").
+		Setup(func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			ctx, err := envfuncs.CreateNamespace(namespace)(ctx, cfg)
+			if err != nil {
+				t.Fatal("Error creating namespace", err)
+			}
+			for _, name := range []string{"changed", "deleted"} {
+				cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Data: baseline}
+				if err := cfg.Client().Resources().Create(ctx, cm); err != nil {
+					t.Fatal("Error creating config map", err)
+				}
+			}
+			ctx, err = envfuncs.SnapshotNamespace(namespace)(ctx, cfg)
+			if err != nil {
+				t.Fatal("Error taking the snapshot of the namespace", err)
+			}
+			return ctx
+		}).
+		Assess("namespace restored", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			r := cfg.Client().Resources()
+			var changed corev1.ConfigMap
+			if err := r.Get(ctx, "changed", namespace, &changed); err != nil {
+				t.Fatal("error getting config map", err)
+			}
+			changed.Data = map[string]string{"key": "changed"}
+			if err := r.Update(ctx, &changed); err != nil {
+				t.Fatal("error updating config map", err)
+			}
+			if err := r.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "deleted", Namespace: namespace}}); err != nil {
+				t.Fatal("error deleting config map", err)
+			}
+			if err := r.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "created", Namespace: namespace}}); err != nil {
+				t.Fatal("error creating config map", err)
+			}
+
+			ctx, err := envfuncs.RestoreNamespace(namespace)(ctx, cfg)
+			if err != nil {
+				t.Fatal("Error restoring the namespace", err)
+			}
+
+			for _, name := range []string{"changed", "deleted"} {
+				var cm corev1.ConfigMap
+				if err := r.Get(ctx, name, namespace, &cm); err != nil {
+					t.Errorf("error getting config map %s: %s", name, err)
+				} else if !reflect.DeepEqual(baseline, cm.Data) {
+					t.Errorf("config map %s not restored. Expected:\n%v but got:\n%v", name, baseline, cm.Data)
+				}
+			}
+			if err := r.Get(ctx, "created", namespace, &corev1.ConfigMap{}); !errors.IsNotFound(err) {
+				t.Error("expected the config map created after the snapshot to be deleted", err)
+			}
+			return ctx
+		}).
+		Teardown(func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			ctx, err := envfuncs.DeleteNamespace(namespace)(ctx, cfg)
+			if err != nil {
+				t.Error("Error deleting namespace", err)
+			}
+			return ctx
+		}).
+		Feature()
+
+	nsTestenv.Test(t, feat)
+}
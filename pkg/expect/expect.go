@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package expect provides assertions on the resources of a cluster for the assessments of features. The assertions
+// report the failures with t.Errorf, along with the reason why the expectation was not met or a diff of the values
+// compared, and return whether the expectation was met so that the assessment can stop if need be, e.g.
+//
+//	if !expect.ResourceExists(t, cfg, deployment) {
+//		t.FailNow()
+//	}
+//	expect.ResourceMatches(t, cfg, deployment, int32(3), func(obj k8s.Object) int32 {
+//		return obj.(*appsv1.Deployment).Status.ReadyReplicas
+//	}, time.Minute)
+package expect
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// checkInterval is the interval between the checks of the conditions waited for
+const checkInterval = time.Second
+
+// T is the subset of testing.TB used to report the expectations that are not met
+type T interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// ConditionFunc returns the condition to wait for, built from the conditions on the resources of the environment,
+// e.g. func(c *conditions.Condition) apimachinerywait.ConditionWithContextFunc { return c.PodReady(pod) }
+type ConditionFunc func(c *conditions.Condition) apimachinerywait.ConditionWithContextFunc
+
+// ResourceExists expects obj to exist, it is updated with the object retrieved from the cluster
+func ResourceExists(t T, cfg *envconf.Config, obj k8s.Object) bool {
+	t.Helper()
+	client, err := cfg.NewClient()
+	if err != nil {
+		t.Errorf("expected %s to exist: %s", describe(obj), err)
+		return false
+	}
+	if err := client.Resources().Get(context.Background(), obj.GetName(), obj.GetNamespace(), obj); err != nil {
+		t.Errorf("expected %s to exist: %s", describe(obj), err)
+		return false
+	}
+	return true
+}
+
+// ResourceDeleted expects obj to be deleted within timeout
+func ResourceDeleted(t T, cfg *envconf.Config, obj k8s.Object, timeout time.Duration) bool {
+	t.Helper()
+	if err := eventually(cfg, func(c *conditions.Condition) apimachinerywait.ConditionWithContextFunc {
+		return c.ResourceDeleted(obj)
+	}, timeout); err != nil {
+		t.Errorf("expected %s to be deleted within %s: %s", describe(obj), timeout, err)
+		return false
+	}
+	return true
+}
+
+// EventuallyCondition expects the condition returned by cond to be met within timeout. The reason why the condition
+// was last not met is reported when it is not, e.g. the last observed status of the resource waited for.
+func EventuallyCondition(t T, cfg *envconf.Config, cond ConditionFunc, timeout time.Duration) bool {
+	t.Helper()
+	if err := eventually(cfg, cond, timeout); err != nil {
+		t.Errorf("expected the condition to be met within %s: %s", timeout, err)
+		return false
+	}
+	return true
+}
+
+// ResourceMatches expects the value extracted from obj to be equal to want within timeout, as compared by cmp.Diff
+// with opts. A diff of the value last extracted is reported when it is not.
+func ResourceMatches[V any](t T, cfg *envconf.Config, obj k8s.Object, want V, extract func(obj k8s.Object) V, timeout time.Duration, opts ...cmp.Option) bool {
+	t.Helper()
+	client, err := cfg.NewClient()
+	if err != nil {
+		t.Errorf("expected %s to match: %s", describe(obj), err)
+		return false
+	}
+	diff := ""
+	err = waitFor(func(ctx context.Context) (bool, error) {
+		if err := client.Resources().Get(ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
+			if errors.IsNotFound(err) {
+				wait.ReportUnmet(ctx, "%s not found", describe(obj))
+				return false, nil
+			}
+			return false, err
+		}
+		diff = cmp.Diff(want, extract(obj), opts...)
+		return diff == "", nil
+	}, timeout)
+	if err == nil {
+		return true
+	}
+	if diff != "" {
+		t.Errorf("expected %s to match within %s (-want +got):\n%s", describe(obj), timeout, diff)
+	} else {
+		t.Errorf("expected %s to match within %s: %s", describe(obj), timeout, err)
+	}
+	return false
+}
+
+// Equal expects got to be equal to want, as compared by cmp.Diff with opts. A diff of the values is reported when
+// they are not.
+func Equal(t T, want, got any, opts ...cmp.Option) bool {
+	t.Helper()
+	if diff := cmp.Diff(want, got, opts...); diff != "" {
+		t.Errorf("unexpected value (-want +got):\n%s", diff)
+		return false
+	}
+	return true
+}
+
+// eventually waits for the condition returned by cond to be met within timeout
+func eventually(cfg *envconf.Config, cond ConditionFunc, timeout time.Duration) error {
+	client, err := cfg.NewClient()
+	if err != nil {
+		return err
+	}
+	return waitFor(cond(conditions.New(client.Resources())), timeout)
+}
+
+// waitFor checks the condition right away and then on every checkInterval until it is met or timeout elapses
+func waitFor(cond apimachinerywait.ConditionWithContextFunc, timeout time.Duration) error {
+	return wait.For(cond, wait.WithImmediate(), wait.WithInterval(checkInterval), wait.WithTimeout(timeout))
+}
+
+// describe returns the type and the namespaced name of obj to be reported, e.g. v1.Deployment default/app
+func describe(obj k8s.Object) string {
+	name := obj.GetName()
+	if ns := obj.GetNamespace(); ns != "" {
+		name = ns + "/" + name
+	}
+	return fmt.Sprintf("%s %s", strings.TrimPrefix(fmt.Sprintf("%T", obj), "*"), name)
+}
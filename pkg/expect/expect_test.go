@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// recorder records the errors reported by the assertions
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestEqual(t *testing.T) {
+	r := &recorder{}
+	if !Equal(r, []string{"a", "b"}, []string{"a", "b"}) || len(r.errors) != 0 {
+		t.Errorf("expected equal values to be equal, got %v", r.errors)
+	}
+	if Equal(r, map[string]int{"replicas": 3}, map[string]int{"replicas": 2}) {
+		t.Error("expected different values not to be equal")
+	}
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "-want +got") || !strings.Contains(r.errors[0], "replicas") {
+		t.Errorf("expected a diff of the values, got %v", r.errors)
+	}
+}
+
+func TestEventuallyCondition(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	content := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:1
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+users:
+- name: test
+current-context: test
+`
+	if err := os.WriteFile(kubeconfig, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := envconf.New().WithKubeconfigFile(kubeconfig)
+
+	checks := 0
+	r := &recorder{}
+	met := EventuallyCondition(r, cfg, func(*conditions.Condition) apimachinerywait.ConditionWithContextFunc {
+		return func(context.Context) (bool, error) {
+			checks++
+			return checks == 2, nil
+		}
+	}, 5*time.Second)
+	if !met || len(r.errors) != 0 {
+		t.Errorf("expected the condition to be met, got %v", r.errors)
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}
+	met = EventuallyCondition(r, cfg, func(*conditions.Condition) apimachinerywait.ConditionWithContextFunc {
+		return func(ctx context.Context) (bool, error) {
+			wait.ReportUnmet(ctx, "%s is pending", describe(pod))
+			return false, nil
+		}
+	}, 1500*time.Millisecond)
+	if met {
+		t.Error("expected the condition not to be met")
+	}
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "v1.Pod default/app is pending") {
+		t.Errorf("expected the reason of the unmet condition to be reported, got %v", r.errors)
+	}
+}
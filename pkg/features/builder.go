@@ -18,6 +18,7 @@ package features
 
 import (
 	"fmt"
+	"time"
 
 	"sigs.k8s.io/e2e-framework/pkg/types"
 )
@@ -42,6 +43,101 @@ func (b *FeatureBuilder) WithLabel(key, value string) *FeatureBuilder {
 	return b
 }
 
+// Parallel marks the feature to be run concurrently with the other features marked as parallel of the same
+// Env.Test call, once the features that are not marked as parallel have been run. Each parallel feature runs
+// with a copy of the environment configuration using an isolated namespace, which is created before and deleted
+// after the feature when the environment is configured with a cluster.
+func (b *FeatureBuilder) Parallel() *FeatureBuilder {
+	b.feat.parallel = true
+	return b
+}
+
+// Isolated runs the feature with a copy of the environment configuration using a namespace of its own, which is
+// created before and deleted after the feature when the environment is configured with a cluster. Features marked
+// as parallel are always isolated.
+func (b *FeatureBuilder) Isolated() *FeatureBuilder {
+	b.feat.isolated = true
+	return b
+}
+
+// WithTimeout sets the time the setups and assessments of the feature are allowed to run for. The context
+// passed to the steps is cancelled when the timeout is exceeded, the step running at that time is failed once it
+// returns and the remaining assessments are skipped. The teardowns are still run, with a context that is not
+// cancelled. The steps are expected to honor the cancellation of their context, use AssessWithTimeout for an
+// assessment that may not return in time.
+func (b *FeatureBuilder) WithTimeout(timeout time.Duration) *FeatureBuilder {
+	b.feat.timeout = timeout
+	return b
+}
+
+// Quarantine marks the feature as quarantined for reason, such as a known flakiness. The feature is still run as a
+// subtest, so that its failures are reported by go test and fail the test running it as any failed subtest does,
+// but they are reported apart at the end of the run and in the reports, and they do not stop the other features
+// in the fail-fast mode of the framework.
+func (b *FeatureBuilder) Quarantine(reason string) *FeatureBuilder {
+	if reason == "" {
+		reason = "quarantined"
+	}
+	b.feat.quarantine = reason
+	return b
+}
+
+// WithRetry re-runs the failed assessments of the feature as configured by the retry policy, unless they have
+// their own retry policy, see AssessWithRetry.
+func (b *FeatureBuilder) WithRetry(policy RetryPolicy) *FeatureBuilder {
+	b.feat.retry = &policy
+	return b
+}
+
+// WithRequirement adds a requirement checked before the feature is run, e.g. a minimum server version or a
+// CustomResourceDefinition installed in the cluster. The feature is skipped with the reason returned by fn when the
+// requirement is not met, before any of the BeforeEachFeature actions and of the setups of the feature are run.
+// See RequireServerVersion, RequireGroupVersionKind, RequireNodes and RequireNodeLabel for common requirements.
+func (b *FeatureBuilder) WithRequirement(fn Requirement) *FeatureBuilder {
+	b.feat.reqs = append(b.feat.reqs, fn)
+	return b
+}
+
+// DependsOn declares that the feature can only be run once the features named after names have passed. The
+// features passed to the same Env.Test call are run in an order that honors their dependencies, and the features
+// depended on can also have been run by a previous test of the suite. The feature is skipped with a reason when one
+// of the features it depends on failed, was skipped or has not been run.
+func (b *FeatureBuilder) DependsOn(names ...string) *FeatureBuilder {
+	b.feat.deps = append(b.feat.deps, names...)
+	return b
+}
+
+// OnClusters runs the feature against each of the clusters named after names, which are registered in the
+// environment configuration with envconf.Config.WithCluster. Each run uses a copy of the configuration whose
+// current cluster is the cluster of the run, see envconf.Config.WithCurrentCluster, and is named after the
+// feature and the cluster, e.g. feature-hub. The feature is skipped for the clusters that are not registered.
+func (b *FeatureBuilder) OnClusters(names ...string) *FeatureBuilder {
+	b.feat.clusters = append(b.feat.clusters, names...)
+	return b
+}
+
+// OnAllClusters runs the feature against each of the clusters registered in the environment configuration, see
+// OnClusters.
+func (b *FeatureBuilder) OnAllClusters() *FeatureBuilder {
+	return b.OnClusters(types.AllClusters)
+}
+
+// BeforeEachAssessment adds a hook run before each assessment of the feature with the name of the assessment, e.g.
+// to log a marker or start measuring the assessment. The hooks run as part of the test of the assessment, after
+// the BeforeEachAssessment funcs of the environment.
+func (b *FeatureBuilder) BeforeEachAssessment(fn HookFunc) *FeatureBuilder {
+	b.feat.beforeEach = append(b.feat.beforeEach, fn)
+	return b
+}
+
+// AfterEachAssessment adds a hook run after each assessment of the feature with the name of the assessment, even
+// when the assessment failed, e.g. to clean up after the assessment. The hooks run as part of the test of the
+// assessment, before the AfterEachAssessment funcs of the environment.
+func (b *FeatureBuilder) AfterEachAssessment(fn HookFunc) *FeatureBuilder {
+	b.feat.afterEach = append(b.feat.afterEach, fn)
+	return b
+}
+
 // WithStep adds a new step that will be applied prior to feature test.
 func (b *FeatureBuilder) WithStep(name string, level Level, fn Func) *FeatureBuilder {
 	b.feat.steps = append(b.feat.steps, newStep(name, level, fn))
@@ -64,6 +160,21 @@ func (b *FeatureBuilder) WithSetup(name string, fn Func) *FeatureBuilder {
 	return b.WithStep(name, LevelSetup, fn)
 }
 
+// SetupWithTeardown adds a new setup step along with the teardown step undoing it, see WithSetupTeardown.
+func (b *FeatureBuilder) SetupWithTeardown(setup, teardown Func) *FeatureBuilder {
+	return b.WithSetupTeardown(fmt.Sprintf("%s-setup", b.feat.name), setup, teardown)
+}
+
+// WithSetupTeardown adds a new setup step with a pre-defined name along with the teardown step undoing it. The
+// teardown is only run if the setup succeeded, including when a later setup or an assessment fails. The teardowns
+// of the setups are run in the reverse order of the setups, after the teardown steps of the feature.
+func (b *FeatureBuilder) WithSetupTeardown(name string, setup, teardown Func) *FeatureBuilder {
+	step := newStep(name, LevelSetup, setup)
+	step.undo = newStep(fmt.Sprintf("%s-teardown", name), LevelTeardown, teardown)
+	b.feat.steps = append(b.feat.steps, step)
+	return b
+}
+
 // Teardown adds a new teardown step that will be applied after feature test.
 func (b *FeatureBuilder) Teardown(fn Func) *FeatureBuilder {
 	return b.WithTeardown(fmt.Sprintf("%s-teardown", b.feat.name), fn)
@@ -80,6 +191,41 @@ func (b *FeatureBuilder) Assess(desc string, fn Func) *FeatureBuilder {
 	return b.WithStep(desc, LevelAssess, fn)
 }
 
+// AssessWithTimeout adds an assessment step to the feature test that is allowed to run for the time timeout.
+// The context passed to the assessment is cancelled when the timeout is exceeded and the assessment is failed
+// right away. An assessment that does not return then is abandoned, which stops the remaining assessments the same
+// way as t.FailNow.
+func (b *FeatureBuilder) AssessWithTimeout(desc string, timeout time.Duration, fn Func) *FeatureBuilder {
+	step := newStep(desc, LevelAssess, fn)
+	step.timeout = timeout
+	b.feat.steps = append(b.feat.steps, step)
+	return b
+}
+
+// AssessInParallel adds an assessment step to the feature test that runs concurrently with the assessments next
+// to it that are also added with AssessInParallel, e.g. to probe several endpoints after a single setup. The
+// assessments next in the feature are run once all of them are done, and the feature fails if any of them fails.
+// As they run concurrently, the assessments share the context of the assessment before them and the contexts they
+// return are not passed on.
+func (b *FeatureBuilder) AssessInParallel(desc string, fn Func) *FeatureBuilder {
+	step := newStep(desc, LevelAssess, fn)
+	step.parallel = true
+	b.feat.steps = append(b.feat.steps, step)
+	return b
+}
+
+// AssessWithRetry adds an assessment step to the feature test that is re-run when it fails, as configured by the
+// retry policy. Each attempt is run as a subtest of the assessment named attempt-<n>. The assessment only fails
+// once all the attempts have failed, while an assessment that passes after a failed attempt is reported as flaky.
+// The failed attempts are still reported by go test and fail the test running the feature, as any failed subtest
+// does.
+func (b *FeatureBuilder) AssessWithRetry(desc string, policy RetryPolicy, fn Func) *FeatureBuilder {
+	step := newStep(desc, LevelAssess, fn)
+	step.retry = &policy
+	b.feat.steps = append(b.feat.steps, step)
+	return b
+}
+
 func (b *FeatureBuilder) AssessWithDescription(name, description string, fn Func) *FeatureBuilder {
 	return b.WithStepDescription(name, description, LevelAssess, fn)
 }
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"sigs.k8s.io/e2e-framework/pkg/types"
+)
+
+// cleanupsKey is the key of the context holding the stack of the cleanups registered by the steps of a feature
+type cleanupsKey struct{}
+
+// cleanups is the stack of the cleanups registered by the steps of a feature, which can run concurrently
+type cleanups struct {
+	mu         sync.Mutex
+	steps      []types.Step
+	registered int
+}
+
+// RegisterCleanup registers fn to be run when the feature whose step was given ctx ends, the same way as t.Cleanup.
+// The cleanups are run after the teardowns of the feature, the last registered first, whether the feature passed,
+// failed or panicked. Registering a cleanup at the point a setup creates a resource keeps the cleanup in sync with
+// what was actually created:
+//
+//	func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+//		if err := cfg.Client().Resources().Create(ctx, deployment); err != nil {
+//			t.Fatal(err)
+//		}
+//		features.RegisterCleanup(ctx, func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+//			_ = cfg.Client().Resources().Delete(ctx, deployment)
+//			return ctx
+//		})
+//		return ctx
+//	}
+//
+// It panics if ctx is not the context of a step of a feature run by an environment.
+func RegisterCleanup(ctx context.Context, fn Func) {
+	stack, ok := ctx.Value(cleanupsKey{}).(*cleanups)
+	if !ok {
+		panic("features.RegisterCleanup called without the context of a feature step")
+	}
+	stack.mu.Lock()
+	defer stack.mu.Unlock()
+	stack.registered++
+	stack.steps = append(stack.steps, newStep(fmt.Sprintf("Cleanup-%d", stack.registered), LevelTeardown, fn))
+}
+
+// WithCleanups returns a copy of ctx holding the stack the cleanups of a feature are registered to with
+// RegisterCleanup. It is used by the environments before running the steps of a feature.
+func WithCleanups(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cleanupsKey{}, &cleanups{})
+}
+
+// PopCleanups removes the cleanups registered to the stack of ctx and returns them, the last registered first.
+// It is used by the environments once the teardowns of a feature are run.
+func PopCleanups(ctx context.Context) []Step {
+	stack, ok := ctx.Value(cleanupsKey{}).(*cleanups)
+	if !ok {
+		return nil
+	}
+	stack.mu.Lock()
+	defer stack.mu.Unlock()
+	steps := make([]Step, 0, len(stack.steps))
+	for i := len(stack.steps) - 1; i >= 0; i-- {
+		steps = append(steps, stack.steps[i])
+	}
+	stack.steps = nil
+	return steps
+}
@@ -18,16 +18,20 @@ package features
 
 import (
 	"regexp"
+	"time"
 
 	"sigs.k8s.io/e2e-framework/pkg/types"
 )
 
 type (
-	Labels  = types.Labels
-	Feature = types.Feature
-	Step    = types.Step
-	Func    = types.StepFunc
-	Level   = types.Level
+	Labels      = types.Labels
+	RetryPolicy = types.RetryPolicy
+	Requirement = types.RequirementFunc
+	HookFunc    = types.AssessmentHookFunc
+	Feature     = types.Feature
+	Step        = types.Step
+	Func        = types.StepFunc
+	Level       = types.Level
 )
 
 const (
@@ -44,6 +48,16 @@ type defaultFeature struct {
 	description string
 	labels      types.Labels
 	steps       []types.Step
+	parallel    bool
+	isolated    bool
+	timeout     time.Duration
+	quarantine  string
+	retry       *types.RetryPolicy
+	deps        []string
+	clusters    []string
+	reqs        []types.RequirementFunc
+	beforeEach  []types.AssessmentHookFunc
+	afterEach   []types.AssessmentHookFunc
 }
 
 func newDefaultFeature(name, description string) *defaultFeature {
@@ -66,11 +80,55 @@ func (f *defaultFeature) Description() string {
 	return f.description
 }
 
+func (f *defaultFeature) Parallel() bool {
+	return f.parallel
+}
+
+func (f *defaultFeature) Isolated() bool {
+	return f.isolated || f.parallel
+}
+
+func (f *defaultFeature) Timeout() time.Duration {
+	return f.timeout
+}
+
+func (f *defaultFeature) QuarantineReason() string {
+	return f.quarantine
+}
+
+func (f *defaultFeature) Retry() *types.RetryPolicy {
+	return f.retry
+}
+
+func (f *defaultFeature) Dependencies() []string {
+	return f.deps
+}
+
+func (f *defaultFeature) Clusters() []string {
+	return f.clusters
+}
+
+func (f *defaultFeature) Requirements() []types.RequirementFunc {
+	return f.reqs
+}
+
+func (f *defaultFeature) BeforeEachAssessment() []types.AssessmentHookFunc {
+	return f.beforeEach
+}
+
+func (f *defaultFeature) AfterEachAssessment() []types.AssessmentHookFunc {
+	return f.afterEach
+}
+
 type testStep struct {
 	name        string
 	description string
 	level       Level
 	fn          Func
+	timeout     time.Duration
+	retry       *types.RetryPolicy
+	undo        *testStep
+	parallel    bool
 }
 
 func newStep(name string, level Level, fn Func) *testStep {
@@ -102,6 +160,25 @@ func (s *testStep) Description() string {
 	return s.description
 }
 
+func (s *testStep) Timeout() time.Duration {
+	return s.timeout
+}
+
+func (s *testStep) Retry() *types.RetryPolicy {
+	return s.retry
+}
+
+func (s *testStep) Parallel() bool {
+	return s.parallel
+}
+
+func (s *testStep) Undo() types.Step {
+	if s.undo == nil {
+		return nil
+	}
+	return s.undo
+}
+
 func GetStepsByLevel(steps []types.Step, l types.Level) []types.Step {
 	if steps == nil {
 		return nil
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// RequireServerVersion requires the version of the apiserver to be at least minVersion, e.g. "1.30" or "v1.30.2"
+func RequireServerVersion(minVersion string) Requirement {
+	return func(ctx context.Context, cfg *envconf.Config) (bool, string) {
+		client, err := cfg.NewClient()
+		if err != nil {
+			return false, fmt.Sprintf("cannot check server version: %s", err)
+		}
+		ok, err := client.Resources().ServerVersionAtLeast(minVersion)
+		if err != nil {
+			return false, fmt.Sprintf("cannot check server version: %s", err)
+		}
+		if !ok {
+			return false, fmt.Sprintf("server version is older than %s", minVersion)
+		}
+		return true, ""
+	}
+}
+
+// RequireGroupVersionKind requires the apiserver to serve the kind gvk, e.g. a kind defined by a
+// CustomResourceDefinition
+func RequireGroupVersionKind(gvk schema.GroupVersionKind) Requirement {
+	return func(ctx context.Context, cfg *envconf.Config) (bool, string) {
+		client, err := cfg.NewClient()
+		if err != nil {
+			return false, fmt.Sprintf("cannot check kind %s: %s", gvk, err)
+		}
+		ok, err := client.Resources().HasGroupVersionKind(gvk)
+		if err != nil {
+			return false, fmt.Sprintf("cannot check kind %s: %s", gvk, err)
+		}
+		if !ok {
+			return false, fmt.Sprintf("kind %s is not served", gvk)
+		}
+		return true, ""
+	}
+}
+
+// RequireNodes requires the cluster to have at least count nodes
+func RequireNodes(count int) Requirement {
+	return func(ctx context.Context, cfg *envconf.Config) (bool, string) {
+		nodes, err := listNodes(ctx, cfg)
+		if err != nil {
+			return false, fmt.Sprintf("cannot list nodes: %s", err)
+		}
+		if len(nodes.Items) < count {
+			return false, fmt.Sprintf("cluster has %d nodes, %d required", len(nodes.Items), count)
+		}
+		return true, ""
+	}
+}
+
+// RequireNodeLabel requires at least one node of the cluster to be labeled with key=value, or with key when value
+// is empty, e.g. to run a feature on clusters with GPU nodes only
+func RequireNodeLabel(key, value string) Requirement {
+	selector := key
+	if value != "" {
+		selector = key + "=" + value
+	}
+	return func(ctx context.Context, cfg *envconf.Config) (bool, string) {
+		nodes, err := listNodes(ctx, cfg, resources.WithLabelSelector(selector))
+		if err != nil {
+			return false, fmt.Sprintf("cannot list nodes: %s", err)
+		}
+		if len(nodes.Items) == 0 {
+			return false, fmt.Sprintf("no node labeled %s", selector)
+		}
+		return true, ""
+	}
+}
+
+func listNodes(ctx context.Context, cfg *envconf.Config, opts ...resources.ListOption) (*corev1.NodeList, error) {
+	client, err := cfg.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	nodes := &corev1.NodeList{}
+	if err := client.Resources().List(ctx, nodes, opts...); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
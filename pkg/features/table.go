@@ -18,6 +18,8 @@ package features
 
 import (
 	"fmt"
+
+	"sigs.k8s.io/e2e-framework/pkg/types"
 )
 
 type TableRow struct {
@@ -54,3 +56,28 @@ func (table Table) Build(args ...string) *FeatureBuilder {
 	}
 	return f
 }
+
+// Case is a parameter set of a parameterized feature, e.g. a storage class or an image version, see Parameterize
+type Case[P any] struct {
+	// Name identifies the case in the name of the feature generated for it
+	Name   string
+	Params P
+}
+
+// Parameterize instantiates the feature template for each of the cases, so that the same feature is exercised
+// across the parameter sets without copying its builder. The template is called with a builder for a feature
+// named <name>-<case name>, or <name>-<case index> when the case has no name, and the parameters of the case.
+// Each generated feature is isolated, it runs with a namespace of its own.
+func Parameterize[P any](name string, cases []Case[P], template func(builder *FeatureBuilder, params P)) []types.Feature {
+	generated := make([]types.Feature, 0, len(cases))
+	for i, c := range cases {
+		caseName := c.Name
+		if caseName == "" {
+			caseName = fmt.Sprintf("%d", i+1)
+		}
+		builder := New(fmt.Sprintf("%s-%s", name, caseName)).Isolated()
+		template(builder, c.Params)
+		generated = append(generated, builder.Feature())
+	}
+	return generated
+}
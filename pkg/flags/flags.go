@@ -20,8 +20,12 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	klog "k8s.io/klog/v2"
 	"sigs.k8s.io/e2e-framework/pkg/featuregate"
 )
@@ -40,8 +44,54 @@ const (
 	flagFailFast                = "fail-fast"
 	flagDisableGracefulTeardown = "disable-graceful-teardown"
 	flagContext                 = "context"
+	flagReportDir               = "report-dir"
+	flagArtifactsDir            = "artifacts-dir"
+	flagList                    = "list"
+	flagStateFile               = "state-file"
+	flagRerunFailed             = "rerun-failed"
+	flagTestDeadline            = "test-deadline"
+	flagConfig                  = "config"
+	flagInCluster               = "in-cluster"
+	flagFeatureGates            = "feature-gates"
+	flagProgress                = "progress"
+
+	// envVarPrefix is the prefix of the environment variables the flags of the framework can be set with
+	envVarPrefix = "E2E_"
 )
 
+// frameworkFlags are the names of the flags of the framework, which can also be set with environment variables
+var frameworkFlags = []string{
+	flagNamespaceName, flagKubecofigName, flagFeatureName, flagAssessName, flagLabelsName, flagSkipLabelName,
+	flagSkipFeatureName, flagSkipAssessmentName, flagParallelTestsName, flagDryRunName, flagFailFast,
+	flagDisableGracefulTeardown, flagContext, flagReportDir, flagArtifactsDir, flagList, flagStateFile,
+	flagRerunFailed, flagTestDeadline, flagConfig, flagInCluster, flagFeatureGates, flagProgress,
+}
+
+var (
+	// userFlags are the flags registered by the test authors, see UserFlags
+	userFlags = flag.NewFlagSet("user", flag.ContinueOnError)
+	// userFlagsSet is the flag set the user flags were last added to, so that they are added to it only once
+	userFlagsSet *flag.FlagSet
+)
+
+// UserFlags returns the flag set the test authors register their own flags with, such as the tag of an image or the
+// version of a chart. The flags are parsed along with the framework flags by Parse, and so by envconf.NewFromFlags,
+// and can be set with environment variables the same way, see EnvVarName. This avoids calling flag.Parse in TestMain
+// before the framework flags are registered:
+//
+//	var imageTag string
+//
+//	func TestMain(m *testing.M) {
+//		flags.UserFlags().StringVar(&imageTag, "image-tag", "latest", "The tag of the image under test")
+//		cfg, err := envconf.NewFromFlags()
+//		...
+//	}
+//
+// Parsing fails if a user flag is named after a flag that is already defined, such as a framework flag.
+func UserFlags() *flag.FlagSet {
+	return userFlags
+}
+
 // Supported flag definitions
 var (
 	featureFlag = flag.Flag{
@@ -54,7 +104,7 @@ var (
 	}
 	labelsFlag = flag.Flag{
 		Name:  flagLabelsName,
-		Usage: "Comma-separated key=value to filter features by labels",
+		Usage: "Comma-separated key=value or set-based label expressions, e.g. 'tier in (net,storage), !slow', to filter features by labels",
 	}
 	kubecfgFlag = flag.Flag{
 		Name:  flagKubecofigName,
@@ -66,7 +116,7 @@ var (
 	}
 	skipLabelsFlag = flag.Flag{
 		Name:  flagSkipLabelName,
-		Usage: "Regular expression to skip label(s) to run",
+		Usage: "Comma-separated key=value or set-based label expressions to skip features by labels",
 	}
 	skipFeatureFlag = flag.Flag{
 		Name:  flagSkipFeatureName,
@@ -96,6 +146,42 @@ var (
 		Name:  flagContext,
 		Usage: "The name of the kubeconfig context to use",
 	}
+	reportDirFlag = flag.Flag{
+		Name:  flagReportDir,
+		Usage: "Path to a directory where JUnit XML and JSON reports of the features run are written (optional)",
+	}
+	artifactsDirFlag = flag.Flag{
+		Name:  flagArtifactsDir,
+		Usage: "Path to a directory where the objects, events and pod logs of the test namespace are dumped when an assessment fails (optional)",
+	}
+	progressFlag = flag.Flag{
+		Name:  flagProgress,
+		Usage: "Path to a file where the progress events of the run are written as JSON lines as it proceeds, - for the standard output (optional)",
+	}
+	listFlag = flag.Flag{
+		Name:  flagList,
+		Usage: "List the environment setup and finish actions, the features, their labels and their steps without running them. This enables the dry-run mode",
+	}
+	stateFileFlag = flag.Flag{
+		Name:  flagStateFile,
+		Usage: "Path to a file where the features that failed are written at the end of the run (optional)",
+	}
+	rerunFailedFlag = flag.Flag{
+		Name:  flagRerunFailed,
+		Usage: "Only run the features that failed in the last run, as written to the file set by --state-file. All the features are run if none failed",
+	}
+	testDeadlineFlag = flag.Flag{
+		Name:  flagTestDeadline,
+		Usage: "Time the test suite is allowed to run for, e.g. 1h30m. Once exceeded, no new feature is started, the features running are cancelled through their context and the finish actions are run (optional)",
+	}
+	inClusterFlag = flag.Flag{
+		Name:  flagInCluster,
+		Usage: "Creates the client from the service account of the pod the tests run in, instead of a kubeconfig (optional)",
+	}
+	configFlag = flag.Flag{
+		Name:  flagConfig,
+		Usage: "Path to a YAML or JSON file the environment configuration is loaded from, the flags set override the values of the file (optional)",
+	}
 )
 
 // EnvFlags surfaces all resolved flag values for the testing framework
@@ -103,9 +189,11 @@ type EnvFlags struct {
 	feature                 string
 	assess                  string
 	labels                  LabelsMap
+	labelSelector           LabelSelector
 	kubeconfig              string
 	namespace               string
 	skiplabels              LabelsMap
+	skipLabelSelector       LabelSelector
 	skipFeatures            string
 	skipAssessments         string
 	parallelTests           bool
@@ -113,6 +201,24 @@ type EnvFlags struct {
 	failFast                bool
 	disableGracefulTeardown bool
 	kubeContext             string
+	reportDir               string
+	artifactsDir            string
+	progress                string
+	list                    bool
+	stateFile               string
+	rerunFailed             bool
+	testDeadline            time.Duration
+	configFile              string
+	inCluster               bool
+	// set are the names of the flags set explicitly, on the command line or with their environment variable
+	set map[string]bool
+}
+
+// IsSet returns true if the flag named name was set explicitly, on the command line or with its environment
+// variable, whatever its value. The dry-run flag is also reported as set when the dry-run mode is enabled by the
+// list mode. This can be used to tell a flag set to its default value from a flag left unset.
+func (f *EnvFlags) IsSet(name string) bool {
+	return f.set[name]
 }
 
 // Feature returns value for `-feature` flag
@@ -130,6 +236,11 @@ func (f *EnvFlags) Labels() LabelsMap {
 	return f.labels
 }
 
+// LabelSelector returns the label requirements parsed from `-labels` flag, including the set-based ones
+func (f *EnvFlags) LabelSelector() LabelSelector {
+	return f.labelSelector
+}
+
 // Namespace returns an optional namespace flag value
 func (f *EnvFlags) Namespace() string {
 	return f.namespace
@@ -154,6 +265,11 @@ func (f *EnvFlags) SkipLabels() LabelsMap {
 	return f.skiplabels
 }
 
+// SkipLabelSelector returns the label requirements parsed from `-skip-labels` flag, including the set-based ones
+func (f *EnvFlags) SkipLabelSelector() LabelSelector {
+	return f.skipLabelSelector
+}
+
 // Kubeconfig returns an optional path for kubeconfig file
 func (f *EnvFlags) Kubeconfig() string {
 	return f.kubeconfig
@@ -169,6 +285,38 @@ func (f *EnvFlags) DryRun() bool {
 	return f.dryRun
 }
 
+// List is used to indicate if the environment actions and the features are to be listed instead of being run.
+// The list mode implies the dry-run mode.
+func (f *EnvFlags) List() bool {
+	return f.list
+}
+
+// StateFile returns the path of the file where the features that failed are written at the end of the run
+func (f *EnvFlags) StateFile() string {
+	return f.stateFile
+}
+
+// RerunFailed is used to indicate if only the features that failed in the last run, as written to the state
+// file, are to be run
+func (f *EnvFlags) RerunFailed() bool {
+	return f.rerunFailed
+}
+
+// TestDeadline returns the time the test suite is allowed to run for, zero if there is no deadline
+func (f *EnvFlags) TestDeadline() time.Duration {
+	return f.testDeadline
+}
+
+// InCluster is used to indicate if the client is to be created from the service account of the pod the tests run in
+func (f *EnvFlags) InCluster() bool {
+	return f.inCluster
+}
+
+// ConfigFile returns the path of the file the environment configuration is loaded from
+func (f *EnvFlags) ConfigFile() string {
+	return f.configFile
+}
+
 // FailFast is used to indicate if the failure of an assessment should continue
 // assessing the rest of the features or skip it and continue to the next one.
 // This is set to false by default.
@@ -192,6 +340,21 @@ func (f *EnvFlags) KubeContext() string {
 	return f.kubeContext
 }
 
+// ReportDir returns an optional path to the directory the reports of the features run are written to
+func (f *EnvFlags) ReportDir() string {
+	return f.reportDir
+}
+
+// ArtifactsDir returns an optional path to the directory the artifacts of the failed assessments are dumped to
+func (f *EnvFlags) ArtifactsDir() string {
+	return f.artifactsDir
+}
+
+// Progress returns an optional path to the file the progress events of the run are written to, - for the standard output
+func (f *EnvFlags) Progress() string {
+	return f.progress
+}
+
 // ParseArgs parses the specified args from global flag.CommandLine
 // and returns a set of environment flag values.
 func ParseArgs(args []string) (*EnvFlags, error) {
@@ -207,10 +370,19 @@ func ParseArgs(args []string) (*EnvFlags, error) {
 		failFast                bool
 		disableGracefulTeardown bool
 		kubeContext             string
+		reportDir               string
+		artifactsDir            string
+		progress                string
+		list                    bool
+		stateFile               string
+		rerunFailed             bool
+		testDeadline            time.Duration
+		configFile              string
+		inCluster               bool
 	)
 
-	labels := make(LabelsMap)
-	skipLabels := make(LabelsMap)
+	labels := &labelsValue{labels: make(LabelsMap)}
+	skipLabels := &labelsValue{labels: make(LabelsMap)}
 
 	if flag.Lookup(featureFlag.Name) == nil {
 		flag.StringVar(&feature, featureFlag.Name, featureFlag.DefValue, featureFlag.Usage)
@@ -229,11 +401,11 @@ func ParseArgs(args []string) (*EnvFlags, error) {
 	}
 
 	if flag.Lookup(labelsFlag.Name) == nil {
-		flag.Var(&labels, labelsFlag.Name, labelsFlag.Usage)
+		flag.Var(labels, labelsFlag.Name, labelsFlag.Usage)
 	}
 
 	if flag.Lookup(skipLabelsFlag.Name) == nil {
-		flag.Var(&skipLabels, skipLabelsFlag.Name, skipLabelsFlag.Usage)
+		flag.Var(skipLabels, skipLabelsFlag.Name, skipLabelsFlag.Usage)
 	}
 
 	if flag.Lookup(skipAssessmentFlag.Name) == nil {
@@ -264,19 +436,82 @@ func ParseArgs(args []string) (*EnvFlags, error) {
 		flag.StringVar(&kubeContext, contextFlag.Name, contextFlag.DefValue, contextFlag.Usage)
 	}
 
-	flag.Var(featuregate.FeatureGate, "feature-gates", "A set of key=value pairs that describe feature gates for alpha/experimental features. Options are: \n"+strings.Join(featuregate.FeatureGate.KnownFeatures(), "\n"))
+	if flag.Lookup(reportDirFlag.Name) == nil {
+		flag.StringVar(&reportDir, reportDirFlag.Name, reportDirFlag.DefValue, reportDirFlag.Usage)
+	}
+
+	if flag.Lookup(artifactsDirFlag.Name) == nil {
+		flag.StringVar(&artifactsDir, artifactsDirFlag.Name, artifactsDirFlag.DefValue, artifactsDirFlag.Usage)
+	}
+
+	if flag.Lookup(progressFlag.Name) == nil {
+		flag.StringVar(&progress, progressFlag.Name, progressFlag.DefValue, progressFlag.Usage)
+	}
+
+	if flag.Lookup(listFlag.Name) == nil {
+		flag.BoolVar(&list, listFlag.Name, false, listFlag.Usage)
+	}
+
+	if flag.Lookup(stateFileFlag.Name) == nil {
+		flag.StringVar(&stateFile, stateFileFlag.Name, stateFileFlag.DefValue, stateFileFlag.Usage)
+	}
+
+	if flag.Lookup(rerunFailedFlag.Name) == nil {
+		flag.BoolVar(&rerunFailed, rerunFailedFlag.Name, false, rerunFailedFlag.Usage)
+	}
+
+	if flag.Lookup(testDeadlineFlag.Name) == nil {
+		flag.DurationVar(&testDeadline, testDeadlineFlag.Name, 0, testDeadlineFlag.Usage)
+	}
+
+	if flag.Lookup(inClusterFlag.Name) == nil {
+		flag.BoolVar(&inCluster, inClusterFlag.Name, false, inClusterFlag.Usage)
+	}
+
+	if flag.Lookup(configFlag.Name) == nil {
+		flag.StringVar(&configFile, configFlag.Name, configFlag.DefValue, configFlag.Usage)
+	}
+
+	flag.Var(featuregate.FeatureGate, flagFeatureGates, "A set of key=value pairs that describe feature gates for alpha/experimental features. Options are: \n"+strings.Join(featuregate.FeatureGate.KnownFeatures(), "\n"))
 
 	// Enable klog/v2 flag integration
 	klog.InitFlags(nil)
 
+	if err := addUserFlags(); err != nil {
+		return nil, fmt.Errorf("flags parsing: %w", err)
+	}
+
 	if err := flag.CommandLine.Parse(args); err != nil {
 		return nil, fmt.Errorf("flags parsing: %w", err)
 	}
 
+	if err := setFromEnvironment(); err != nil {
+		return nil, fmt.Errorf("flags parsing: %w", err)
+	}
+
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
 	// Hook into the default test.list of the `go test` and integrate that with the `--dry-run` behavior. Treat them the same way
 	if !dryRun && flag.Lookup("test.list") != nil && flag.Lookup("test.list").Value.String() == "true" {
 		klog.V(2).Info("Enabling dry-run mode as the tests were invoked in list mode")
 		dryRun = true
+		set[flagDryRunName] = true
+	}
+
+	if list {
+		dryRun = true
+		set[flagDryRunName] = true
+	}
+
+	if rerunFailed && stateFile == "" {
+		return nil, fmt.Errorf("--%s requires --%s", flagRerunFailed, flagStateFile)
+	}
+
+	if inCluster && (kubeconfig != "" || kubeContext != "") {
+		return nil, fmt.Errorf("--%s cannot be used along with --%s or --%s", flagInCluster, flagKubecofigName, flagContext)
 	}
 
 	if failFast && parallelTests {
@@ -286,10 +521,12 @@ func ParseArgs(args []string) (*EnvFlags, error) {
 	return &EnvFlags{
 		feature:                 feature,
 		assess:                  assess,
-		labels:                  labels,
+		labels:                  labels.labels,
+		labelSelector:           labels.selector,
 		namespace:               namespace,
 		kubeconfig:              kubeconfig,
-		skiplabels:              skipLabels,
+		skiplabels:              skipLabels.labels,
+		skipLabelSelector:       skipLabels.selector,
 		skipFeatures:            skipFeature,
 		skipAssessments:         skipAssessment,
 		parallelTests:           parallelTests,
@@ -297,9 +534,71 @@ func ParseArgs(args []string) (*EnvFlags, error) {
 		failFast:                failFast,
 		disableGracefulTeardown: disableGracefulTeardown,
 		kubeContext:             kubeContext,
+		reportDir:               reportDir,
+		artifactsDir:            artifactsDir,
+		progress:                progress,
+		list:                    list,
+		stateFile:               stateFile,
+		rerunFailed:             rerunFailed,
+		testDeadline:            testDeadline,
+		configFile:              configFile,
+		inCluster:               inCluster,
+		set:                     set,
 	}, nil
 }
 
+// EnvVarName returns the name of the environment variable the flag named flagName can be set with,
+// e.g. E2E_SKIP_LABELS for --skip-labels
+func EnvVarName(flagName string) string {
+	return envVarPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// addUserFlags adds the user flags to the flag set the command line is parsed with
+func addUserFlags() error {
+	if userFlagsSet == flag.CommandLine {
+		return nil
+	}
+	var err error
+	userFlags.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		if flag.Lookup(f.Name) != nil {
+			err = fmt.Errorf("user flag %s is already defined", f.Name)
+			return
+		}
+		flag.Var(f.Value, f.Name, f.Usage)
+	})
+	if err != nil {
+		return err
+	}
+	userFlagsSet = flag.CommandLine
+	return nil
+}
+
+// setFromEnvironment sets the framework and user flags that are not set on the command line from their environment
+// variable, so that the flags take precedence over the environment
+func setFromEnvironment() error {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	names := append([]string{}, frameworkFlags...)
+	userFlags.VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name)
+	})
+	for _, name := range names {
+		value, ok := os.LookupEnv(EnvVarName(name))
+		if !ok || set[name] || flag.Lookup(name) == nil {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("environment variable %s: %w", EnvVarName(name), err)
+		}
+	}
+	return nil
+}
+
 type LabelsMap map[string][]string
 
 func (m LabelsMap) String() string {
@@ -331,3 +630,125 @@ func (m LabelsMap) Contains(key, val string) bool {
 	}
 	return false
 }
+
+// LabelSelector is a list of label requirements, such as `tier in (net,storage), !slow`, parsed the same way as
+// the Kubernetes label selectors. As the features can have several values for a label, the equality and set-based
+// requirements match when any of the values of the label is selected, and the inequality ones when none is.
+type LabelSelector []labels.Requirement
+
+// ParseLabelSelector parses the comma-separated label requirements of selector
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	requirements, err := labels.ParseToRequirements(selector)
+	if err != nil {
+		return nil, fmt.Errorf("label selector format error: %w", err)
+	}
+	return requirements, nil
+}
+
+func (s LabelSelector) String() string {
+	requirements := make([]string, 0, len(s))
+	for i := range s {
+		requirements = append(requirements, s[i].String())
+	}
+	return strings.Join(requirements, ",")
+}
+
+// Matches checks whether the labels meet all the requirements of the selector. The equality and set-based
+// requirements on the same key are merged, so that `k=v0,k=v1` selects the labels with either value of k as the
+// key=value form of the -labels flag always did.
+func (s LabelSelector) Matches(m LabelsMap) bool {
+	selected := map[string][]string{}
+	for i := range s {
+		switch s[i].Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			selected[s[i].Key()] = append(selected[s[i].Key()], s[i].Values().List()...)
+		default:
+			if !requirementMatches(&s[i], m) {
+				return false
+			}
+		}
+	}
+	for key, vals := range selected {
+		if !m.containsAny(key, vals) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesAny returns the first requirement of the selector met by the labels, if any
+func (s LabelSelector) MatchesAny(m LabelsMap) (string, bool) {
+	for i := range s {
+		if requirementMatches(&s[i], m) {
+			return s[i].String(), true
+		}
+	}
+	return "", false
+}
+
+func requirementMatches(r *labels.Requirement, m LabelsMap) bool {
+	_, exists := m[r.Key()]
+	switch r.Operator() {
+	case selection.Exists:
+		return exists
+	case selection.DoesNotExist:
+		return !exists
+	case selection.Equals, selection.DoubleEquals, selection.In:
+		return m.containsAny(r.Key(), r.Values().List())
+	case selection.NotEquals, selection.NotIn:
+		return !m.containsAny(r.Key(), r.Values().List())
+	case selection.GreaterThan, selection.LessThan:
+		limit, err := strconv.ParseInt(r.Values().List()[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		for _, v := range m[r.Key()] {
+			value, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				continue
+			}
+			if (r.Operator() == selection.GreaterThan && value > limit) || (r.Operator() == selection.LessThan && value < limit) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m LabelsMap) containsAny(key string, vals []string) bool {
+	for _, v := range vals {
+		if m.Contains(key, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// labelsValue is the value of the -labels and -skip-labels flags. The label requirements are all kept in the
+// selector, while the key=value ones are also kept in the labels.
+type labelsValue struct {
+	labels   LabelsMap
+	selector LabelSelector
+}
+
+func (v *labelsValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.selector.String()
+}
+
+func (v *labelsValue) Set(val string) error {
+	selector, err := ParseLabelSelector(val)
+	if err != nil {
+		return err
+	}
+	for i := range selector {
+		switch selector[i].Operator() {
+		case selection.Equals, selection.DoubleEquals:
+			v.labels[selector[i].Key()] = append(v.labels[selector[i].Key()], selector[i].Values().List()...)
+		}
+	}
+	v.selector = append(v.selector, selector...)
+	return nil
+}
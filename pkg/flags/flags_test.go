@@ -18,8 +18,10 @@ package flags
 
 import (
 	"flag"
+	"io"
 	"reflect"
 	"testing"
+	"time"
 
 	"sigs.k8s.io/e2e-framework/pkg/featuregate"
 )
@@ -32,8 +34,8 @@ func TestParseFlags(t *testing.T) {
 	}{
 		{
 			name:  "with all",
-			args:  []string{"-assess", "volume test", "--feature", "beta", "--labels", "k0=v0, k0=v01, k1=v1, k1=v11, k2=v2", "--skip-labels", "k0=v0, k1=v1", "-skip-features", "networking", "-skip-assessment", "volume test", "-parallel", "--dry-run", "--disable-graceful-teardown", "--feature-gates", "ReverseTestFinishExecutionOrder=true"},
-			flags: &EnvFlags{assess: "volume test", feature: "beta", labels: LabelsMap{"k0": {"v0", "v01"}, "k1": {"v1", "v11"}, "k2": {"v2"}}, skiplabels: LabelsMap{"k0": {"v0"}, "k1": {"v1"}}, skipFeatures: "networking", skipAssessments: "volume test"},
+			args:  []string{"-assess", "volume test", "--feature", "beta", "--labels", "k0=v0, k0=v01, k1=v1, k1=v11, k2=v2", "--skip-labels", "k0=v0, k1=v1", "-skip-features", "networking", "-skip-assessment", "volume test", "-parallel", "--dry-run", "--disable-graceful-teardown", "--feature-gates", "ReverseTestFinishExecutionOrder=true", "--report-dir", "reports", "--artifacts-dir", "artifacts", "--list", "--state-file", "state.json", "--rerun-failed", "--test-deadline", "90m", "--config", "e2e.yaml", "--context", "kind-hub", "--progress", "-"},
+			flags: &EnvFlags{assess: "volume test", feature: "beta", labels: LabelsMap{"k0": {"v0", "v01"}, "k1": {"v1", "v11"}, "k2": {"v2"}}, skiplabels: LabelsMap{"k0": {"v0"}, "k1": {"v1"}}, skipFeatures: "networking", skipAssessments: "volume test", reportDir: "reports", artifactsDir: "artifacts", dryRun: true, list: true, stateFile: "state.json", rerunFailed: true, testDeadline: 90 * time.Minute, configFile: "e2e.yaml", kubeContext: "kind-hub", progress: "-"},
 		},
 	}
 
@@ -51,6 +53,30 @@ func TestParseFlags(t *testing.T) {
 			if testFlags.Assessment() != test.flags.Assessment() {
 				t.Errorf("unmatched assessment: %s", testFlags.Assessment())
 			}
+			if testFlags.ReportDir() != test.flags.ReportDir() {
+				t.Errorf("unmatched report dir: %s", testFlags.ReportDir())
+			}
+			if testFlags.ArtifactsDir() != test.flags.ArtifactsDir() {
+				t.Errorf("unmatched artifacts dir: %s", testFlags.ArtifactsDir())
+			}
+			if testFlags.StateFile() != test.flags.StateFile() || testFlags.RerunFailed() != test.flags.RerunFailed() {
+				t.Errorf("unmatched state file: %s, rerun failed: %t", testFlags.StateFile(), testFlags.RerunFailed())
+			}
+			if testFlags.TestDeadline() != test.flags.TestDeadline() {
+				t.Errorf("unmatched test deadline: %s", testFlags.TestDeadline())
+			}
+			if testFlags.Progress() != test.flags.Progress() {
+				t.Errorf("unmatched progress: %s", testFlags.Progress())
+			}
+			if testFlags.KubeContext() != test.flags.KubeContext() {
+				t.Errorf("unmatched kubeconfig context: %s", testFlags.KubeContext())
+			}
+			if testFlags.ConfigFile() != test.flags.ConfigFile() {
+				t.Errorf("unmatched config file: %s", testFlags.ConfigFile())
+			}
+			if testFlags.List() != test.flags.List() || testFlags.DryRun() != test.flags.DryRun() {
+				t.Errorf("unmatched list mode: %t, dry-run: %t", testFlags.List(), testFlags.DryRun())
+			}
 
 			for k, v := range testFlags.Labels() {
 				if !reflect.DeepEqual(test.flags.Labels()[k], v) {
@@ -91,6 +117,141 @@ func TestParseFlags(t *testing.T) {
 	}
 }
 
+func TestParseFlags_LabelSelector(t *testing.T) {
+	flag.CommandLine = &flag.FlagSet{}
+	testFlags, err := ParseArgs([]string{"--labels", "tier in (net,storage), !slow", "--skip-labels", "k0=v0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := testFlags.LabelSelector().String(); got != "!slow,tier in (net,storage)" {
+		t.Errorf("unmatched label selector: %s", got)
+	}
+	if len(testFlags.Labels()) != 0 {
+		t.Errorf("expected no key=value labels, got %v", testFlags.Labels())
+	}
+	if !reflect.DeepEqual(testFlags.SkipLabels(), LabelsMap{"k0": {"v0"}}) {
+		t.Errorf("unmatched skip labels: %v", testFlags.SkipLabels())
+	}
+	if got := testFlags.SkipLabelSelector().String(); got != "k0=v0" {
+		t.Errorf("unmatched skip label selector: %s", got)
+	}
+
+	flag.CommandLine = flag.NewFlagSet("invalid", flag.ContinueOnError)
+	flag.CommandLine.SetOutput(io.Discard)
+	if _, err := ParseArgs([]string{"--labels", "tier in (net"}); err == nil {
+		t.Error("expected an invalid label selector to fail")
+	}
+}
+
+func TestParseFlags_InCluster(t *testing.T) {
+	flag.CommandLine = &flag.FlagSet{}
+	testFlags, err := ParseArgs([]string{"--in-cluster"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !testFlags.InCluster() {
+		t.Error("unmatched flag parsed. Expected inCluster to be true")
+	}
+
+	flag.CommandLine = &flag.FlagSet{}
+	if _, err := ParseArgs([]string{"--in-cluster", "--kubeconfig", "kubeconfig"}); err == nil {
+		t.Error("expected --in-cluster along with --kubeconfig to fail")
+	}
+}
+
+func TestParseFlags_Environment(t *testing.T) {
+	t.Setenv("E2E_NAMESPACE", "from-env")
+	t.Setenv("E2E_PARALLEL", "true")
+	t.Setenv("E2E_SKIP_LABELS", "k0=v0")
+	flag.CommandLine = &flag.FlagSet{}
+	testFlags, err := ParseArgs([]string{"--namespace", "from-flag"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if testFlags.Namespace() != "from-flag" {
+		t.Errorf("expected the flag to take precedence over the environment, got namespace %s", testFlags.Namespace())
+	}
+	if !testFlags.Parallel() {
+		t.Error("expected parallel to be set from the environment")
+	}
+	if !testFlags.IsSet("namespace") || !testFlags.IsSet("parallel") || testFlags.IsSet("feature") {
+		t.Error("expected only the flags set on the command line or the environment to be reported as set")
+	}
+	if !reflect.DeepEqual(testFlags.SkipLabels(), LabelsMap{"k0": {"v0"}}) {
+		t.Errorf("unmatched skip labels: %v", testFlags.SkipLabels())
+	}
+
+	t.Setenv(EnvVarName(flagTestDeadline), "soon")
+	flag.CommandLine = flag.NewFlagSet("invalid", flag.ContinueOnError)
+	if _, err := ParseArgs(nil); err == nil {
+		t.Error("expected an invalid environment variable to fail")
+	}
+}
+
+func TestParseFlags_UserFlags(t *testing.T) {
+	defer func() {
+		userFlags = flag.NewFlagSet("user", flag.ContinueOnError)
+		userFlagsSet = nil
+	}()
+	var imageTag, chartVersion string
+	UserFlags().StringVar(&imageTag, "image-tag", "latest", "The tag of the image under test")
+	UserFlags().StringVar(&chartVersion, "chart-version", "", "The version of the chart under test")
+	t.Setenv("E2E_CHART_VERSION", "1.2.3")
+
+	flag.CommandLine = &flag.FlagSet{}
+	if _, err := ParseArgs([]string{"--image-tag", "v1", "--namespace", "e2e"}); err != nil {
+		t.Fatal(err)
+	}
+	if imageTag != "v1" {
+		t.Errorf("unmatched user flag image-tag: %s", imageTag)
+	}
+	if chartVersion != "1.2.3" {
+		t.Errorf("expected user flag chart-version to be set from the environment, got %s", chartVersion)
+	}
+
+	UserFlags().String(flagNamespaceName, "", "conflicting flag")
+	flag.CommandLine = &flag.FlagSet{}
+	if _, err := ParseArgs(nil); err == nil {
+		t.Error("expected a user flag named after a framework flag to fail")
+	}
+}
+
+func TestLabelSelector_Matches(t *testing.T) {
+	labels := LabelsMap{"tier": {"net", "storage"}, "size": {"3"}, "slow": {"true"}}
+	tests := []struct {
+		selector string
+		matches  bool
+		any      bool
+	}{
+		{selector: "tier=net", matches: true, any: true},
+		{selector: "tier=compute", matches: false, any: false},
+		{selector: "tier=compute,tier=storage", matches: true, any: true},
+		{selector: "tier in (compute,net)", matches: true, any: true},
+		{selector: "tier notin (net)", matches: false, any: false},
+		{selector: "tier!=compute", matches: true, any: true},
+		{selector: "slow", matches: true, any: true},
+		{selector: "!slow", matches: false, any: false},
+		{selector: "!gpu,tier", matches: true, any: true},
+		{selector: "size>2,size<4", matches: true, any: true},
+		{selector: "size>3", matches: false, any: false},
+		{selector: "tier=net,!slow", matches: false, any: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := ParseLabelSelector(tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := selector.Matches(labels); got != tt.matches {
+				t.Errorf("Matches() = %v, want %v", got, tt.matches)
+			}
+			if _, got := selector.MatchesAny(labels); got != tt.any {
+				t.Errorf("MatchesAny() = %v, want %v", got, tt.any)
+			}
+		})
+	}
+}
+
 func TestLabelsMap_Contains(t *testing.T) {
 	type args struct {
 		key string
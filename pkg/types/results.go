@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import "time"
+
+// ResultStatus is the outcome of a feature or of one of its steps
+type ResultStatus string
+
+const (
+	StatusPassed  ResultStatus = "passed"
+	StatusFailed  ResultStatus = "failed"
+	StatusSkipped ResultStatus = "skipped"
+	// StatusFlaky is the status of the assessments that passed after having been retried, and of their features
+	StatusFlaky ResultStatus = "flaky"
+)
+
+// StepResult is the outcome of a step of a feature. As the messages logged by a test cannot be retrieved from
+// the testing package, the message of a failed step points at the output of its test.
+type StepResult struct {
+	Name     string        `json:"name"`
+	Status   ResultStatus  `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Message  string        `json:"message,omitempty"`
+}
+
+// FeatureResult is the outcome of a feature run by a test, along with the outcomes of its steps. The setup and
+// teardown steps are reported as failed when the test of the feature started failing while they ran.
+type FeatureResult struct {
+	Test        string        `json:"test"`
+	Name        string        `json:"name"`
+	Status      ResultStatus  `json:"status"`
+	Duration    time.Duration `json:"duration"`
+	Message     string        `json:"message,omitempty"`
+	Setups      []StepResult  `json:"setups,omitempty"`
+	Assessments []StepResult  `json:"assessments,omitempty"`
+	Teardowns   []StepResult  `json:"teardowns,omitempty"`
+	// Quarantined is the reason the feature is quarantined for, if it is. The failures of the quarantined
+	// features are reported apart.
+	Quarantined string `json:"quarantined,omitempty"`
+}
+
+// Failed returns true if the feature failed
+func (r FeatureResult) Failed() bool {
+	return r.Status == StatusFailed
+}
+
+// QuarantinedFailure returns true if the feature failed while quarantined, which is reported apart
+func (r FeatureResult) QuarantinedFailure() bool {
+	return r.Failed() && r.Quarantined != ""
+}
@@ -18,7 +18,9 @@ package types
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/e2e-framework/pkg/flags"
@@ -38,6 +40,13 @@ type EnvFunc func(context.Context, *envconf.Config) (context.Context, error)
 // features.
 type FeatureEnvFunc func(context.Context, *envconf.Config, *testing.T, Feature) (context.Context, error)
 
+// AssessmentEnvFunc represents a user-defined operation that
+// can be used to customize the behavior of the
+// environment. Changes to context are expected to surface
+// to caller. Meant for use with before/after assessment hooks,
+// it is provided with the feature and the name of the assessment.
+type AssessmentEnvFunc func(context.Context, *envconf.Config, *testing.T, Feature, string) (context.Context, error)
+
 // TestEnvFunc represents a user-defined operation that
 // can be used to customize the behavior of the
 // environment. Changes to context are expected to surface
@@ -54,6 +63,11 @@ type Environment interface {
 	// prior to the environment being ready and prior to any test.
 	Setup(...EnvFunc) Environment
 
+	// BeforeSuite registers funcs that are executed exactly once,
+	// before the Setup funcs. All the funcs are run even if some
+	// of them fail, and the suite is not run if any of them fails.
+	BeforeSuite(...EnvFunc) Environment
+
 	// BeforeEachTest registers environment funcs that are executed
 	// before each Env.Test(...)
 	BeforeEachTest(...TestEnvFunc) Environment
@@ -66,6 +80,14 @@ type Environment interface {
 	// after each feature is tested during an env.Test call.
 	AfterEachFeature(...FeatureEnvFunc) Environment
 
+	// BeforeEachAssessment registers functions that are executed
+	// before each assessment of the features tested during an env.Test call.
+	BeforeEachAssessment(...AssessmentEnvFunc) Environment
+
+	// AfterEachAssessment registers functions that are executed
+	// after each assessment of the features tested during an env.Test call.
+	AfterEachAssessment(...AssessmentEnvFunc) Environment
+
 	// Test executes a test feature defined in a TestXXX function
 	// This method surfaces context for further updates.
 	Test(*testing.T, ...Feature) context.Context
@@ -83,11 +105,21 @@ type Environment interface {
 	// test suite.
 	Finish(...EnvFunc) Environment
 
+	// AfterSuite registers funcs that are executed exactly once,
+	// after the Finish funcs. All the funcs are run even if some
+	// of them fail, and the suite fails if any of them fails.
+	AfterSuite(...EnvFunc) Environment
+
 	// Run Launches the test suite from within a TestMain
 	Run(*testing.M) int
 
 	// EnvConf returns the test environment's environment configuration
 	EnvConf() *envconf.Config
+
+	// Results returns the results of the features run so far by the
+	// environment, including the features run by Env.Test calls, in
+	// the order they completed.
+	Results() []FeatureResult
 }
 
 type Labels = flags.LabelsMap
@@ -112,8 +144,40 @@ const (
 	LevelTeardown
 )
 
+func (l Level) String() string {
+	switch l {
+	case LevelSetup:
+		return "setup"
+	case LevelAssess:
+		return "assess"
+	case LevelTeardown:
+		return "teardown"
+	default:
+		return fmt.Sprintf("Level(%d)", l)
+	}
+}
+
 type StepFunc func(context.Context, *testing.T, *envconf.Config) context.Context
 
+// AssessmentHookFunc is a step function run before or after each assessment of a feature, it is provided with the
+// name of the assessment.
+type AssessmentHookFunc func(context.Context, *testing.T, *envconf.Config, string) context.Context
+
+type ParallelStep interface {
+	Step
+
+	// Parallel indicates that the assessment runs concurrently with the assessments next to it that are also
+	// marked as parallel
+	Parallel() bool
+}
+
+type UndoableStep interface {
+	Step
+
+	// Undo is the teardown step undoing the setup step, it is only run if the setup succeeded
+	Undo() Step
+}
+
 type Step interface {
 	// Name is the step name
 	Name() string
@@ -138,3 +202,110 @@ type DescribableFeature interface {
 	// feature.
 	Description() string
 }
+
+type TimedFeature interface {
+	Feature
+
+	// Timeout is the time the setups and assessments of the feature are allowed to run for. A zero value
+	// disables the timeout.
+	Timeout() time.Duration
+}
+
+type QuarantinedFeature interface {
+	Feature
+
+	// QuarantineReason is the reason the feature is quarantined for, such as a known flakiness. A quarantined
+	// feature is still run, but its failures are reported apart and do not stop the other features. An empty
+	// reason means that the feature is not quarantined.
+	QuarantineReason() string
+}
+
+type TimedStep interface {
+	Step
+
+	// Timeout is the time the step is allowed to run for. A zero value disables the timeout.
+	Timeout() time.Duration
+}
+
+// RetryPolicy configures how a failed assessment is re-run
+type RetryPolicy struct {
+	// Attempts is the maximum number of times the assessment is run, including the first run
+	Attempts int
+	// Backoff is the time waited for before the first retry
+	Backoff time.Duration
+	// Factor is used to multiply the time waited for before each retry. A factor lower than or equal to 1 keeps
+	// the time waited for constant.
+	Factor float64
+}
+
+type RetriedFeature interface {
+	Feature
+
+	// Retry is the retry policy of the assessments of the feature that do not have their own. A nil policy
+	// disables retries.
+	Retry() *RetryPolicy
+}
+
+type RetriedStep interface {
+	Step
+
+	// Retry is the retry policy of the assessment. A nil policy falls back to the policy of the feature.
+	Retry() *RetryPolicy
+}
+
+type ParallelFeature interface {
+	Feature
+
+	// Parallel indicates that the feature can run concurrently with the other features marked as parallel
+	// of the same Env.Test call, each using an isolated namespace.
+	Parallel() bool
+}
+
+type AssessmentHookedFeature interface {
+	Feature
+
+	// BeforeEachAssessment are the hooks run before each assessment of the feature
+	BeforeEachAssessment() []AssessmentHookFunc
+	// AfterEachAssessment are the hooks run after each assessment of the feature, even when it failed
+	AfterEachAssessment() []AssessmentHookFunc
+}
+
+type IsolatedFeature interface {
+	Feature
+
+	// Isolated indicates that the feature runs with a namespace of its own, which is created before and deleted
+	// after the feature.
+	Isolated() bool
+}
+
+// RequirementFunc checks whether the cluster or the environment provides a capability a feature relies on. It
+// returns false along with the reason why when the capability is missing.
+type RequirementFunc func(context.Context, *envconf.Config) (bool, string)
+
+type RequiringFeature interface {
+	Feature
+
+	// Requirements are checked before the feature is run, the feature is skipped with the reason returned by the
+	// first requirement that is not met.
+	Requirements() []RequirementFunc
+}
+
+// AllClusters is the cluster name requesting a feature to be run against all the clusters registered in the
+// environment configuration
+const AllClusters = "*"
+
+type ClusteredFeature interface {
+	Feature
+
+	// Clusters are the names of the clusters registered in the environment configuration that the feature is run
+	// against, in turn. The feature is run against all the registered clusters when the names include AllClusters.
+	Clusters() []string
+}
+
+type DependentFeature interface {
+	Feature
+
+	// Dependencies are the names of the features that must have passed for the feature to be run. The feature
+	// is skipped when one of them failed, was skipped or has not been run.
+	Dependencies() []string
+}
@@ -50,7 +50,10 @@ type Cluster struct {
 }
 
 // Enforce Type check always to avoid future breaks
-var _ support.E2EClusterProvider = &Cluster{}
+var (
+	_ support.E2EClusterProvider             = &Cluster{}
+	_ support.E2EClusterProviderWithRegistry = &Cluster{}
+)
 
 func NewCluster(name string) *Cluster {
 	return &Cluster{name: name}
@@ -277,6 +280,52 @@ func (k *Cluster) LoadImageArchive(ctx context.Context, imageArchive string) err
 	return nil
 }
 
+// kindNetwork is the docker network of the nodes of the kind clusters
+const kindNetwork = "kind"
+
+// ConnectRegistry connects the registry container to the network of the kind nodes and configures containerd on
+// each node to pull the images of host from the registry, with a hosts.toml file under /etc/containerd/certs.d.
+// The cluster must be created with a kind config setting the config_path of the containerd registries:
+//
+//	containerdConfigPatches:
+//	- |-
+//	  [plugins."io.containerd.grpc.v1.cri".registry]
+//	    config_path = "/etc/containerd/certs.d"
+func (k *Cluster) ConnectRegistry(ctx context.Context, registry, host string) error {
+	p := utils.RunCommand(fmt.Sprintf(`docker network connect %s %s`, kindNetwork, registry))
+	if p.Err() != nil && !strings.Contains(p.Result(), "already exists") {
+		return fmt.Errorf("kind: connect registry %v to network %s failed: %s: %s", registry, kindNetwork, p.Err(), p.Result())
+	}
+
+	hostsFile, err := os.CreateTemp("", "kind-registry-hosts")
+	if err != nil {
+		return fmt.Errorf("kind: registry hosts file: %w", err)
+	}
+	defer os.Remove(hostsFile.Name())
+	_, err = fmt.Fprintf(hostsFile, "[host.\"http://%s:5000\"]\n", registry)
+	if closeErr := hostsFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("kind: registry hosts file: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := utils.RunCommandWithSeperatedOutput(fmt.Sprintf("%s get nodes --name %s", k.path, k.name), &stdout, &stderr); err != nil {
+		return fmt.Errorf("kind get nodes: stderr: %s: %w", stderr.String(), err)
+	}
+	for _, node := range strings.Fields(stdout.String()) {
+		dir := fmt.Sprintf("/etc/containerd/certs.d/%s", host)
+		if p := utils.RunCommand(fmt.Sprintf(`docker exec %s mkdir -p %s`, node, dir)); p.Err() != nil {
+			return fmt.Errorf("kind: configure registry on node %v failed: %s: %s", node, p.Err(), p.Result())
+		}
+		if p := utils.RunCommand(fmt.Sprintf(`docker cp %s %s:%s/hosts.toml`, hostsFile.Name(), node, dir)); p.Err() != nil {
+			return fmt.Errorf("kind: configure registry on node %v failed: %s: %s", node, p.Err(), p.Result())
+		}
+	}
+	return nil
+}
+
 func (k *Cluster) WaitForControlPlane(ctx context.Context, client klient.Client) error {
 	r, err := resources.New(client.RESTConfig())
 	if err != nil {
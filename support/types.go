@@ -103,3 +103,12 @@ type E2EClusterProviderWithImageLoader interface {
 	// can just provide a no-op implementation to be compliant with the interface
 	LoadImageArchive(ctx context.Context, archivePath string) error
 }
+
+type E2EClusterProviderWithRegistry interface {
+	E2EClusterProvider
+
+	// ConnectRegistry is used to wire a local registry, run as the container named registry and listening on port 5000
+	// of the container, into the cluster, so that the images pushed to the registry at host, e.g. localhost:5001, are
+	// pulled by the nodes of the cluster from the registry via the cluster provider native workflow
+	ConnectRegistry(ctx context.Context, registry, host string) error
+}
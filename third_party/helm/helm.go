@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/vladimirvivien/gexe"
@@ -56,6 +57,9 @@ type Opts struct {
 	Wait bool
 	// Timeout is used to indicate the time to wait for any individual Kubernetes ops
 	Timeout string
+	// Output is used to capture the output of the helm command, such as the notes
+	// rendered by the chart being installed
+	Output io.Writer
 }
 
 type Manager struct {
@@ -140,6 +144,15 @@ func WithTimeout(timeout string) Option {
 	}
 }
 
+// WithOutput is used to configure a writer into which the output of the helm
+// command is written once the command completes, e.g. to surface the notes
+// rendered by the chart being installed
+func WithOutput(out io.Writer) Option {
+	return func(opts *Opts) {
+		opts.Output = out
+	}
+}
+
 // processOpts is used to generate the Opts resource that will be used to generate
 // the actual helm command to be run using the getCommand helper
 func (m *Manager) processOpts(opts ...Option) *Opts {
@@ -256,7 +269,10 @@ func (m *Manager) run(opts *Opts) (err error) {
 	if !proc.IsSuccess() {
 		return fmt.Errorf("%s: %w", strings.TrimSuffix(stderr.String(), "\n"), proc.Err())
 	}
-	return nil
+	if opts.Output != nil {
+		_, err = io.WriteString(opts.Output, result)
+	}
+	return err
 }
 
 // WithPath is used to provide a custom path where the `helm` executable command